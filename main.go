@@ -72,6 +72,7 @@ func runAgent() {
 
 // Options
 // --kubeconfig <kubecfg local dir> | agent=host:port
+// --context <kubeconfig context> default the current context
 // --mode <agent|gui> default gui
 // if mode is agent, --kubeconfig must be a local kubeconfig
 func main() {
@@ -84,14 +85,83 @@ func main() {
 		kubeconfig = flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
 	}
 	var mode *string = flag.String("mode", "gui", "running mode")
+	kubeContext := flag.String("context", "", "(optional) kubeconfig context to use instead of the current one")
+	clusters := flag.String("clusters", "", "agent only, comma separated kubeconfig contexts to serve, default all")
 
 	var useCompressor *bool = flag.Bool("grpc-compression", true, "Whether to use compression in grpc")
+	compressor := flag.String("grpc-compressor", "gzip", "gui only, the grpc compression: gzip or zstd")
+
+	informerCache := flag.Bool("informer-cache", false, "agent only, serve lists and watches from informers started on demand")
+	informerIdleTimeout := flag.Duration("informer-idle-timeout", 10*time.Minute, "agent only, stop an informer unused for this long")
+
+	crdTimeout := flag.Duration("crd-timeout", 2*time.Minute, "gui only, how long a deploy waits for a crd to be established")
+	readyTimeout := flag.Duration("ready-timeout", 5*time.Minute, "gui only, how long a deploy waits for the deployed resources to be ready")
+	driftInterval := flag.Duration("drift-interval", 5*time.Minute, "gui only, how often deployments are checked for drift from the cluster, 0 to disable")
+
+	tlsEnabled := flag.Bool("tls", false, "Use tls on the grpc connection between gui and agent")
+	tlsCert := flag.String("tls-cert", "", "certificate file (agent: server cert, gui: client cert)")
+	tlsKey := flag.String("tls-key", "", "key file of the --tls-cert")
+	tlsCa := flag.String("tls-ca", "", "ca file (agent: verifies client certs, gui: verifies the agent)")
+	tlsClientAuth := flag.Bool("tls-client-auth", false, "agent only, require clients to present a cert signed by --tls-ca")
+	tlsServerName := flag.String("tls-server-name", "", "gui only, server name to verify the agent cert against")
+	tlsHosts := flag.String("tls-hosts", "", "agent only, comma separated extra hosts for the bootstrapped server cert")
+	tokenFile := flag.String("token-file", "", "gui only, file containing the bearer token to authenticate to the agent")
+	tlsBootstrap := flag.Bool("tls-bootstrap", false, "agent only, generate (or reuse) a self-signed agent ca and certs and enable mutual tls")
 
 	flag.Parse()
 
 	options.Options.Mode = *mode
 	options.Options.Kubeconfig = *kubeconfig
+	options.Options.Context = *kubeContext
+	if *clusters != "" {
+		options.Options.Clusters = strings.Split(*clusters, ",")
+	}
 	options.Options.UseCompressor = *useCompressor
+	options.Options.Compressor = *compressor
+	options.Options.InformerCache = *informerCache
+	options.Options.InformerIdleTimeout = *informerIdleTimeout
+	options.Options.CrdTimeout = *crdTimeout
+	options.Options.ReadyTimeout = *readyTimeout
+	options.Options.DriftInterval = *driftInterval
+
+	// tls settings from config.json, explicit flags take precedence
+	if cfg, err := config.GetConfig(); err == nil {
+		options.Options.Tls = cfg.Agent.Tls
+		options.Options.Auth = cfg.Agent.Auth
+		options.Options.Token = cfg.Agent.Token
+	} else {
+		logger.Warn("failed to load config", zap.Error(err))
+	}
+	// sets the tls options passed explicitly as flags
+	applyTlsFlags := func(tlsOpts *options.TlsOptions) {
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "tls":
+				tlsOpts.Enabled = *tlsEnabled
+			case "tls-cert":
+				tlsOpts.CertFile = *tlsCert
+			case "tls-key":
+				tlsOpts.KeyFile = *tlsKey
+			case "tls-ca":
+				tlsOpts.CaFile = *tlsCa
+			case "tls-client-auth":
+				tlsOpts.ClientAuth = *tlsClientAuth
+			case "tls-server-name":
+				tlsOpts.ServerName = *tlsServerName
+			case "tls-hosts":
+				tlsOpts.Hosts = strings.Split(*tlsHosts, ",")
+			}
+		})
+	}
+	applyTlsFlags(&options.Options.Tls)
+	if *tokenFile != "" {
+		token, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			logger.Error("failed to read token file", zap.Error(err))
+			return
+		}
+		options.Options.Token = strings.TrimSpace(string(token))
+	}
 
 	k8sservice.InitK8sService()
 
@@ -100,6 +170,22 @@ func main() {
 			logger.Info("agent mode doesn't support remote k8s client")
 			return
 		}
+		if *tlsBootstrap {
+			tlsDir, err := config.GetAgentTlsDir()
+			if err != nil {
+				logger.Error("error getting agent tls dir", zap.Error(err))
+				return
+			}
+			tlsOpts, err := k8sservice.BootstrapAgentCerts(tlsDir, options.Options.Tls.Hosts)
+			if err != nil {
+				logger.Error("failed to bootstrap agent certificates", zap.Error(err))
+				return
+			}
+			// the bootstrapped files fill in what isn't passed as flags
+			applyTlsFlags(tlsOpts)
+			options.Options.Tls = *tlsOpts
+			logger.Info("copy ca.crt, client.crt and client.key to the gui host", zap.String("dir", tlsDir))
+		}
 		logger.Info("starting local agent...")
 		runAgent()
 		return
@@ -42,6 +42,7 @@ type AboutAction struct {
 type ControlBar struct {
 	bar             *component.AppBar
 	overflowAbout   *AboutAction
+	overflowContext *SwitchContextAction
 	globalOverflows []component.OverflowAction
 }
 
@@ -54,6 +55,10 @@ func (cbar *ControlBar) init(m *component.ModalLayer) {
 	cbar.bar.NavigationIcon = graphics.MenuIcon
 
 	cbar.globalOverflows = []component.OverflowAction{
+		{
+			Name: "Switch Context",
+			Tag:  cbar.overflowContext,
+		},
 		{
 			Name: "About",
 			Tag:  cbar.overflowAbout,
@@ -108,6 +113,9 @@ func (navigator *ResourceNavigator) setup(gtx layout.Context) *layout.FlexChild
 			if aboutAction, ok := event.Tag.(*AboutAction); ok {
 				common.SetContextBool(common.CONTEXT_SHOW_ABOUT, true, aboutAction)
 			}
+			if contextAction, ok := event.Tag.(*SwitchContextAction); ok {
+				common.SetContextBool(common.CONTEXT_SWITCH_CONTEXT, true, contextAction)
+			}
 		}
 	}
 
@@ -127,6 +135,7 @@ func (navigator *ResourceNavigator) init() {
 	navigator.floatMenu = true
 
 	navigator.constrolBar.overflowAbout = &AboutAction{}
+	navigator.constrolBar.overflowContext = &SwitchContextAction{}
 }
 
 type AppUI struct {
@@ -146,6 +155,10 @@ type AppUI struct {
 	resize1             component.Resize
 	resourceCollections *ResourceCollections
 	aboutPanel          *AboutPanel
+	contextPicker       *ContextPicker
+	// the cluster switches to refresh the tabs for in the ui goroutine,
+	// each is closed once done
+	clusterSwitches chan chan struct{}
 }
 
 const (
@@ -247,6 +260,7 @@ func (appUi *AppUI) Init() []error {
 	common.SetContextData(common.CONTEXT_APP_INIT_STATE, float32(0.0), nil)
 
 	appUi.RefreshCh = make(chan int, 1)
+	appUi.clusterSwitches = make(chan chan struct{}, 1)
 	appUi.ForceUpdate = false
 	// opentype.Parse()
 	appUi.theme.Shaper = text.NewShaper(text.WithCollection(gofont.Collection()))
@@ -277,6 +291,7 @@ func (appUi *AppUI) Init() []error {
 	appUi.progressBar.Height = unit.Dp(6)
 
 	appUi.aboutPanel = &AboutPanel{}
+	appUi.contextPicker = NewContextPicker(appUi.clusterSwitched)
 
 	common.SetContextData(common.CONTEXT_APP_INIT_STATE, float32(1.0), nil)
 	return nil
@@ -358,6 +373,14 @@ func (appUi *AppUI) getBottomBarComponents() []layout.FlexChild {
 		appUi.info = "App is running"
 		firstTask = ld.GetFirstTask()
 	}
+	if stats := k8sservice.GetK8sService().CacheStats(); stats != nil {
+		flexChildren = append(flexChildren,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Label(appUi.theme, unit.Sp(13), stats.String())
+				label.Color = common.COLOR.Gray
+				return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, label.Layout)
+			}))
+	}
 	if firstTask != nil {
 		appUi.info = fmt.Sprintf("Running Task: %v", firstTask.Name)
 		// The progress bar
@@ -421,6 +444,8 @@ func (appUi *AppUI) Layout(gtx layout.Context) {
 
 	flex := layout.Flex{Axis: layout.Vertical}
 	bar := appUi.setupAppBar(gtx)
+	appUi.handleSwitchContext(gtx)
+	appUi.handleClusterSwitched()
 
 	cols := appUi.setupCollections()
 	content := appUi.setupMainContent()
@@ -495,6 +520,18 @@ func (appUi *AppUI) InitDone() bool {
 	return false
 }
 
+func (appUi *AppUI) handleSwitchContext(gtx layout.Context) {
+	show, _, err := common.GetContextBool(common.CONTEXT_SWITCH_CONTEXT)
+	if err != nil {
+		logger.Info("failed to get context", zap.Error(err))
+		return
+	}
+	if show {
+		common.SetContextBool(common.CONTEXT_SWITCH_CONTEXT, false, nil)
+		appUi.contextPicker.Show(gtx)
+	}
+}
+
 func (appUi *AppUI) closeAboutDialog() {
 	common.SetContextBool(common.CONTEXT_SHOW_ABOUT, false, nil)
 }
@@ -512,6 +549,7 @@ func NewAppUI(logger *zap.Logger) *AppUI {
 
 	common.RegisterContext(common.CONTEXT_APP_INIT_STATE, float32(0.0), true)
 	common.RegisterContext(common.CONTEXT_SHOW_ABOUT, false, true)
+	common.RegisterContext(common.CONTEXT_SWITCH_CONTEXT, false, true)
 
 	appUI := &AppUI{
 		Logger:       logger,
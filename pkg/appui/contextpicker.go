@@ -0,0 +1,134 @@
+package appui
+
+import (
+	"fmt"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/dialogs"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"go.uber.org/zap"
+)
+
+type SwitchContextAction struct {
+}
+
+// impl dialogs.DialogControl
+// ContextPicker lists the kubeconfig contexts and switches
+// the k8s service to the chosen one
+type ContextPicker struct {
+	contexts []string
+	current  string
+	selected widget.Enum
+	list     widget.List
+	dialog   *dialogs.InputDialog
+	// called after the service is switched to the new context
+	switched func()
+}
+
+func NewContextPicker(switched func()) *ContextPicker {
+	picker := &ContextPicker{
+		switched: switched,
+	}
+	picker.list.Axis = layout.Vertical
+	picker.dialog = dialogs.NewInputDialog("Switch Context", picker)
+	dialogs.RegisterDialog(picker.dialog)
+	return picker
+}
+
+// Show reloads the contexts from the kubeconfig and opens the dialog
+func (c *ContextPicker) Show(gtx layout.Context) {
+	contexts, current, err := k8sservice.GetK8sService().ListContexts()
+	c.contexts = contexts
+	c.current = current
+	c.selected.Value = current
+	if err != nil {
+		logger.Info("failed to list contexts", zap.Error(err))
+		c.dialog.SetError(err)
+	} else {
+		c.dialog.ClearError()
+	}
+	c.dialog.Show(gtx)
+}
+
+// Apply implements dialogs.DialogControl.
+func (c *ContextPicker) Apply() error {
+	if len(c.contexts) == 0 {
+		return fmt.Errorf("no context to switch to")
+	}
+	name := c.selected.Value
+	if name == "" {
+		return fmt.Errorf("please select a context")
+	}
+	if name == c.current {
+		return nil
+	}
+
+	ctxData, _ := common.GetContextData(common.CONTEXT_LONG_TASK_LIST)
+	if taskCtx, ok := ctxData.(*common.LongTasksContext); ok {
+		task := taskCtx.AddTask("Switching to context " + name)
+		task.Progress = float32(0.0)
+		task.Step = 1.0 / 2
+		task.Run = func() {
+			if err := k8sservice.GetK8sService().SwitchContext(name); err != nil {
+				task.Failed(err)
+				return
+			}
+			task.Update("Switched to context " + name)
+			c.switched()
+			task.Update("Reloaded cluster contents")
+			task.Done()
+		}
+		task.Start()
+	}
+	return nil
+}
+
+// Cancel implements dialogs.DialogControl.
+func (c *ContextPicker) Cancel() {
+}
+
+// GetWidget implements dialogs.DialogControl.
+func (c *ContextPicker) GetWidget() layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		th := common.GetTheme()
+		return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return material.List(th, &c.list).Layout(gtx, len(c.contexts), func(gtx layout.Context, index int) layout.Dimensions {
+				name := c.contexts[index]
+				label := name
+				if name == c.current {
+					label += " (current)"
+				}
+				return material.RadioButton(th, &c.selected, name, label).Layout(gtx)
+			})
+		})
+	}
+}
+
+// clusterSwitched refreshes the contents that belong to the cluster.
+// It is called in the switching task and waits for the ui goroutine
+// to do it, see handleClusterSwitched.
+func (appUi *AppUI) clusterSwitched() {
+	done := make(chan struct{})
+	appUi.clusterSwitches <- done
+	common.GetAppWindow().Invalidate()
+	<-done
+}
+
+// handleClusterSwitched refreshes the tabs if the cluster is switched
+func (appUi *AppUI) handleClusterSwitched() {
+	select {
+	case done := <-appUi.clusterSwitches:
+		if appUi.panel != nil {
+			appUi.panel.ClusterChanged()
+		}
+		// the title is recomputed with the new cluster
+		appUi.resourceNavigator.constrolBar.bar.Title = ""
+		close(done)
+		common.GetAppWindow().Invalidate()
+	default:
+	}
+}
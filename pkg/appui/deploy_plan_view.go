@@ -0,0 +1,283 @@
+package appui
+
+import (
+	"sync"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+)
+
+// the unchanged lines shown around a change
+const PREVIEW_DIFF_CONTEXT = 3
+
+// the widths of the kind, name, namespace, action and reason columns
+var planColumnWeights = []float32{0.15, 0.25, 0.15, 0.1, 0.35}
+
+type planRow struct {
+	item    *k8sservice.DeployPlanItem
+	deploy  widget.Bool
+	btn     widget.Clickable
+	preview *k8sservice.ResourcePreview
+	err     error
+	diff    *common.DiffView
+}
+
+// change is the dry run result, empty if not dry run
+func (r *planRow) change() string {
+	if r.err != nil {
+		return "failed"
+	}
+	if r.preview != nil {
+		return r.preview.Change()
+	}
+	return ""
+}
+
+// DeployPlanView shows the plan of a deploy for the user to skip
+// items and approve it. With a dry run it also shows what each item
+// changes in the cluster.
+type DeployPlanView struct {
+	lock     sync.Mutex
+	resId    string
+	resName  string
+	dryRun   bool
+	loading  bool
+	err      error
+	plan     *k8sservice.DeployPlan
+	rows     []*planRow
+	selected int
+
+	split      component.Resize
+	rowList    widget.List
+	approveBtn widget.Clickable
+	cancelBtn  widget.Clickable
+	waitReady  widget.Bool
+	rollback   widget.Bool
+}
+
+func NewDeployPlanView(resource common.Resource, dryRun bool) *DeployPlanView {
+	view := &DeployPlanView{
+		resId:   resource.GetId(),
+		resName: resource.GetName(),
+		dryRun:  dryRun,
+		loading: true,
+		split:   component.Resize{Ratio: 0.5},
+	}
+	view.rowList.Axis = layout.Vertical
+	return view
+}
+
+// Run shows plan, dry running its actions if asked to.
+// It is called in a go routine.
+func (v *DeployPlanView) Run(client k8sservice.K8sService, plan *k8sservice.DeployPlan, err error) {
+	rows := make([]*planRow, 0)
+	if err == nil {
+		for _, item := range plan.Items {
+			row := &planRow{item: item}
+			row.deploy.Value = item.Action != nil && !item.Skip
+			if v.dryRun && item.Action != nil {
+				row.diff = common.NewDiffView()
+				row.preview, row.err = client.PreviewResource(item.Action, "")
+				if row.err == nil {
+					row.diff.SetDiff(row.preview.Diff(), PREVIEW_DIFF_CONTEXT)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	v.lock.Lock()
+	v.plan = plan
+	v.rows = rows
+	v.err = err
+	v.loading = false
+	v.lock.Unlock()
+	common.GetAppWindow().Invalidate()
+}
+
+// Layout returns the plan once the user approved it, or whether
+// the user cancelled
+func (v *DeployPlanView) Layout(gtx layout.Context) (layout.Dimensions, *k8sservice.DeployPlan, bool) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	th := common.GetTheme()
+	var approved *k8sservice.DeployPlan
+	if v.approveBtn.Clicked(gtx) && v.plan != nil {
+		for _, row := range v.rows {
+			row.item.Skip = !row.deploy.Value
+		}
+		v.plan.WaitReady = v.waitReady.Value
+		v.plan.Transactional = v.rollback.Value
+		approved = v.plan
+	}
+	cancel := v.cancelBtn.Clicked(gtx)
+	for i, row := range v.rows {
+		if row.btn.Clicked(gtx) {
+			v.selected = i
+		}
+	}
+
+	title := "Deploy plan of "
+	if v.dryRun {
+		title = "Deploy preview of "
+	}
+	header := func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1.0, material.H6(th, title+v.resName).Layout),
+				layout.Rigid(material.CheckBox(th, &v.rollback, "Roll back on failure").Layout),
+				layout.Rigid(material.CheckBox(th, &v.waitReady, "Wait until ready").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if v.loading || v.err != nil || v.approvedCount() == 0 {
+						gtx = gtx.Disabled()
+					}
+					return material.Button(th, &v.approveBtn, "Deploy").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+				layout.Rigid(material.Button(th, &v.cancelBtn, "Cancel").Layout),
+			)
+		})
+	}
+
+	body := func(gtx layout.Context) layout.Dimensions {
+		switch {
+		case v.loading && v.dryRun:
+			return material.Body1(th, "Running the dry run...").Layout(gtx)
+		case v.loading:
+			return material.Body1(th, "Working out the plan...").Layout(gtx)
+		case v.err != nil:
+			label := material.Body1(th, v.err.Error())
+			label.Color = common.COLOR.Red
+			return label.Layout(gtx)
+		case len(v.rows) == 0:
+			return material.Body1(th, "No resources to deploy").Layout(gtx)
+		}
+		if v.dryRun {
+			return v.split.Layout(gtx, v.layoutRows, v.layoutSelected, common.VerticalSplitHandler)
+		}
+		return v.layoutRows(gtx)
+	}
+
+	dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(header),
+			layout.Flexed(1.0, body),
+		)
+	})
+	return dims, approved, cancel
+}
+
+func (v *DeployPlanView) approvedCount() int {
+	count := 0
+	for _, row := range v.rows {
+		if row.deploy.Value {
+			count++
+		}
+	}
+	return count
+}
+
+func (v *DeployPlanView) layoutRows(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+	columns := func(gtx layout.Context, texts []string, style func(*material.LabelStyle)) layout.Dimensions {
+		children := make([]layout.FlexChild, 0, len(texts))
+		for i, text := range texts {
+			children = append(children, layout.Flexed(planColumnWeights[i], func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(th, text)
+				label.MaxLines = 1
+				style(&label)
+				return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, label.Layout)
+			}))
+		}
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+	}
+	checkWidth := unit.Dp(32)
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(layout.Spacer{Width: checkWidth}.Layout),
+				layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+					return columns(gtx, []string{"Kind", "Name", "Namespace", "Action", "Reason"}, func(l *material.LabelStyle) {
+						l.Font.Weight = font.Bold
+					})
+				}),
+			)
+		}),
+		layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+			return material.List(th, &v.rowList).Layout(gtx, len(v.rows), func(gtx layout.Context, index int) layout.Dimensions {
+				row := v.rows[index]
+				action := row.item.ActionName()
+				if change := row.change(); change != "" {
+					action += " (" + change + ")"
+				}
+				texts := []string{row.item.Kind, row.item.Name, row.item.Namespace, action, row.item.Reason}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Min.X = gtx.Dp(checkWidth)
+						if row.item.Action == nil {
+							gtx = gtx.Disabled()
+						}
+						return material.CheckBox(th, &row.deploy, "").Layout(gtx)
+					}),
+					layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+						return material.Clickable(gtx, &row.btn, func(gtx layout.Context) layout.Dimensions {
+							return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return columns(gtx, texts, func(l *material.LabelStyle) {
+									switch {
+									case row.item.Warning || row.err != nil || (row.preview != nil && row.preview.DryRunErr != nil):
+										l.Color = common.COLOR.Red
+									case !row.deploy.Value:
+										l.Color = common.COLOR.Gray
+									}
+									if v.dryRun && index == v.selected {
+										l.Font.Weight = font.Bold
+									}
+								})
+							})
+						})
+					}),
+				)
+			})
+		}),
+	)
+}
+
+func (v *DeployPlanView) layoutSelected(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+	if v.selected >= len(v.rows) {
+		return layout.Dimensions{}
+	}
+	row := v.rows[v.selected]
+	return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		var message string
+		switch {
+		case row.item.Warning:
+			message = row.item.Reason
+		case row.item.Action == nil:
+			message = "unchanged, nothing to deploy"
+		case row.err != nil:
+			message = row.err.Error()
+		case row.preview.DryRunErr != nil:
+			message = row.preview.DryRunErr.Error()
+			for _, cause := range k8sservice.ErrorCauses(row.preview.DryRunErr) {
+				message += "\n  " + cause
+			}
+		}
+		if message != "" {
+			label := material.Body2(th, message)
+			if row.item.Action != nil || row.item.Warning {
+				label.Color = common.COLOR.Red
+			}
+			return label.Layout(gtx)
+		}
+		return row.diff.Layout(gtx)
+	})
+}
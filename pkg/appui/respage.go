@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"strings"
+	"time"
 
 	"slices"
 
@@ -12,6 +13,7 @@ import (
 	"gaohoward.tools/k8s/resutil/pkg/graphics"
 	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
 	"gaohoward.tools/k8s/resutil/pkg/logs"
+	"gaohoward.tools/k8s/resutil/pkg/options"
 	"gaohoward.tools/k8s/resutil/pkg/panels"
 	"gioui.org/font"
 	"gioui.org/layout"
@@ -23,6 +25,7 @@ import (
 	"gioui.org/x/component"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -155,6 +158,12 @@ type ResourcePage struct {
 	SaveBtnTooltip component.Tooltip
 	SaveBtnTipArea component.TipArea
 
+	editorBtnPreview  widget.Clickable
+	PreviewBtnTooltip component.Tooltip
+	PreviewBtnTipArea component.TipArea
+	// shown in place of the editor until approved or cancelled
+	planView *DeployPlanView
+
 	k8sClient         k8sservice.K8sService
 	appPanel          *panels.AppPanel
 	deployedResources *k8sservice.DeployedResources
@@ -236,6 +245,7 @@ func (rp *ResourcePage) Init(rtclient k8sservice.K8sService, refreshCh chan int)
 
 	rp.SaveBtnTooltip = component.DesktopTooltip(th, "Save")
 	rp.DeployBtnTooltip = component.DesktopTooltip(th, "Deploy")
+	rp.PreviewBtnTooltip = component.DesktopTooltip(th, "Preview deploy")
 
 	rp.k8sClient = rtclient
 
@@ -568,13 +578,15 @@ func (rp *ResourcePage) Layout(gtx layout.Context) layout.Dimensions {
 
 		if rp.editorBtnDeploy.Clicked(gtx) {
 			rp.SaveCurrent(gtx)
-			go func() {
-				rp.DeployResource(rp.current)
-			}()
+			rp.PlanDeploy(rp.current, false)
 		}
 		if rp.editorBtnSave.Clicked(gtx) {
 			rp.SaveCurrent(gtx)
 		}
+		if rp.editorBtnPreview.Clicked(gtx) {
+			rp.SaveCurrent(gtx)
+			rp.PlanDeploy(rp.current, true)
+		}
 		// The editor area
 		if rp.current == nil || rp.activeResources.Size() == 0 {
 			rp.crEditor.Editor.SetText("")
@@ -614,6 +626,19 @@ func (rp *ResourcePage) Layout(gtx layout.Context) layout.Dimensions {
 			}),
 			// the editor
 			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				if planView := rp.planView; planView != nil && planView.resId == rp.current.GetId() {
+					dims, plan, cancel := planView.Layout(gtx)
+					if plan != nil {
+						rp.planView = nil
+						current := rp.current
+						go func() {
+							rp.DeployResource(current, plan)
+						}()
+					} else if cancel {
+						rp.planView = nil
+					}
+					return dims
+				}
 				return layout.UniformInset(unit.Dp(10)).Layout(gtx,
 					func(gtx layout.Context) layout.Dimensions {
 						return rp.crEditor.Layout(gtx)
@@ -700,56 +725,35 @@ func (rp *ResourcePage) Layout(gtx layout.Context) layout.Dimensions {
 
 }
 
-// re-arrange the map to a list of keys and make sure all namespaces
-// ids are in the front of the slice (so deployed first)
-func ProcessDeployOrder(deployMap map[string]*common.ResourceInstanceAction) []string {
-	result := make([]string, len(deployMap))
-	index1 := 0
-	index2 := len(deployMap) - 1
-
-	for key, inst := range deployMap {
-		if inst.Instance.GetSpecApiVer() == "v1/namespaces" {
-			result[index1] = key
-			index1++
-		} else {
-			result[index2] = key
-			index2--
-		}
-	}
-	return result
-}
-
 // Note: this method is called in a go routine
 // be careful not to update the ui directly in this method scope
 // if app crashes examine this method's call stacks and see
 // if somewhere in the path it updates UI directly. If so
 // move them to the layout path.
-func (rp *ResourcePage) DeployResource(current common.Resource) error {
+func (rp *ResourcePage) DeployResource(current common.Resource, plan *k8sservice.DeployPlan) error {
 	appLog := logs.GetLogger(logs.IN_APP_LOGGER_NAME)
-	currentId := rp.current.GetId()
+	currentId := current.GetId()
 
-	inode := rp.resourceManager.GetNodeMap()[current.GetId()]
-	if inode == nil {
-		// this could happen with template resource where it's not in the repository
-		fmt.Printf("Logging warn current: %v, with logger: %v\n", current.GetName(), logger)
-		logger.Warn("Resource is a template", zap.String("Name", current.GetName()))
-		if inst, ok := current.(*common.ResourceInstance); ok {
-			inode = common.NewResourceNode(inst)
-		} else {
-			return fmt.Errorf("current is not a ResourceInstance: %T", current)
-		}
+	orderedResourceToDeploy := plan.Approved()
+	if len(orderedResourceToDeploy) == 0 {
+		appLog.Info("No resources to deploy")
+		return nil
 	}
-	resourcesToDeploy, err := rp.deployedResources.LockAndAdd(inode)
+
+	inode, err := rp.deployNode(current)
 	if err != nil {
-		appLog.Warn("Failed to deploy resource", zap.String("Name", current.GetName()))
 		return err
 	}
-	if len(resourcesToDeploy) == 0 {
-		appLog.Info("No resources to deploy")
-		return nil
+	// what the deployment was, to put it back if the deploy is rolled back
+	prior := rp.deployedResources.Snapshot(currentId)
+	revision, err := rp.deployedResources.NewRevision(inode, plan)
+	if err != nil {
+		return err
+	}
+	if _, err := rp.deployedResources.LockAndAdd(inode); err != nil {
+		appLog.Warn("Failed to deploy resource", zap.String("Name", current.GetName()))
+		return err
 	}
-
-	orderedResourceToDeploy := ProcessDeployOrder(resourcesToDeploy)
 
 	ctxData, _ := common.GetContextData(common.CONTEXT_LONG_TASK_LIST)
 	if taskCtx, ok := ctxData.(*common.LongTasksContext); ok {
@@ -759,22 +763,69 @@ func (rp *ResourcePage) DeployResource(current common.Resource) error {
 			finalNs := make(map[string]types.NamespacedName, 0)
 			task.Progress = float32(0.1)
 			task.Update("Starting")
-			task.Step = 0.9 / float32(len(orderedResourceToDeploy))
+			toWait := make([]*k8sservice.DeployPlanItem, 0)
+			if plan.WaitReady {
+				for _, item := range orderedResourceToDeploy {
+					if item.Action.GetAction() != common.Delete {
+						toWait = append(toWait, item)
+					}
+				}
+			}
+			task.Step = 0.9 / float32(len(orderedResourceToDeploy)+len(toWait))
 
 			logger.Debug("Resources to deploy", zap.Int("count", len(orderedResourceToDeploy)))
 
-			for _, res := range orderedResourceToDeploy {
-				toDeploy := resourcesToDeploy[res]
-				if ns, reply, err := rp.k8sClient.DeployResource(toDeploy, ""); err != nil {
-					logger.Error("Failed to deploy resource", zap.Any("res", res), zap.Error(err))
+			deploy := func(item *k8sservice.DeployPlanItem) (types.NamespacedName, *unstructured.Unstructured, error) {
+				return rp.k8sClient.DeployResource(item.Action, "")
+			}
+			var tx *k8sservice.DeployTransaction
+			if plan.Transactional {
+				tx = k8sservice.NewDeployTransaction(rp.k8sClient)
+				deploy = func(item *k8sservice.DeployPlanItem) (types.NamespacedName, *unstructured.Unstructured, error) {
+					return tx.Deploy(item, "")
+				}
+			}
+			fail := func(err error) {
+				revision.Message = err.Error()
+				if tx == nil {
 					task.Failed(err)
-					rp.deployedResources.Remove(currentId)
+					rp.deployedResources.Failed(currentId, prior, revision)
+					return
+				}
+				task.SetStatus("Rolling back")
+				report := tx.Rollback(err)
+				rp.deployedResources.RolledBack(currentId, prior, report)
+				revision.Outcome = k8sservice.RevisionRolledBack
+				rp.deployedResources.AddRevision(currentId, revision)
+				appLog.Warn("Deploy rolled back", zap.String("resource", current.GetName()), zap.String(logs.REPLY_CONTENT_KEY, report.String()))
+				if failures := report.Failures(); len(failures) > 0 {
+					err = fmt.Errorf("%w, and %d resources failed to roll back", err, len(failures))
+				}
+				task.Failed(err)
+			}
+
+			for _, item := range orderedResourceToDeploy {
+				toDeploy := item.Action
+				if ns, reply, err := deploy(item); err != nil {
+					logger.Error("Failed to deploy resource", zap.String("res", item.Id), zap.Error(err))
+					fail(err)
 					return
 				} else {
 					// for a collection
 					finalNs[toDeploy.Instance.GetId()] = ns
 					//update progress
 					task.Update("deployed " + toDeploy.GetName())
+					if item.Kind == "CustomResourceDefinition" && toDeploy.GetAction() != common.Delete {
+						// the custom resources of the crd are deployed after it
+						err := rp.k8sClient.WaitForCrdEstablished(item.Name, options.Options.CrdTimeout, func(message string) {
+							task.SetStatus("crd " + item.Name + " " + message)
+						})
+						if err != nil {
+							logger.Error("Crd not established", zap.String("crd", item.Name), zap.Error(err))
+							fail(err)
+							return
+						}
+					}
 					if reply != nil {
 
 						appLog.Info("Successfully deployed", zap.String("resource", toDeploy.GetName()))
@@ -789,15 +840,108 @@ func (rp *ResourcePage) DeployResource(current common.Resource) error {
 					}
 				}
 			}
-			task.Done()
+			// a transactional deploy is rolled back if it is not ready,
+			// so it is only recorded as deployed once ready
+			healths := make(map[string]k8sservice.ResourceHealth)
+			if tx != nil {
+				err := rp.waitForReady(task, toWait, finalNs, func(instId string, health k8sservice.ResourceHealth) {
+					healths[instId] = health
+				})
+				if err != nil {
+					logger.Error("Deployed resources not ready", zap.String("res", currentId), zap.Error(err))
+					fail(err)
+					return
+				}
+				toWait = nil
+			}
 			// release locked res so that deploy button should be enabled again
 			rp.deployedResources.Deployed(currentId, finalNs)
+			revision.Outcome = k8sservice.RevisionDeployed
+			revision.SetNamespaces(finalNs)
+			rp.deployedResources.AddRevision(currentId, revision)
+			for instId, health := range healths {
+				rp.deployedResources.SetHealth(currentId, instId, health)
+			}
+
+			err := rp.waitForReady(task, toWait, finalNs, func(instId string, health k8sservice.ResourceHealth) {
+				rp.deployedResources.SetHealth(currentId, instId, health)
+			})
+			if err != nil {
+				logger.Error("Deployed resources not ready", zap.String("res", currentId), zap.Error(err))
+				task.Failed(err)
+				return
+			}
+			task.Done()
 		}
 		task.Start()
 	}
 	return nil
 }
 
+// waitForReady waits for the deployed items to be ready within the
+// ready timeout and passes their health to record. It fails if any is
+// not ready.
+func (rp *ResourcePage) waitForReady(task *common.LongTask, items []*k8sservice.DeployPlanItem, finalNs map[string]types.NamespacedName,
+	record func(instId string, health k8sservice.ResourceHealth)) error {
+	deadline := time.Now().Add(options.Options.ReadyTimeout)
+	notReady := make([]string, 0)
+	for _, item := range items {
+		health, err := rp.k8sClient.WaitForReady(item.Action, finalNs[item.Id].Namespace, time.Until(deadline), func(health k8sservice.ResourceHealth) {
+			task.SetStatus(item.Name + " " + health.String())
+		})
+		if err != nil {
+			health = k8sservice.ResourceHealth{State: k8sservice.HealthFailed, Message: err.Error(), Checked: time.Now().Format(time.RFC3339)}
+		}
+		record(item.Id, health)
+		if health.State != k8sservice.HealthReady {
+			logger.Warn("Resource not ready", zap.String("res", item.Id), zap.String("health", health.String()))
+			notReady = append(notReady, item.Kind+"/"+item.Name+" "+health.String())
+		}
+		task.Update(item.Name + " " + string(health.State))
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("%d resources not ready: %s", len(notReady), strings.Join(notReady, "; "))
+	}
+	return nil
+}
+
+func (rp *ResourcePage) deployNode(current common.Resource) (common.INode, error) {
+	inode := rp.resourceManager.GetNodeMap()[current.GetId()]
+	if inode == nil {
+		// this could happen with template resource where it's not in the repository
+		logger.Warn("Resource is a template", zap.String("Name", current.GetName()))
+		if inst, ok := current.(*common.ResourceInstance); ok {
+			inode = common.NewResourceNode(inst)
+		} else {
+			return nil, fmt.Errorf("current is not a ResourceInstance: %T", current)
+		}
+	}
+	return inode, nil
+}
+
+// PlanDeploy shows the plan of deploying current in place of the editor,
+// with the changes the server reports for a dry run if dryRun is set.
+// The deploy is done once the user approves the plan.
+func (rp *ResourcePage) PlanDeploy(current common.Resource, dryRun bool) {
+	planView := NewDeployPlanView(current, dryRun)
+	rp.planView = planView
+	go func() {
+		inode, err := rp.deployNode(current)
+		var plan *k8sservice.DeployPlan
+		if err == nil {
+			plan, err = rp.deployedResources.PlanDeploy(inode)
+		}
+		if err == nil {
+			// what earlier deploys left, even from another machine
+			if err := plan.PlanPrune(rp.k8sClient); err != nil {
+				logger.Warn("Failed to find the inventory in the cluster", zap.String("res", inode.GetId()), zap.Error(err))
+				plan.Warn("what earlier deploys left in the cluster is not pruned: " + err.Error())
+			}
+		}
+		planView.Run(rp.k8sClient, plan, err)
+	}()
+}
+
 func (rp *ResourcePage) SaveCurrent(gtx layout.Context) {
 
 	if rp.current != nil {
@@ -849,6 +993,22 @@ func (rp *ResourcePage) GetBarButtons() []layout.FlexChild {
 					},
 				)
 			}))
+
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(10)}.Layout(gtx,
+					func(gtx layout.Context) layout.Dimensions {
+						button := component.TipIconButtonStyle{
+							Tooltip:         rp.PreviewBtnTooltip,
+							IconButtonStyle: material.IconButton(th, &rp.editorBtnPreview, graphics.SearchIcon, "Preview deploy"),
+							State:           &rp.PreviewBtnTipArea,
+						}
+
+						button.Size = 20
+						button.IconButtonStyle.Inset = layout.Inset{Top: 1, Bottom: 1, Left: 1, Right: 1}
+						return button.Layout(gtx)
+					},
+				)
+			}))
 		}
 
 		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -1,10 +1,18 @@
 package common
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	crand "crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
 )
 
 func NewRsaKey(len int) (*rsa.PrivateKey, error) {
@@ -25,3 +33,98 @@ func NewEcdsaKey() (*ecdsa.PrivateKey, error) {
 	}
 	return priv, nil
 }
+
+func newSerialNumber() (*big.Int, error) {
+	return crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// NewCACert creates a self-signed CA certificate for the ecdsa key.
+// It returns the parsed certificate together with its DER bytes.
+func NewCACert(cn string, key *ecdsa.PrivateKey, months int) (*x509.Certificate, []byte, error) {
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   cn,
+			Organization: []string{APP_NAME},
+		},
+		NotBefore:             time.Now().Add(-5 * time.Minute),
+		NotAfter:              time.Now().AddDate(0, months, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(crand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, der, nil
+}
+
+// NewSignedCert issues a certificate for the key signed by the given ca.
+// hosts are put into the SANs (ip addresses or dns names). If client is
+// true the certificate is for client auth, otherwise for server auth.
+func NewSignedCert(cn string, hosts []string, client bool, key *ecdsa.PrivateKey,
+	ca *x509.Certificate, caKey crypto.Signer, months int) ([]byte, error) {
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	usage := x509.ExtKeyUsageServerAuth
+	if client {
+		usage = x509.ExtKeyUsageClientAuth
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   cn,
+			Organization: []string{APP_NAME},
+		},
+		NotBefore:   time.Now().Add(-5 * time.Minute),
+		NotAfter:    time.Now().AddDate(0, months, 0),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{usage},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	return x509.CreateCertificate(crand.Reader, tmpl, ca, &key.PublicKey, caKey)
+}
+
+func EncodeCertPem(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: der,
+	})
+}
+
+func EncodeEcdsaKeyPem(key *ecdsa.PrivateKey) ([]byte, error) {
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: keyBytes,
+	}), nil
+}
+
+// DecodeEcdsaKeyPem parses an ecdsa key encoded by EncodeEcdsaKeyPem
+func DecodeEcdsaKeyPem(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem data found")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
@@ -0,0 +1,156 @@
+package common
+
+import (
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type DiffOp int
+
+const (
+	DiffSame DiffOp = iota
+	DiffAdded
+	DiffRemoved
+)
+
+// DiffLine is a line of a diff, Text has no line ending
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+func (l DiffLine) String() string {
+	switch l.Op {
+	case DiffAdded:
+		return "+ " + l.Text
+	case DiffRemoved:
+		return "- " + l.Text
+	}
+	return "  " + l.Text
+}
+
+// DiffLines compares the texts a line at a time, the removed lines of a
+// change come before the added ones
+func DiffLines(from string, to string) []DiffLine {
+	a := splitLines(from)
+	b := splitLines(to)
+	// common[i][j] is the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	result := make([]DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			result = append(result, DiffLine{Op: DiffSame, Text: a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			result = append(result, DiffLine{Op: DiffRemoved, Text: a[i]})
+			i++
+		default:
+			result = append(result, DiffLine{Op: DiffAdded, Text: b[j]})
+			j++
+		}
+	}
+	return result
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// HasChanges tells whether the diff has added or removed lines
+func HasChanges(diff []DiffLine) bool {
+	for _, line := range diff {
+		if line.Op != DiffSame {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffContext keeps the changed lines and up to context lines around them,
+// a nil line stands for the lines left out
+func DiffContext(diff []DiffLine, context int) []*DiffLine {
+	keep := make([]bool, len(diff))
+	for i, line := range diff {
+		if line.Op != DiffSame {
+			for k := max(0, i-context); k <= min(len(diff)-1, i+context); k++ {
+				keep[k] = true
+			}
+		}
+	}
+	result := make([]*DiffLine, 0)
+	for i := range diff {
+		if keep[i] {
+			result = append(result, &diff[i])
+		} else if len(result) == 0 || result[len(result)-1] != nil {
+			result = append(result, nil)
+		}
+	}
+	return result
+}
+
+// DiffView shows a diff with the added lines in green and the removed ones in red
+type DiffView struct {
+	list  widget.List
+	lines []*DiffLine
+}
+
+func NewDiffView() *DiffView {
+	view := &DiffView{}
+	view.list.Axis = layout.Vertical
+	return view
+}
+
+// SetDiff shows the changes of diff with context lines around them
+func (v *DiffView) SetDiff(diff []DiffLine, context int) {
+	v.lines = DiffContext(diff, context)
+}
+
+func (v *DiffView) Layout(gtx layout.Context) layout.Dimensions {
+	th := GetTheme()
+	if len(v.lines) == 0 {
+		return material.Body2(th, "no changes").Layout(gtx)
+	}
+	return material.List(th, &v.list).Layout(gtx, len(v.lines), func(gtx layout.Context, index int) layout.Dimensions {
+		line := v.lines[index]
+		if line == nil {
+			label := material.Body2(th, "...")
+			label.Color = COLOR.Gray
+			return label.Layout(gtx)
+		}
+		label := material.Body2(th, line.String())
+		label.Font.Typeface = "Monospace"
+		label.TextSize = unit.Sp(13)
+		switch line.Op {
+		case DiffAdded:
+			label.Color = COLOR.DarkGreen
+			label.Font.Weight = font.Bold
+		case DiffRemoved:
+			label.Color = COLOR.Red
+			label.Font.Weight = font.Bold
+		}
+		return label.Layout(gtx)
+	})
+}
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	from := "a: 1\nb: 2\nc: 3\nd: 4\n"
+	to := "a: 1\nb: 20\nc: 3\nd: 4\ne: 5\n"
+	diff := DiffLines(from, to)
+	text := make([]string, 0)
+	for _, line := range diff {
+		text = append(text, line.String())
+	}
+	expected := "  a: 1\n- b: 2\n+ b: 20\n  c: 3\n  d: 4\n+ e: 5"
+	if strings.Join(text, "\n") != expected {
+		t.Errorf("wrong diff:\n%s", strings.Join(text, "\n"))
+	}
+	if !HasChanges(diff) || HasChanges(DiffLines(from, from)) {
+		t.Errorf("wrong changes")
+	}
+	if added := DiffLines("", "x\ny"); len(added) != 2 || added[0].Op != DiffAdded {
+		t.Errorf("wrong diff from nothing %v", added)
+	}
+
+	// the unchanged lines far from the changes are left out
+	context := DiffContext(DiffLines("1\n2\n3\n4\n5\n6\n7\n", "1\n2\n3\n4\n5\n6\nx\n"), 1)
+	if len(context) != 4 || context[0] != nil || context[1].Text != "6" || context[3].Text != "x" {
+		t.Errorf("wrong context %v", context)
+	}
+}
@@ -180,6 +180,11 @@ func (se *ReadOnlyEditor) GetText() string {
 	}
 }
 
+// FollowTail keeps the last line in view as content is appended
+func (se *ReadOnlyEditor) FollowTail(follow bool) {
+	se.list.ScrollToEnd = follow
+}
+
 type MenuAction interface {
 	GetName() string
 	GetMenuOption() func(gtx layout.Context) layout.Dimensions
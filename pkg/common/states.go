@@ -6,12 +6,15 @@ const (
 	StateNew DeployState = iota
 	StateInDeploy
 	StateDeployed
+	// a failed deploy was rolled back, nothing of it is deployed
+	StateRolledBack
 )
 
 var stateName = map[DeployState]string{
-	StateNew:      "New",
-	StateInDeploy: "InDeploy",
-	StateDeployed: "Deployed",
+	StateNew:        "New",
+	StateInDeploy:   "InDeploy",
+	StateDeployed:   "Deployed",
+	StateRolledBack: "RolledBack",
 }
 
 func (ds DeployState) String() string {
@@ -3,6 +3,7 @@ package common
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"image/color"
 	"log"
 	"os"
@@ -31,6 +32,18 @@ const (
 	Delete
 )
 
+func (a ResourceAction) String() string {
+	switch a {
+	case Create:
+		return "create"
+	case Update:
+		return "update"
+	case Delete:
+		return "delete"
+	}
+	return fmt.Sprintf("action(%d)", int(a))
+}
+
 type BuiltinKind string
 
 type INode interface {
@@ -467,6 +480,13 @@ type ResourceInstanceAction struct {
 	//User shouldn't change the apiVersion/Kind once resource is created
 	Action    ResourceAction
 	DefaultNs string
+	// the server checks the action without persisting it
+	DryRun bool `yaml:"-"`
+	// the apply takes over the fields other managers changed
+	Force bool `yaml:"-"`
+	// the id of the deployment the object is labeled with as its
+	// inventory, not labeled if empty
+	Inventory string `yaml:"-"`
 }
 
 func (r *ResourceInstanceAction) GetDefaultNamespace() string {
@@ -1192,6 +1212,8 @@ type ResourceManager interface {
 	GetNodeMap() map[string]INode
 	SaveResource(resId string)
 	SaveTemplate(current *ResourceInstance)
+	// refresh the opened resources after pos changed
+	ResourceUpdated(pos INode)
 	IsRepo(id string) bool
 }
 
@@ -198,6 +198,13 @@ func (lt *LongTask) Update(status string) {
 	GetAppWindow().Invalidate()
 }
 
+// SetStatus shows status without moving the progress, e.g. while
+// waiting in the middle of a step
+func (lt *LongTask) SetStatus(status string) {
+	lt.Status = status
+	GetAppWindow().Invalidate()
+}
+
 type LongTasksContext struct {
 	longTaskLock sync.RWMutex
 	queue        []*LongTask
@@ -11,6 +11,7 @@ import (
 const (
 	CONTEXT_SHOW_ABOUT     = "app.show.about"
 	CONTEXT_APP_INIT_STATE = "app.init.state"
+	CONTEXT_SWITCH_CONTEXT = "app.switch.context"
 )
 
 const APP_NAME = "k8sutil"
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"gaohoward.tools/k8s/resutil/pkg/logs"
+	"gaohoward.tools/k8s/resutil/pkg/options"
 	"go.uber.org/zap"
 )
 
@@ -22,7 +23,67 @@ const (
 const APP_DIR = ".k8sutil"
 
 type Config struct {
-	CollectionRepoPaths []string `json:"collection_paths"`
+	CollectionRepoPaths []string    `json:"collection_paths"`
+	Agent               AgentConfig `json:"agent"`
+	// the settings of each cluster, keyed by its api server url or its id
+	// in clusters/info.yaml
+	Clusters map[string]*ClusterConfig `json:"clusters,omitempty"`
+}
+
+// where the deployment records of a cluster are stored
+const (
+	DEPLOYMENTS_STORE_FILE      = "file"
+	DEPLOYMENTS_STORE_CONFIGMAP = "configmap"
+	DEPLOYMENTS_STORE_SECRET    = "secret"
+)
+
+// ClusterConfig holds the settings of one cluster
+type ClusterConfig struct {
+	Deployments DeploymentsConfig `json:"deployments"`
+}
+
+// DeploymentsConfig tells where the deployment records are kept. In
+// configmaps or secrets of the cluster they are shared by everyone using
+// it, in a file they are only seen locally.
+type DeploymentsConfig struct {
+	// file (the default), configmap or secret
+	Store string `json:"store,omitempty"`
+	// the namespace of the configmaps or secrets, default if empty
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GetClusterConfig returns the settings of the cluster of id or host, nil
+// if there are none
+func (c *Config) GetClusterConfig(id string, host string) *ClusterConfig {
+	if cfg, ok := c.Clusters[id]; ok {
+		return cfg
+	}
+	return c.Clusters[host]
+}
+
+// AgentConfig holds the settings for the grpc connection to/from an agent.
+// Command line flags take precedence over them.
+type AgentConfig struct {
+	Tls  options.TlsOptions `json:"tls"`
+	Auth options.AgentAuth  `json:"auth"`
+	// the token used by the gui to authenticate to the agent
+	Token string `json:"token,omitempty"`
+}
+
+// GetAgentTlsDir returns the dir where the bootstrapped agent certificates live
+func GetAgentTlsDir() (string, error) {
+	cfgDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	tlsDir := filepath.Join(cfgDir, "agent-tls")
+	if _, err := os.Stat(tlsDir); os.IsNotExist(err) {
+		err = os.MkdirAll(tlsDir, 0700)
+		if err != nil {
+			return "", err
+		}
+	}
+	return tlsDir, nil
 }
 
 func (c *Config) GetToolDir(toolName string) (string, error) {
@@ -49,6 +49,10 @@ func (dlg *InputDialog) SetError(err error) {
 	dlg.errorMessage = err.Error()
 }
 
+func (dlg *InputDialog) ClearError() {
+	dlg.errorMessage = ""
+}
+
 func (dlg *InputDialog) SetControl(control DialogControl) {
 	dlg.mainPanel = control
 }
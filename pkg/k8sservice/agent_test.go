@@ -0,0 +1,245 @@
+package k8sservice
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestPod(ns string, name string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetNamespace(ns)
+	pod.SetName(name)
+	return pod
+}
+
+func newTestDynClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podsGvr: "PodList", configMapsGvr: "ConfigMapList"}, objects...)
+}
+
+// newTestAgent starts an agent backed by a fake dynamic client over an
+// in-memory connection and returns the remote service connected to it.
+func newTestAgent(t testing.TB, objects ...runtime.Object) (*RemoteK8sService, *dynamicfake.FakeDynamicClient) {
+	dynClient := newTestDynClient(objects...)
+	remote := startTestAgent(t, &server{
+		client:      &K8sClient{dynClient: dynClient},
+		userClients: make(map[string]*K8sClient),
+	})
+	return remote, dynClient
+}
+
+func startTestAgent(t testing.TB, srv *server, opts ...grpc.ServerOption) *RemoteK8sService {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(opts...)
+	RegisterGrpcK8SServiceServer(grpcServer, srv)
+	go grpcServer.Serve(listener)
+
+	remote := &RemoteK8sService{
+		agentUrl: "bufnet",
+		Cache:    NewK8sCache(),
+	}
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(&clusterCredentials{service: remote}))
+	if err != nil {
+		t.Fatalf("failed to connect to test agent: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+
+	remote.Conn = conn
+	return remote
+}
+
+func nextEvent(t *testing.T, w watch.Interface) watch.Event {
+	select {
+	case event, ok := <-w.ResultChan():
+		if !ok {
+			t.Fatalf("watch closed unexpectedly")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for watch event")
+	}
+	return watch.Event{}
+}
+
+func TestRemoteWatch(t *testing.T) {
+	remote, dynClient := newTestAgent(t)
+
+	fakeWatch := watch.NewFake()
+	dynClient.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, fakeWatch, nil
+	})
+
+	w, err := remote.WatchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	defer w.Stop()
+
+	pod := newTestPod("default", "pod1")
+	go fakeWatch.Add(pod)
+	event := nextEvent(t, w)
+	if event.Type != watch.Added {
+		t.Fatalf("expected ADDED, got %v", event.Type)
+	}
+	if obj := event.Object.(*unstructured.Unstructured); obj.GetName() != "pod1" {
+		t.Errorf("wrong object %v", obj.GetName())
+	}
+
+	modified := pod.DeepCopy()
+	modified.SetLabels(map[string]string{"app": "test"})
+	go fakeWatch.Modify(modified)
+	event = nextEvent(t, w)
+	if event.Type != watch.Modified {
+		t.Fatalf("expected MODIFIED, got %v", event.Type)
+	}
+	if obj := event.Object.(*unstructured.Unstructured); obj.GetLabels()["app"] != "test" {
+		t.Errorf("labels not updated %v", obj.GetLabels())
+	}
+
+	go fakeWatch.Delete(modified)
+	event = nextEvent(t, w)
+	if event.Type != watch.Deleted {
+		t.Fatalf("expected DELETED, got %v", event.Type)
+	}
+
+	w.Stop()
+	for {
+		select {
+		case _, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("watch not closed after stop")
+		}
+	}
+}
+
+func TestRemoteListOptions(t *testing.T) {
+	pod1 := newTestPod("default", "pod1")
+	pod1.SetLabels(map[string]string{"app": "a"})
+	pod2 := newTestPod("default", "pod2")
+	pod2.SetLabels(map[string]string{"app": "b"})
+	remote, dynClient := newTestAgent(t, pod1, pod2)
+
+	t.Run("label selector", func(t *testing.T) {
+		list, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{
+			LabelSelector: "app=b",
+		})
+		if err != nil {
+			t.Fatalf("failed to list: %v", err)
+		}
+		if len(list.Items) != 1 || list.Items[0].GetName() != "pod2" {
+			t.Errorf("selector not applied, got %d items", len(list.Items))
+		}
+	})
+
+	t.Run("continue token", func(t *testing.T) {
+		var gotRestrictions k8stesting.ListRestrictions
+		dynClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			gotRestrictions = action.(k8stesting.ListAction).GetListRestrictions()
+			page := &unstructured.UnstructuredList{}
+			page.SetAPIVersion("v1")
+			page.SetKind("PodList")
+			page.SetContinue("page2")
+			page.Items = []unstructured.Unstructured{*pod1}
+			return true, page, nil
+		})
+		list, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{
+			FieldSelector: "metadata.name=pod1",
+			Limit:         1,
+		})
+		if err != nil {
+			t.Fatalf("failed to list: %v", err)
+		}
+		if list.GetContinue() != "page2" {
+			t.Errorf("expected continue token page2, got %q", list.GetContinue())
+		}
+		if gotRestrictions.Fields.String() != "metadata.name=pod1" {
+			t.Errorf("field selector not passed, got %q", gotRestrictions.Fields.String())
+		}
+	})
+}
+
+func TestRemoteClusters(t *testing.T) {
+	devClient := &K8sClient{
+		dynClient:   newTestDynClient(newTestPod("default", "dev-pod")),
+		clusterInfo: &common.ClusterInfo{Id: "dev-id"},
+	}
+	pool := NewClusterPool("", []string{"dev", "prod"}, "dev", devClient)
+	pool.clients["prod"] = &K8sClient{
+		dynClient:   newTestDynClient(newTestPod("default", "prod-pod")),
+		clusterInfo: &common.ClusterInfo{Id: "prod-id"},
+	}
+	remote := startTestAgent(t, &server{
+		client:      devClient,
+		clusters:    pool,
+		userClients: make(map[string]*K8sClient),
+	}, grpc.ChainUnaryInterceptor(pool.UnaryInterceptor), grpc.ChainStreamInterceptor(pool.StreamInterceptor))
+
+	podName := func() string {
+		list, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to list: %v", err)
+		}
+		if len(list.Items) != 1 {
+			t.Fatalf("expected 1 pod, got %d", len(list.Items))
+		}
+		return list.Items[0].GetName()
+	}
+
+	clusters, current, err := remote.ListContexts()
+	if err != nil {
+		t.Fatalf("failed to list clusters: %v", err)
+	}
+	if !slices.Equal(clusters, []string{"dev", "prod"}) || current != "dev" {
+		t.Errorf("wrong clusters %v, current %v", clusters, current)
+	}
+	if name := podName(); name != "dev-pod" {
+		t.Errorf("default cluster not used, got %v", name)
+	}
+
+	if err := remote.SwitchContext("prod"); err != nil {
+		t.Fatalf("failed to switch cluster: %v", err)
+	}
+	if name := podName(); name != "prod-pod" {
+		t.Errorf("target cluster not used, got %v", name)
+	}
+	if id := remote.GetClusterName(); id != "prod-id" {
+		t.Errorf("wrong cluster name %v", id)
+	}
+
+	if err := remote.SwitchContext("qa"); err == nil {
+		t.Errorf("switching to an unknown cluster should fail")
+	}
+	remote.cluster = "qa"
+	remote.Cache = NewK8sCache()
+	if _, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{}); err == nil {
+		t.Errorf("rpc to an unknown cluster should be rejected")
+	}
+}
@@ -0,0 +1,186 @@
+package k8sservice
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"gaohoward.tools/k8s/resutil/pkg/options"
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const AUTH_HEADER = "authorization"
+
+type identityKey struct{}
+
+// Identity is the authenticated caller of an agent rpc
+type Identity struct {
+	Name   string
+	User   string
+	Groups []string
+}
+
+func (i *Identity) Key() string {
+	return i.User + "|" + strings.Join(i.Groups, ",")
+}
+
+func IdentityFromContext(ctx context.Context) *Identity {
+	if id, ok := ctx.Value(identityKey{}).(*Identity); ok {
+		return id
+	}
+	return nil
+}
+
+type Authenticator struct {
+	users []options.AgentUser
+}
+
+func NewAuthenticator(auth *options.AgentAuth) (*Authenticator, error) {
+	tokens := make(map[string]string)
+	cns := make(map[string]string)
+	for _, u := range auth.Users {
+		if u.Name == "" {
+			return nil, fmt.Errorf("agent user without name")
+		}
+		if u.Token == "" && u.CertCN == "" {
+			return nil, fmt.Errorf("agent user %s has neither token nor cert_cn", u.Name)
+		}
+		if u.Token != "" {
+			if other, ok := tokens[u.Token]; ok {
+				return nil, fmt.Errorf("agent users %s and %s share the same token", other, u.Name)
+			}
+			tokens[u.Token] = u.Name
+		}
+		if u.CertCN != "" {
+			if other, ok := cns[u.CertCN]; ok {
+				return nil, fmt.Errorf("agent users %s and %s share the same cert_cn", other, u.Name)
+			}
+			cns[u.CertCN] = u.Name
+		}
+	}
+	return &Authenticator{
+		users: auth.Users,
+	}, nil
+}
+
+func toIdentity(u *options.AgentUser) *Identity {
+	id := &Identity{
+		Name:   u.Name,
+		User:   u.User,
+		Groups: u.Groups,
+	}
+	if id.User == "" {
+		id.User = u.Name
+	}
+	return id
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, v := range md.Get(AUTH_HEADER) {
+		if token, ok := strings.CutPrefix(v, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+func clientCertCN(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	// only certs verified against the agent ca count
+	for _, chain := range tlsInfo.State.VerifiedChains {
+		if len(chain) > 0 {
+			return chain[0].Subject.CommonName
+		}
+	}
+	return ""
+}
+
+// Authenticate finds the identity of the caller, a bearer token takes
+// precedence over the client cert.
+func (a *Authenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	if token := bearerToken(ctx); token != "" {
+		var found *options.AgentUser
+		for i, u := range a.users {
+			if u.Token != "" && subtle.ConstantTimeCompare([]byte(u.Token), []byte(token)) == 1 {
+				found = &a.users[i]
+			}
+		}
+		if found == nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return toIdentity(found), nil
+	}
+	if cn := clientCertCN(ctx); cn != "" {
+		for i, u := range a.users {
+			if u.CertCN == cn {
+				return toIdentity(&a.users[i]), nil
+			}
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "unknown client certificate %s", cn)
+	}
+	return nil, status.Error(codes.Unauthenticated, "no credentials")
+}
+
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id, err := a.Authenticate(ctx)
+	if err != nil {
+		logger.Info("rejected call", zap.String("method", info.FullMethod), zap.Error(err))
+		return nil, err
+	}
+	return handler(context.WithValue(ctx, identityKey{}, id), req)
+}
+
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *Authenticator) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id, err := a.Authenticate(ss.Context())
+	if err != nil {
+		logger.Info("rejected stream", zap.String("method", info.FullMethod), zap.Error(err))
+		return err
+	}
+	return handler(srv, &wrappedServerStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), identityKey{}, id),
+	})
+}
+
+// tokenCredentials puts the bearer token on every rpc the gui makes
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (t *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		AUTH_HEADER: "Bearer " + t.token,
+	}, nil
+}
+
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return t.secure
+}
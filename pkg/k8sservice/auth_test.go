@@ -0,0 +1,89 @@
+package k8sservice
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(AUTH_HEADER, "Bearer "+token))
+}
+
+func withCertCN(cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{cert}},
+			},
+		},
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth, err := NewAuthenticator(&options.AgentAuth{
+		Enabled: true,
+		Users: []options.AgentUser{
+			{Name: "alice", Token: "alice-token", Groups: []string{"dev"}},
+			{Name: "bob", CertCN: "bob-cert", User: "bob@example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	t.Run("token", func(t *testing.T) {
+		id, err := auth.Authenticate(withToken("alice-token"))
+		if err != nil {
+			t.Fatalf("failed to authenticate: %v", err)
+		}
+		if id.User != "alice" || len(id.Groups) != 1 || id.Groups[0] != "dev" {
+			t.Errorf("wrong identity %v", id)
+		}
+	})
+
+	t.Run("client cert", func(t *testing.T) {
+		id, err := auth.Authenticate(withCertCN("bob-cert"))
+		if err != nil {
+			t.Fatalf("failed to authenticate: %v", err)
+		}
+		if id.User != "bob@example.com" {
+			t.Errorf("wrong identity %v", id)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		for name, ctx := range map[string]context.Context{
+			"bad token":    withToken("guess"),
+			"unknown cert": withCertCN("eve"),
+			"anonymous":    context.Background(),
+		} {
+			_, err := auth.Authenticate(ctx)
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("%s: expected unauthenticated, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewAuthenticator(&options.AgentAuth{
+			Users: []options.AgentUser{
+				{Name: "a", Token: "same"},
+				{Name: "b", Token: "same"},
+			},
+		})
+		if err == nil {
+			t.Errorf("users sharing a token should be rejected")
+		}
+	})
+}
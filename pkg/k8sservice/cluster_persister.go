@@ -0,0 +1,318 @@
+package k8sservice
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"gaohoward.tools/k8s/resutil/pkg/config"
+	"go.uber.org/zap"
+	yamlv3 "gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DEPLOYMENT_RECORD_LABEL marks the configmaps or secrets holding the
+// deployment records
+const DEPLOYMENT_RECORD_LABEL = "k8sutil.gaohoward.tools/deployment-record"
+
+// the key of the record in the configmap or secret data
+const DEPLOYMENT_RECORD_KEY = "deployment.yaml"
+
+// MAX_RECORD_SIZE is the most data a configmap or secret can hold
+const MAX_RECORD_SIZE = 1024*1024 - len(DEPLOYMENT_RECORD_KEY)
+
+// ErrDeploymentConflict is returned when a deployment record was changed in
+// the cluster since it was loaded, e.g. by a teammate
+var ErrDeploymentConflict = errors.New("changed by someone else since it was loaded, reload the deployments")
+
+// ErrDeploymentTooLarge is returned when a deployment doesn't fit in a
+// record even without its revisions
+var ErrDeploymentTooLarge = errors.New("too large to store in the cluster")
+
+// clusterRecord is what was last read or written of a record
+type clusterRecord struct {
+	name            string
+	resourceVersion string
+	data            string
+}
+
+// ClusterDeploymentPersister keeps each deployment in a configmap or secret
+// of a namespace, so everyone using the cluster shares them. A record is
+// only written over the version it was loaded or written as, a record
+// changed by others fails with ErrDeploymentConflict. The oldest revisions
+// of a deployment too large for a record are dropped.
+type ClusterDeploymentPersister struct {
+	client K8sService
+	// configmap or secret
+	Store     string
+	Namespace string
+
+	lock  sync.Mutex
+	cache []*DeployDetail
+	// by deployment id
+	records map[string]*clusterRecord
+}
+
+func NewClusterDeploymentPersister(client K8sService, store string, namespace string) *ClusterDeploymentPersister {
+	if namespace == "" {
+		namespace = config.DEFAULT_NAMESPACE
+	}
+	return &ClusterDeploymentPersister{
+		client:    client,
+		Store:     store,
+		Namespace: namespace,
+		cache:     make([]*DeployDetail, 0),
+		records:   make(map[string]*clusterRecord),
+	}
+}
+
+func (p *ClusterDeploymentPersister) isSecret() bool {
+	return p.Store == config.DEPLOYMENTS_STORE_SECRET
+}
+
+func (p *ClusterDeploymentPersister) path(name string) string {
+	resource := "configmaps"
+	if p.isSecret() {
+		resource = "secrets"
+	}
+	path := "/api/v1/namespaces/" + p.Namespace + "/" + resource
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+// recordName is the name of the object of the deployment resId
+func recordName(resId string) string {
+	name := "k8sutil-deployment-" + strings.ToLower(resId)
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		sum := sha256.Sum256([]byte(resId))
+		name = "k8sutil-deployment-" + hex.EncodeToString(sum[:20])
+	}
+	return name
+}
+
+func (p *ClusterDeploymentPersister) do(method string, path string, body any) (*unstructured.Unstructured, error) {
+	req := &RawRequest{Method: method, Path: path}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = string(data)
+	}
+	resp, err := p.client.DoRawRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.StatusError(); err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(resp.Body)); err != nil {
+		return nil, fmt.Errorf("invalid reply of %s %s: %w", method, path, err)
+	}
+	return obj, nil
+}
+
+// recordData returns the record in obj
+func (p *ClusterDeploymentPersister) recordData(obj *unstructured.Unstructured) (string, error) {
+	data, _, _ := unstructured.NestedString(obj.Object, "data", DEPLOYMENT_RECORD_KEY)
+	if !p.isSecret() {
+		return data, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	return string(decoded), err
+}
+
+func (p *ClusterDeploymentPersister) recordObject(name string, data string, resourceVersion string) map[string]any {
+	obj := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": p.Namespace,
+			"labels":    map[string]any{DEPLOYMENT_RECORD_LABEL: "true"},
+		},
+		"data": map[string]any{DEPLOYMENT_RECORD_KEY: data},
+	}
+	if p.isSecret() {
+		obj["kind"] = "Secret"
+		obj["type"] = "Opaque"
+		obj["data"] = map[string]any{DEPLOYMENT_RECORD_KEY: base64.StdEncoding.EncodeToString([]byte(data))}
+	}
+	if resourceVersion != "" {
+		obj["metadata"].(map[string]any)["resourceVersion"] = resourceVersion
+	}
+	return obj
+}
+
+// marshalRecord marshals d to fit in a record, the oldest revisions of d
+// are dropped until it does
+func marshalRecord(d *DeployDetail) ([]byte, error) {
+	for {
+		data, err := yamlv3.Marshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", d.Name, err)
+		}
+		if len(data) <= MAX_RECORD_SIZE {
+			return data, nil
+		}
+		if len(d.Revisions) == 0 {
+			return nil, fmt.Errorf("deployment %s of %d bytes is %w, a record holds %d", d.Name, len(data), ErrDeploymentTooLarge, MAX_RECORD_SIZE)
+		}
+		logger.Warn("Dropped a revision to fit the deployment record", zap.String("deployment", d.Name), zap.Int("revision", d.Revisions[0].Number))
+		d.Revisions = slices.Delete(d.Revisions, 0, 1)
+	}
+}
+
+// write creates or updates the record of d if it changed
+func (p *ClusterDeploymentPersister) write(d *DeployDetail) error {
+	data, err := marshalRecord(d)
+	if err != nil {
+		return err
+	}
+	record, exists := p.records[d.Id]
+	if exists && record.data == string(data) {
+		return nil
+	}
+	var obj *unstructured.Unstructured
+	if exists {
+		obj, err = p.do(http.MethodPut, p.path(record.name), p.recordObject(record.name, string(data), record.resourceVersion))
+	} else {
+		record = &clusterRecord{name: recordName(d.Id)}
+		obj, err = p.do(http.MethodPost, p.path(""), p.recordObject(record.name, string(data), ""))
+	}
+	// removed or added by others counts as a change too
+	if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) || (exists && apierrors.IsNotFound(err)) {
+		return fmt.Errorf("deployment %s was %w", d.Name, ErrDeploymentConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store deployment %s: %w", d.Name, err)
+	}
+	record.resourceVersion = obj.GetResourceVersion()
+	record.data = string(data)
+	p.records[d.Id] = record
+	return nil
+}
+
+// warnNotStored logs that the records are not stored, the callers don't
+// check
+func warnNotStored(err error) error {
+	if err != nil {
+		logger.Warn("Failed to store the deployments in the cluster", zap.Error(err))
+	}
+	return err
+}
+
+// Add implements DeploymentPersister.
+func (p *ClusterDeploymentPersister) Add(d *DeployDetail) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if record, ok := p.records[d.Id]; ok {
+		// written even if the same
+		record.data = ""
+	}
+	if err := p.write(d); err != nil {
+		return warnNotStored(err)
+	}
+	p.cache = append(p.cache, d)
+	return nil
+}
+
+// Update implements DeploymentPersister, only the changed records are
+// written.
+func (p *ClusterDeploymentPersister) Update() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	errs := make([]error, 0)
+	for _, d := range p.cache {
+		if err := p.write(d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return warnNotStored(errors.Join(errs...))
+}
+
+// Remove implements DeploymentPersister.
+func (p *ClusterDeploymentPersister) Remove(d *DeployDetail) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.cache = slices.DeleteFunc(p.cache, func(detail *DeployDetail) bool {
+		return detail.Id == d.Id
+	})
+	record, ok := p.records[d.Id]
+	if !ok {
+		return nil
+	}
+	delete(p.records, d.Id)
+	options := map[string]any{
+		"apiVersion":    "v1",
+		"kind":          "DeleteOptions",
+		"preconditions": map[string]any{"resourceVersion": record.resourceVersion},
+	}
+	_, err := p.do(http.MethodDelete, p.path(record.name), options)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case apierrors.IsConflict(err):
+		return warnNotStored(fmt.Errorf("deployment %s was %w", d.Name, ErrDeploymentConflict))
+	case err != nil:
+		return warnNotStored(fmt.Errorf("failed to remove deployment %s: %w", d.Name, err))
+	}
+	return nil
+}
+
+// Load implements DeploymentPersister, the records are in the order they
+// were created.
+func (p *ClusterDeploymentPersister) Load() ([]*DeployDetail, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	list, err := p.do(http.MethodGet, p.path("")+"?labelSelector="+url.QueryEscape(DEPLOYMENT_RECORD_LABEL+"=true"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the deployments: %w", err)
+	}
+	items, err := list.ToList()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(items.Items, func(a, b unstructured.Unstructured) int {
+		if c := a.GetCreationTimestamp().Compare(b.GetCreationTimestamp().Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	p.cache = make([]*DeployDetail, 0, len(items.Items))
+	p.records = make(map[string]*clusterRecord, len(items.Items))
+	for i := range items.Items {
+		obj := &items.Items[i]
+		data, err := p.recordData(obj)
+		if err != nil {
+			logger.Warn("Invalid deployment record", zap.String("name", obj.GetName()), zap.Error(err))
+			continue
+		}
+		detail := &DeployDetail{}
+		if err := yamlv3.Unmarshal([]byte(data), detail); err != nil || detail.Id == "" {
+			logger.Warn("Invalid deployment record", zap.String("name", obj.GetName()), zap.Error(err))
+			continue
+		}
+		p.cache = append(p.cache, detail)
+		p.records[detail.Id] = &clusterRecord{
+			name:            obj.GetName(),
+			resourceVersion: obj.GetResourceVersion(),
+			data:            data,
+		}
+	}
+	return p.cache, nil
+}
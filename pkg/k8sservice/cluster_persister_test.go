@@ -0,0 +1,229 @@
+package k8sservice
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// newTestRecordServer keeps the configmaps or secrets of a namespace like
+// the api server, with the resource version checked on update and delete.
+// It returns the objects by name and the count of writes.
+func newTestRecordServer(t *testing.T) (*httptest.Server, map[string]map[string]any, *int) {
+	var lock sync.Mutex
+	objects := make(map[string]map[string]any)
+	writes := 0
+	version := 0
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		reply := func(code int, obj any) {
+			if statusErr, ok := obj.(*apierrors.StatusError); ok {
+				code = int(statusErr.ErrStatus.Code)
+				statusErr.ErrStatus.Kind = "Status"
+				obj = statusErr.ErrStatus
+			}
+			data, _ := json.Marshal(obj)
+			w.WriteHeader(code)
+			w.Write(data)
+		}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")
+		gr := schema.GroupResource{Resource: parts[1]}
+		name := ""
+		if len(parts) > 2 {
+			name = parts[2]
+		}
+		body, _ := io.ReadAll(r.Body)
+		obj := &unstructured.Unstructured{}
+		if len(body) > 0 {
+			obj.UnmarshalJSON(body)
+		}
+		stored, exists := objects[name]
+		storedVersion, _, _ := unstructured.NestedString(stored, "metadata", "resourceVersion")
+		switch {
+		case r.Method == http.MethodGet && name == "":
+			items := []any{}
+			for _, item := range objects {
+				items = append(items, item)
+			}
+			reply(http.StatusOK, map[string]any{"apiVersion": "v1", "kind": "List", "items": items})
+			return
+		case r.Method == http.MethodPost:
+			name = obj.GetName()
+			if _, ok := objects[name]; ok {
+				reply(0, apierrors.NewAlreadyExists(gr, name))
+				return
+			}
+			created = created.Add(time.Second)
+			obj.SetCreationTimestamp(metav1.NewTime(created))
+		case !exists:
+			reply(0, apierrors.NewNotFound(gr, name))
+			return
+		case r.Method == http.MethodPut:
+			if obj.GetResourceVersion() != storedVersion {
+				reply(0, apierrors.NewConflict(gr, name, errors.New("the object has been modified")))
+				return
+			}
+			obj.SetCreationTimestamp(metav1.NewTime(created))
+		case r.Method == http.MethodDelete:
+			precondition, _, _ := unstructured.NestedString(obj.Object, "preconditions", "resourceVersion")
+			if precondition != storedVersion {
+				reply(0, apierrors.NewConflict(gr, name, errors.New("the precondition failed")))
+				return
+			}
+			delete(objects, name)
+			writes++
+			reply(http.StatusOK, map[string]any{"apiVersion": "v1", "kind": "Status", "status": "Success"})
+			return
+		}
+		version++
+		writes++
+		obj.SetResourceVersion(strconv.Itoa(version))
+		objects[name] = obj.Object
+		reply(http.StatusOK, obj.Object)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, objects, &writes
+}
+
+func TestClusterDeploymentPersister(t *testing.T) {
+	for _, store := range []string{"configmap", "secret"} {
+		srv, objects, writes := newTestRecordServer(t)
+		client := &K8sClient{config: &rest.Config{Host: srv.URL}}
+		alice := NewClusterDeploymentPersister(&LocalK8sService{localClient: client}, store, "team")
+		// bob goes through an agent
+		bob := NewClusterDeploymentPersister(startTestAgent(t, &server{
+			client:      client,
+			userClients: make(map[string]*K8sClient),
+		}), store, "team")
+
+		web := &DeployDetail{Id: "Web_1", Name: "web"}
+		db := &DeployDetail{Id: "db", Name: "db"}
+		if err := alice.Add(web); err != nil {
+			t.Fatalf("%s: failed to add: %v", store, err)
+		}
+		if err := alice.Add(db); err != nil {
+			t.Fatalf("%s: failed to add: %v", store, err)
+		}
+		for name, obj := range objects {
+			data, _, _ := unstructured.NestedString(obj, "data", DEPLOYMENT_RECORD_KEY)
+			if strings.Contains(data, "name:") == (store == "secret") {
+				t.Errorf("%s: wrong data of %s: %s", store, name, data)
+			}
+		}
+
+		loaded, err := bob.Load()
+		if err != nil {
+			t.Fatalf("%s: failed to load: %v", store, err)
+		}
+		if len(loaded) != 2 || loaded[0].Id != "Web_1" || loaded[1].Name != "db" {
+			t.Fatalf("%s: wrong deployments loaded %v", store, loaded)
+		}
+
+		// nothing changed, nothing written
+		before := *writes
+		if err := alice.Update(); err != nil || *writes != before {
+			t.Errorf("%s: unchanged records written %d, %v", store, *writes-before, err)
+		}
+
+		loaded[0].Name = "web by bob"
+		if err := bob.Update(); err != nil {
+			t.Fatalf("%s: failed to update: %v", store, err)
+		}
+		web.Name = "web by alice"
+		if err := alice.Update(); !errors.Is(err, ErrDeploymentConflict) {
+			t.Errorf("%s: expected a conflict, got %v", store, err)
+		}
+		if err := alice.Remove(db); err != nil {
+			t.Errorf("%s: failed to remove: %v", store, err)
+		}
+
+		loaded, err = alice.Load()
+		if err != nil {
+			t.Fatalf("%s: failed to load: %v", store, err)
+		}
+		if len(loaded) != 1 || loaded[0].Name != "web by bob" {
+			t.Fatalf("%s: wrong deployments reloaded %v", store, loaded)
+		}
+		loaded[0].Name = "web by alice"
+		if err := alice.Update(); err != nil {
+			t.Errorf("%s: failed to update after reload: %v", store, err)
+		}
+		// bob still has the version before
+		if err := bob.Remove(loaded[0]); !errors.Is(err, ErrDeploymentConflict) {
+			t.Errorf("%s: expected a conflict removing, got %v", store, err)
+		}
+		// alice removed it
+		if err := bob.Add(&DeployDetail{Id: "db", Name: "db"}); !errors.Is(err, ErrDeploymentConflict) {
+			t.Errorf("%s: expected a conflict adding a removed one, got %v", store, err)
+		}
+		if err := alice.Add(&DeployDetail{Id: "db", Name: "db"}); err != nil {
+			t.Errorf("%s: failed to add again: %v", store, err)
+		}
+		carol := NewClusterDeploymentPersister(&LocalK8sService{localClient: client}, store, "team")
+		if err := carol.Add(&DeployDetail{Id: "db", Name: "db"}); !errors.Is(err, ErrDeploymentConflict) {
+			t.Errorf("%s: expected a conflict adding an existing one, got %v", store, err)
+		}
+		// the one failed to add is not kept
+		if err := carol.Update(); err != nil {
+			t.Errorf("%s: the failed add is written again: %v", store, err)
+		}
+	}
+}
+
+func TestClusterRecordSize(t *testing.T) {
+	srv, objects, writes := newTestRecordServer(t)
+	persister := NewClusterDeploymentPersister(&LocalK8sService{localClient: &K8sClient{config: &rest.Config{Host: srv.URL}}}, "configmap", "team")
+	newInstance := func(size int) map[string]*common.ResourceInstanceAction {
+		return map[string]*common.ResourceInstanceAction{
+			"web": {Instance: &common.ResourceInstance{Id: "web", Cr: strings.Repeat("x", size)}},
+		}
+	}
+
+	web := &DeployDetail{Id: "web", Name: "web", AllInstances: newInstance(1000)}
+	for i := range MAX_REVISIONS {
+		web.Revisions = append(web.Revisions, &DeployRevision{Number: i + 1, Instances: newInstance(MAX_RECORD_SIZE / 4)})
+	}
+	if err := persister.Add(web); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	data, _, _ := unstructured.NestedString(objects[recordName("web")], "data", DEPLOYMENT_RECORD_KEY)
+	if len(data) > MAX_RECORD_SIZE || len(web.Revisions) != 3 || web.Revisions[0].Number != 8 {
+		t.Errorf("the record is not pruned to fit, %d bytes with %d revisions", len(data), len(web.Revisions))
+	}
+
+	// too large even without revisions, nothing is written
+	before := *writes
+	huge := &DeployDetail{Id: "huge", Name: "huge", AllInstances: newInstance(MAX_RECORD_SIZE)}
+	if err := persister.Add(huge); !errors.Is(err, ErrDeploymentTooLarge) || *writes != before {
+		t.Errorf("expected a too large error without writing, got %v", err)
+	}
+	if err := persister.Update(); err != nil {
+		t.Errorf("the too large deployment is written again: %v", err)
+	}
+}
+
+func TestRecordName(t *testing.T) {
+	// not a valid name, hashed
+	if name := recordName("Web_1"); len(name) != len("k8sutil-deployment-")+40 {
+		t.Errorf("wrong name %s", name)
+	}
+	if recordName("8c5e4d1f-3a5b") != "k8sutil-deployment-8c5e4d1f-3a5b" {
+		t.Errorf("wrong name of a uuid %s", recordName("8c5e4d1f-3a5b"))
+	}
+}
@@ -0,0 +1,171 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"gaohoward.tools/k8s/resutil/pkg/options"
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the kubeconfig context an rpc targets, the agent's default cluster if absent
+const CLUSTER_HEADER = "x-target-cluster"
+
+type clusterKey struct{}
+
+// targetCluster is the cluster resolved for an rpc
+type targetCluster struct {
+	name   string
+	client *K8sClient
+}
+
+func clusterFromContext(ctx context.Context) *targetCluster {
+	if c, ok := ctx.Value(clusterKey{}).(*targetCluster); ok {
+		return c
+	}
+	return nil
+}
+
+// ClusterPool holds the clients of the kubeconfig contexts an agent serves,
+// keyed by context name. A client is created on first use of its context.
+type ClusterPool struct {
+	lock        sync.Mutex
+	configPath  string
+	names       []string
+	defaultName string
+	clients     map[string]*K8sClient
+}
+
+// NewClusterPool serves the given contexts of the kubeconfig, defaultClient
+// serves the rpcs without a target cluster and is registered as defaultName.
+func NewClusterPool(configPath string, names []string, defaultName string, defaultClient *K8sClient) *ClusterPool {
+	pool := &ClusterPool{
+		configPath:  configPath,
+		names:       slices.Clone(names),
+		defaultName: defaultName,
+		clients:     make(map[string]*K8sClient),
+	}
+	if defaultName != "" {
+		if !slices.Contains(pool.names, defaultName) {
+			pool.names = append(pool.names, defaultName)
+		}
+		pool.clients[defaultName] = defaultClient
+	}
+	slices.Sort(pool.names)
+	return pool
+}
+
+// Names returns the served contexts and the default one
+func (p *ClusterPool) Names() ([]string, string) {
+	return p.names, p.defaultName
+}
+
+// Get returns the client of a served context
+func (p *ClusterPool) Get(name string) (*K8sClient, error) {
+	if !slices.Contains(p.names, name) {
+		return nil, status.Errorf(codes.NotFound, "cluster %s is not served by the agent", name)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if c, ok := p.clients[name]; ok {
+		return c, nil
+	}
+	c := NewK8sClient(p.configPath, name)
+	if options.Options.InformerCache {
+		c.EnableInformerCache(options.Options.InformerIdleTimeout)
+	}
+	p.clients[name] = c
+	return c, nil
+}
+
+// newAgentClusterPool serves the contexts of the agent's kubeconfig, or only those
+// given in the options. The default cluster is the one of the internal client.
+func newAgentClusterPool() (*ClusterPool, error) {
+	contexts, current, err := ListKubeContexts(options.Options.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	names := contexts
+	if len(options.Options.Clusters) > 0 {
+		for _, name := range options.Options.Clusters {
+			if !slices.Contains(contexts, name) {
+				return nil, fmt.Errorf("context %s not found in %s", name, options.Options.Kubeconfig)
+			}
+		}
+		names = options.Options.Clusters
+	}
+	defaultName := options.Options.Context
+	if defaultName == "" {
+		defaultName = current
+	}
+	return NewClusterPool(options.Options.Kubeconfig, names, defaultName, getInternalClient()), nil
+}
+
+func targetClusterName(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(CLUSTER_HEADER); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (p *ClusterPool) resolve(ctx context.Context) (context.Context, error) {
+	name := targetClusterName(ctx)
+	if name == "" {
+		return ctx, nil
+	}
+	client, err := p.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, clusterKey{}, &targetCluster{name: name, client: client}), nil
+}
+
+func (p *ClusterPool) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	clusterCtx, err := p.resolve(ctx)
+	if err != nil {
+		logger.Info("rejected call", zap.String("method", info.FullMethod), zap.Error(err))
+		return nil, err
+	}
+	return handler(clusterCtx, req)
+}
+
+func (p *ClusterPool) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	clusterCtx, err := p.resolve(ss.Context())
+	if err != nil {
+		logger.Info("rejected stream", zap.String("method", info.FullMethod), zap.Error(err))
+		return err
+	}
+	return handler(srv, &wrappedServerStream{
+		ServerStream: ss,
+		ctx:          clusterCtx,
+	})
+}
+
+// clusterCredentials puts the target cluster on every rpc the gui makes
+type clusterCredentials struct {
+	service *RemoteK8sService
+}
+
+func (c *clusterCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	cluster := c.service.getCluster()
+	if cluster == "" {
+		return nil, nil
+	}
+	return map[string]string{
+		CLUSTER_HEADER: cluster,
+	}, nil
+}
+
+func (c *clusterCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,101 @@
+package k8sservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var crdGvr = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// how often a CRD is checked while waiting for it
+var crdPollInterval = 500 * time.Millisecond
+
+// crdStatus tells whether the crd is established, or what it waits for.
+// It fails if the crd can never be established.
+func crdStatus(crd *unstructured.Unstructured) (bool, string, error) {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	message := "waiting for the conditions"
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		condType, _ := condition["type"].(string)
+		condStatus, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		switch {
+		case condType == "Established" && condStatus == "True":
+			return true, "established", nil
+		case condType == "NamesAccepted" && condStatus == "False":
+			condMessage, _ := condition["message"].(string)
+			return false, "", fmt.Errorf("names not accepted: %s %s", reason, condMessage)
+		case condType == "Established":
+			message = "not established yet: " + reason
+		}
+	}
+	return false, message, nil
+}
+
+// WaitForCrdEstablished waits until the named CRD is established, then
+// drops the cached discovery so that the next lookups find its resources.
+// The states of the crd seen while waiting are passed to progress.
+func (k *K8sClient) WaitForCrdEstablished(name string, timeout time.Duration, progress func(string)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	last := ""
+	report := func(message string) {
+		if message != last {
+			last = message
+			progress(message)
+		}
+	}
+	err := wait.PollUntilContextCancel(ctx, crdPollInterval, true, func(ctx context.Context) (bool, error) {
+		crd, err := k.dynClient.Resource(crdGvr).Get(ctx, name, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			report("not found yet")
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		established, message, err := crdStatus(crd)
+		if err != nil {
+			return false, err
+		}
+		report(message)
+		return established, nil
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("crd %s is not established after %v, %s", name, timeout, last)
+		}
+		return err
+	}
+	k.InvalidateDiscovery()
+	return nil
+}
+
+// InvalidateDiscovery drops the cached api resources and mappings so
+// that the resources added since, e.g. by a CRD, are found
+func (k *K8sClient) InvalidateDiscovery() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.discoveryClient != nil {
+		k.discoveryClient.Invalidate()
+	}
+	if k.mapper != nil {
+		k.mapper.Reset()
+	}
+	k.allRes = nil
+	logger.Debug("discovery invalidated", zap.String("context", k.context))
+}
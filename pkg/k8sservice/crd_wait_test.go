@@ -0,0 +1,140 @@
+package k8sservice
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newTestCrd(name string, conditions ...map[string]any) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName(name)
+	list := []any{}
+	for _, condition := range conditions {
+		list = append(list, condition)
+	}
+	unstructured.SetNestedSlice(crd.Object, list, "status", "conditions")
+	return crd
+}
+
+func condition(condType string, status string, reason string) map[string]any {
+	return map[string]any{"type": condType, "status": status, "reason": reason, "message": reason}
+}
+
+// progressRecorder collects the progress of a wait
+type progressRecorder struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (p *progressRecorder) add(message string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.messages = append(p.messages, message)
+}
+
+func (p *progressRecorder) String() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return strings.Join(p.messages, ",")
+}
+
+func TestWaitForCrdEstablished(t *testing.T) {
+	defer func(interval time.Duration) { crdPollInterval = interval }(crdPollInterval)
+	crdPollInterval = 10 * time.Millisecond
+
+	brokers := schema.GroupKind{Group: "example.com", Kind: "Broker"}
+	client, dynClient, fake := newTestDiscoveryClient(newTestCrd("brokers.example.com",
+		condition("NamesAccepted", "True", "NoConflicts"),
+		condition("Established", "False", "Installing")))
+	remote := startTestAgent(t, &server{
+		client:      client,
+		userClients: make(map[string]*K8sClient),
+	})
+
+	// the mappings are cached before the crd is served
+	if _, err := client.RetrieveMapping(brokers, "v1", false); err == nil {
+		t.Fatalf("unexpected mapping of an unknown kind")
+	}
+	fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "brokers", Kind: "Broker", Namespaced: true}},
+	})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		established := newTestCrd("brokers.example.com",
+			condition("NamesAccepted", "True", "NoConflicts"),
+			condition("Established", "True", "InitialNamesAccepted"))
+		dynClient.Resource(crdGvr).Update(context.TODO(), established, metav1.UpdateOptions{})
+	}()
+
+	for _, service := range []K8sService{&LocalK8sService{localClient: client}, remote} {
+		progress := &progressRecorder{}
+		if err := service.WaitForCrdEstablished("brokers.example.com", 5*time.Second, progress.add); err != nil {
+			t.Fatalf("failed to wait: %v", err)
+		}
+		if !strings.HasSuffix(progress.String(), "established") {
+			t.Errorf("wrong progress %q", progress)
+		}
+	}
+	if _, err := client.RetrieveMapping(brokers, "v1", false); err != nil {
+		t.Errorf("discovery not refreshed: %v", err)
+	}
+
+	progress := &progressRecorder{}
+	err := remote.WaitForCrdEstablished("missing.example.com", 100*time.Millisecond, progress.add)
+	if err == nil || !strings.Contains(err.Error(), "not established after") || progress.String() != "not found yet" {
+		t.Errorf("expected a timeout, got %v %q", err, progress)
+	}
+
+	dynClient.Resource(crdGvr).Create(context.TODO(), newTestCrd("conflict.example.com",
+		condition("NamesAccepted", "False", "KindConflict"),
+		condition("Established", "False", "NotAccepted")), metav1.CreateOptions{})
+	started := time.Now()
+	err = client.WaitForCrdEstablished("conflict.example.com", 5*time.Second, progress.add)
+	if err == nil || !strings.Contains(err.Error(), "names not accepted") || time.Since(started) > time.Second {
+		t.Errorf("expected the names not accepted, got %v", err)
+	}
+}
+
+func TestWaitForCrdImpersonated(t *testing.T) {
+	client, dynClient, fake := newTestDiscoveryClient(newTestCrd("brokers.example.com",
+		condition("NamesAccepted", "True", "NoConflicts"),
+		condition("Established", "True", "InitialNamesAccepted")))
+	// alice waits with her own client
+	alice := &Identity{User: "alice"}
+	remote := startTestAgent(t, &server{
+		client: client,
+		userClients: map[string]*K8sClient{"/" + alice.Key(): {
+			dynClient:       dynClient,
+			discoveryClient: client.discoveryClient,
+			mapper:          client.mapper,
+		}},
+	}, grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), identityKey{}, alice)})
+	}))
+
+	if client.FetchAllApiResources(false) == nil {
+		t.Fatalf("no api resources")
+	}
+	fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "brokers", Kind: "Broker", Namespaced: true}},
+	})
+	if err := remote.WaitForCrdEstablished("brokers.example.com", 5*time.Second, func(string) {}); err != nil {
+		t.Fatalf("failed to wait: %v", err)
+	}
+	// served to everyone from the cluster's client
+	if _, ok := client.FetchAllApiResources(false).ResMap["example.com/v1/brokers"]; !ok {
+		t.Errorf("the api resources of the cluster are not refreshed")
+	}
+}
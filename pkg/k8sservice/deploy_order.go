@@ -0,0 +1,335 @@
+package k8sservice
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+)
+
+// DEPENDS_ON_ANNOTATION lists the resources of the same deploy that must be
+// deployed before the annotated one, separated by commas. A resource is
+// referred to as ns/kind/name, kind/name or just name, e.g.
+// "ConfigMap/settings,db". Without a namespace the one in the namespace
+// of the annotated resource is preferred.
+const DEPENDS_ON_ANNOTATION = "k8sutil.gaohoward.tools/depends-on"
+
+// KindDeployOrder is the order kinds are deployed in, like helm installs.
+// Kinds not in the list, e.g. custom resources, are deployed last.
+var KindDeployOrder = []string{
+	"CustomResourceDefinition",
+	"PriorityClass",
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"SecretList",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"ClusterRole",
+	"ClusterRoleList",
+	"ClusterRoleBinding",
+	"ClusterRoleBindingList",
+	"Role",
+	"RoleList",
+	"RoleBinding",
+	"RoleBindingList",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+func kindRank(kind string) int {
+	if rank := slices.Index(KindDeployOrder, kind); rank >= 0 {
+		return rank
+	}
+	return len(KindDeployOrder)
+}
+
+type orderNode struct {
+	id    string
+	kind  string
+	name  string
+	ns    string
+	rank  int
+	order int
+	deps  []string
+	// the nodes that depend on this one
+	dependents []*orderNode
+	pending    int
+}
+
+func (n *orderNode) String() string {
+	return n.kind + "/" + n.name
+}
+
+func (n *orderNode) before(o *orderNode) bool {
+	if n.rank != o.rank {
+		return n.rank < o.rank
+	}
+	if n.order != o.order {
+		return n.order < o.order
+	}
+	if n.name != o.name {
+		return n.name < o.name
+	}
+	return n.id < o.id
+}
+
+// orderQueue is the nodes ready to deploy, the first by kind goes first
+type orderQueue []*orderNode
+
+func (q orderQueue) Len() int           { return len(q) }
+func (q orderQueue) Less(i, j int) bool { return q[i].before(q[j]) }
+func (q orderQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *orderQueue) Push(x any)        { *q = append(*q, x.(*orderNode)) }
+func (q *orderQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+func newOrderNode(id string, action *common.ResourceInstanceAction) (*orderNode, error) {
+	obj := &unstructured.Unstructured{}
+	dec := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	_, gvk, err := dec.Decode([]byte(action.Instance.GetCR()), nil, obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", action.GetName(), err)
+	}
+	node := &orderNode{
+		id:   id,
+		kind: gvk.Kind,
+		name: obj.GetName(),
+		ns:   obj.GetNamespace(),
+		rank: kindRank(gvk.Kind),
+	}
+	if node.ns == "" {
+		node.ns = action.GetDefaultNamespace()
+	}
+	if action.Instance.Order != nil {
+		node.order = *action.Instance.Order
+	}
+	for _, dep := range strings.Split(obj.GetAnnotations()[DEPENDS_ON_ANNOTATION], ",") {
+		if dep = strings.TrimSpace(dep); dep != "" {
+			node.deps = append(node.deps, dep)
+		}
+	}
+	return node, nil
+}
+
+func newOrderNodes(actions map[string]*common.ResourceInstanceAction) ([]*orderNode, error) {
+	nodes := make([]*orderNode, 0, len(actions))
+	for id, action := range actions {
+		node, err := newOrderNode(id, action)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// resolve finds the node a depends-on reference of the from node is to
+func resolve(ref string, from *orderNode, nodes []*orderNode) (*orderNode, error) {
+	ns, kind, name := "", "", ref
+	switch parts := strings.Split(ref, "/"); len(parts) {
+	case 1:
+	case 2:
+		kind, name = parts[0], parts[1]
+	case 3:
+		ns, kind, name = parts[0], parts[1], parts[2]
+	default:
+		return nil, fmt.Errorf("%q is not a valid reference", ref)
+	}
+	var matches []*orderNode
+	for _, node := range nodes {
+		if node.name != name || (kind != "" && !strings.EqualFold(node.kind, kind)) || (ns != "" && node.ns != ns) {
+			continue
+		}
+		matches = append(matches, node)
+	}
+	if len(matches) > 1 && ns == "" {
+		// default to the namespace of the dependent
+		local := slices.DeleteFunc(slices.Clone(matches), func(node *orderNode) bool {
+			return node.ns != from.ns
+		})
+		if len(local) > 0 {
+			matches = local
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%q is not in the deploy", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("%q matches both %s/%s and %s/%s", ref, matches[0].ns, matches[0], matches[1].ns, matches[1])
+}
+
+// sortNodes orders the nodes by their dependencies first, then by kind.
+// The dependencies are looked up in known, those that are not among
+// the nodes don't take part in the order. If strict, a dependency
+// that is not known is an error, otherwise it is left out.
+func sortNodes(nodes []*orderNode, known []*orderNode, strict bool) ([]string, error) {
+	byId := make(map[string]*orderNode, len(nodes))
+	for _, node := range nodes {
+		byId[node.id] = node
+	}
+	for _, node := range nodes {
+		for _, ref := range node.deps {
+			found, err := resolve(ref, node, known)
+			if err != nil {
+				if strict {
+					return nil, fmt.Errorf("%s depends on %w", node, err)
+				}
+				continue
+			}
+			if found.id == node.id {
+				return nil, fmt.Errorf("%s depends on itself", node)
+			}
+			if dep, ok := byId[found.id]; ok {
+				dep.dependents = append(dep.dependents, node)
+				node.pending++
+			}
+		}
+	}
+
+	ready := orderQueue{}
+	for _, node := range nodes {
+		if node.pending == 0 {
+			ready = append(ready, node)
+		}
+	}
+	heap.Init(&ready)
+	result := make([]string, 0, len(nodes))
+	for ready.Len() > 0 {
+		node := heap.Pop(&ready).(*orderNode)
+		result = append(result, node.id)
+		for _, dependent := range node.dependents {
+			dependent.pending--
+			if dependent.pending == 0 {
+				heap.Push(&ready, dependent)
+			}
+		}
+	}
+	if len(result) < len(nodes) {
+		return nil, fmt.Errorf("dependency cycle: %s", findCycle(nodes))
+	}
+	return result, nil
+}
+
+// findCycle returns a cycle among the nodes left pending by sortNodes
+func findCycle(nodes []*orderNode) string {
+	// follow a pending dependency back until a node is seen twice
+	depOf := make(map[*orderNode]*orderNode)
+	for _, node := range nodes {
+		for _, dependent := range node.dependents {
+			if node.pending > 0 && dependent.pending > 0 {
+				depOf[dependent] = node
+			}
+		}
+	}
+	var start *orderNode
+	for _, node := range nodes {
+		if _, ok := depOf[node]; ok {
+			start = node
+			break
+		}
+	}
+	seen := make(map[*orderNode]int)
+	path := []*orderNode{}
+	for node := start; node != nil; node = depOf[node] {
+		if at, ok := seen[node]; ok {
+			cycle := []string{}
+			for _, n := range path[at:] {
+				cycle = append(cycle, n.String())
+			}
+			cycle = append(cycle, node.String())
+			return strings.Join(cycle, " -> ")
+		}
+		seen[node] = len(path)
+		path = append(path, node)
+	}
+	return "unknown"
+}
+
+// DeployOrder returns the ids of the actions in the order to run them.
+// Resources are created or updated after their dependencies and by kind,
+// those to delete go last in the reverse order. The dependencies are
+// checked among all the resources of the deploy, including the
+// unchanged ones that have no action.
+func DeployOrder(actions map[string]*common.ResourceInstanceAction, all map[string]*common.ResourceInstanceAction) ([]string, error) {
+	allNodes, err := newOrderNodes(all)
+	if err != nil {
+		return nil, err
+	}
+	// a cycle or a missing dependency is an error even if the
+	// resources involved are unchanged
+	if _, err := sortNodes(allNodes, allNodes, true); err != nil {
+		return nil, err
+	}
+
+	nodes, err := newOrderNodes(actions)
+	if err != nil {
+		return nil, err
+	}
+	applies := make([]*orderNode, 0, len(nodes))
+	deletes := make([]*orderNode, 0)
+	for _, node := range nodes {
+		if actions[node.id].GetAction() == common.Delete {
+			deletes = append(deletes, node)
+		} else {
+			applies = append(applies, node)
+		}
+	}
+	result, err := sortNodes(applies, allNodes, true)
+	if err != nil {
+		return nil, err
+	}
+	// the resources to delete are gone from the deploy,
+	// only the order among them matters
+	deleteOrder, err := sortNodes(deletes, deletes, false)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(deleteOrder)
+	return append(result, deleteOrder...), nil
+}
+
+// UndeployOrder returns the ids of the actions in the reverse of their
+// deploy order
+func UndeployOrder(actions map[string]*common.ResourceInstanceAction) ([]string, error) {
+	nodes, err := newOrderNodes(actions)
+	if err != nil {
+		return nil, err
+	}
+	result, err := sortNodes(nodes, nodes, false)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(result)
+	return result, nil
+}
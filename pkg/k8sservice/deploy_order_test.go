@@ -0,0 +1,163 @@
+package k8sservice
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"github.com/google/uuid"
+)
+
+func newTestKindAction(kind string, name string, dependsOn string) *common.ResourceInstanceAction {
+	cr := fmt.Sprintf("apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, name)
+	if dependsOn != "" {
+		cr += fmt.Sprintf("  annotations:\n    %s: %q\n", DEPENDS_ON_ANNOTATION, dependsOn)
+	}
+	return &common.ResourceInstanceAction{
+		Instance: &common.ResourceInstance{Id: kind + "/" + name, Cr: cr},
+	}
+}
+
+func newTestActionMap(actions ...*common.ResourceInstanceAction) map[string]*common.ResourceInstanceAction {
+	actionMap := make(map[string]*common.ResourceInstanceAction)
+	for _, action := range actions {
+		actionMap[action.Instance.Id] = action
+	}
+	return actionMap
+}
+
+func TestDeployOrderKinds(t *testing.T) {
+	// in the deploy order, a custom resource last
+	kinds := []string{"CustomResourceDefinition", "Namespace", "ServiceAccount", "Secret", "ConfigMap", "Role", "Deployment", "Ingress", "Broker"}
+	actions := newTestActionMap()
+	for i := range 30 {
+		kind := kinds[rand.IntN(len(kinds))]
+		action := newTestKindAction(kind, fmt.Sprintf("res%d", i), "")
+		actions[action.Instance.Id] = action
+	}
+	order, err := DeployOrder(actions, actions)
+	if err != nil {
+		t.Fatalf("failed to order: %v", err)
+	}
+	if len(order) != len(actions) {
+		t.Fatalf("wrong order length %d", len(order))
+	}
+	ranks := []int{}
+	for _, id := range order {
+		kind, _, _ := strings.Cut(id, "/")
+		ranks = append(ranks, slices.Index(kinds, kind))
+	}
+	if !slices.IsSorted(ranks) {
+		t.Errorf("wrong kind order %v", order)
+	}
+
+	undeploy, err := UndeployOrder(actions)
+	if err != nil {
+		t.Fatalf("failed to order: %v", err)
+	}
+	slices.Reverse(undeploy)
+	if !slices.Equal(undeploy, order) {
+		t.Errorf("undeploy is not the reverse of deploy: %v", undeploy)
+	}
+}
+
+func TestDeployOrderNamespacesFirst(t *testing.T) {
+	// the namespaces are deployed before the resources in them
+	kinds := []string{"Pod", "Deployment", "Secret", "ConfigMap", "StatefulSet"}
+	actions := newTestActionMap()
+	nsIds := make(map[string]bool)
+	for i := range 20 {
+		id := uuid.NewString()
+		kind := kinds[rand.IntN(len(kinds))]
+		if i%4 == 0 {
+			kind = "Namespace"
+			nsIds[id] = true
+		}
+		action := newTestKindAction(kind, fmt.Sprintf("res%d", i), "")
+		action.Instance.Id = id
+		actions[id] = action
+	}
+	order, err := DeployOrder(actions, actions)
+	if err != nil {
+		t.Fatalf("failed to order: %v", err)
+	}
+	if len(order) != len(actions) || len(nsIds) != 5 {
+		t.Fatalf("wrong order length %d", len(order))
+	}
+	for _, id := range order[:len(nsIds)] {
+		if !nsIds[id] {
+			t.Fatalf("the namespaces are not first: %v", order)
+		}
+	}
+}
+
+func TestDeployOrderDependsOn(t *testing.T) {
+	all := newTestActionMap(
+		newTestKindAction("ConfigMap", "settings", "Deployment/db"),
+		newTestKindAction("Deployment", "db", ""),
+		newTestKindAction("Deployment", "web", "settings, Service/db"),
+		newTestKindAction("Service", "db", "Deployment/db"),
+		newTestKindAction("Namespace", "apps", ""),
+	)
+	order, err := DeployOrder(all, all)
+	if err != nil {
+		t.Fatalf("failed to order: %v", err)
+	}
+	expected := []string{"Namespace/apps", "Deployment/db", "ConfigMap/settings", "Service/db", "Deployment/web"}
+	if !slices.Equal(order, expected) {
+		t.Errorf("wrong order %v", order)
+	}
+
+	// the unchanged resources don't take part, those to delete go last
+	actions := newTestActionMap(all["Deployment/web"], all["ConfigMap/settings"], newTestKindAction("Secret", "old", ""))
+	actions["Secret/old"].SetAction(common.Delete)
+	order, err = DeployOrder(actions, all)
+	if err != nil || !slices.Equal(order, []string{"ConfigMap/settings", "Deployment/web", "Secret/old"}) {
+		t.Errorf("wrong order %v %v", order, err)
+	}
+
+	cycle := newTestActionMap(
+		newTestKindAction("ConfigMap", "a", "b"),
+		newTestKindAction("ConfigMap", "b", "c"),
+		newTestKindAction("ConfigMap", "c", "a"),
+		newTestKindAction("ConfigMap", "d", "a"),
+	)
+	if _, err := DeployOrder(cycle, cycle); err == nil || !strings.Contains(err.Error(), "dependency cycle") ||
+		!strings.Contains(err.Error(), "ConfigMap/a") {
+		t.Errorf("expected a cycle, got %v", err)
+	}
+
+	for dependsOn, message := range map[string]string{
+		"missing":        `"missing" is not in the deploy`,
+		"Deployment/web": "depends on itself",
+		"db":             `"db" matches both`,
+	} {
+		bad := newTestActionMap(all["Deployment/db"], all["Service/db"], newTestKindAction("Deployment", "web", dependsOn))
+		if _, err := DeployOrder(bad, bad); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected %q, got %v", message, err)
+		}
+	}
+
+	// the same resource in several namespaces
+	inNs := func(ns string, action *common.ResourceInstanceAction) *common.ResourceInstanceAction {
+		action.Instance.Id = ns + "/" + action.Instance.Id
+		action.DefaultNs = ns
+		return action
+	}
+	namespaced := newTestActionMap(
+		inNs("apps", newTestKindAction("Deployment", "web", "ConfigMap/settings")),
+		inNs("apps", newTestKindAction("ConfigMap", "settings", "other/ConfigMap/settings")),
+		inNs("other", newTestKindAction("ConfigMap", "settings", "")),
+	)
+	order, err = DeployOrder(namespaced, namespaced)
+	if err != nil || !slices.Equal(order, []string{"other/ConfigMap/settings", "apps/ConfigMap/settings", "apps/Deployment/web"}) {
+		t.Errorf("wrong order in namespaces %v %v", order, err)
+	}
+	namespaced["third/Deployment/web"] = inNs("third", newTestKindAction("Deployment", "web", "settings"))
+	if _, err := DeployOrder(namespaced, namespaced); err == nil || !strings.Contains(err.Error(), `"settings" matches both`) {
+		t.Errorf("expected an ambiguous reference, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package k8sservice
+
+import (
+	"slices"
+	"sort"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+)
+
+// DeployPlanItem is what a deploy does to one resource
+type DeployPlanItem struct {
+	Id        string
+	Kind      string
+	Name      string
+	Namespace string
+	Reason    string
+	// the action to take, nil if the resource is unchanged
+	Action *common.ResourceInstanceAction
+	// the user chose not to deploy it
+	Skip bool
+	// a warning about the plan rather than a resource, it has no action
+	Warning bool
+}
+
+// ActionName is the action or "none" for an unchanged resource
+func (i *DeployPlanItem) ActionName() string {
+	if i.Action == nil {
+		return "none"
+	}
+	return i.Action.GetAction().String()
+}
+
+// DeployPlan lists in order what deploying a resource or collection does,
+// the user reviews it and skips items before it runs
+type DeployPlan struct {
+	ResId   string
+	ResName string
+	Items   []*DeployPlanItem
+	// wait for the deployed resources to be ready after the deploy
+	WaitReady bool
+	// roll back what is deployed if the deploy fails. With WaitReady a
+	// resource not ready within the ready timeout fails the deploy too,
+	// it is rolled back and recorded as a RevisionRolledBack revision.
+	// Without WaitReady the readiness is not checked.
+	Transactional bool
+
+	// the instance ids the deployment has after the deploy, the others
+	// labeled as its inventory are pruned
+	desired map[string]bool
+}
+
+// Warn adds a warning about the plan before its items
+func (p *DeployPlan) Warn(reason string) {
+	p.Items = slices.Insert(p.Items, 0, &DeployPlanItem{Kind: "Warning", Reason: reason, Warning: true})
+}
+
+// Approved returns the items to deploy in order
+func (p *DeployPlan) Approved() []*DeployPlanItem {
+	approved := make([]*DeployPlanItem, 0, len(p.Items))
+	for _, item := range p.Items {
+		if item.Action != nil && !item.Skip {
+			approved = append(approved, item)
+		}
+	}
+	return approved
+}
+
+// PlanDeploy works out the plan of deploying resNode the way LockAndAdd
+// does, the deployed resources are not changed. The actions are put in
+// their DeployOrder, the unchanged resources go last.
+func (d *DeployedResources) PlanDeploy(resNode common.INode) (*DeployPlan, error) {
+	all, err := NewDeployDetail(resNode).ParseResources()
+	if err != nil {
+		return nil, err
+	}
+	actions, err := d.PendingActions(resNode)
+	if err != nil {
+		return nil, err
+	}
+	order, err := DeployOrder(actions, all)
+	if err != nil {
+		return nil, err
+	}
+	// a rolled back deployment has nothing deployed
+	dd, deployed := d.resIds[resNode.GetId()]
+	deployed = deployed && len(dd.AllInstances) > 0
+
+	plan := &DeployPlan{
+		ResId:   resNode.GetId(),
+		ResName: resNode.GetName(),
+		desired: make(map[string]bool, len(all)),
+	}
+	for id := range all {
+		plan.desired[id] = true
+	}
+	for _, id := range order {
+		actions[id].Inventory = plan.ResId
+		item := newDeployPlanItem(id, actions[id])
+		switch item.Action.GetAction() {
+		case common.Create:
+			if deployed {
+				item.Reason = "added since the last deploy"
+			} else {
+				item.Reason = "not deployed yet"
+			}
+		case common.Update:
+			item.Reason = "changed since the last deploy"
+		case common.Delete:
+			item.Reason = "removed since the last deploy"
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	unchanged := make([]string, 0)
+	for id := range all {
+		if _, ok := actions[id]; !ok {
+			unchanged = append(unchanged, id)
+		}
+	}
+	sort.Strings(unchanged)
+	for _, id := range unchanged {
+		item := newDeployPlanItem(id, all[id])
+		item.Action = nil
+		item.Reason = "unchanged since the last deploy"
+		plan.Items = append(plan.Items, item)
+	}
+	return plan, nil
+}
+
+func newDeployPlanItem(id string, action *common.ResourceInstanceAction) *DeployPlanItem {
+	item := &DeployPlanItem{
+		Id:        id,
+		Name:      action.GetName(),
+		Namespace: action.GetDefaultNamespace(),
+		Action:    action,
+	}
+	obj := &unstructured.Unstructured{}
+	dec := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	if _, gvk, err := dec.Decode([]byte(action.Instance.GetCR()), nil, obj); err == nil {
+		item.Kind = gvk.Kind
+		item.Name = obj.GetName()
+		if obj.GetNamespace() != "" {
+			item.Namespace = obj.GetNamespace()
+		}
+	} else {
+		item.Kind = action.Instance.GetSpecApiVer()
+	}
+	return item
+}
@@ -0,0 +1,68 @@
+package k8sservice
+
+import (
+	"slices"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/config"
+)
+
+func newTestCollection(id string, pods map[string]string) *common.Collection {
+	col := common.NewCollection("apps", nil, &id, &config.CollectionConfig{}, "", make(map[string]common.INode))
+	for name, app := range pods {
+		col.AddResource(newTestPodAction(name, app, common.Create).Instance)
+	}
+	return col
+}
+
+func TestPlanDeploy(t *testing.T) {
+	deployed := &DeployedResources{
+		resIds:    make(map[string]*DeployDetail),
+		persister: &DummyPersister{},
+	}
+	first := newTestCollection("apps", map[string]string{"web": "web", "db": "db", "cache": "cache"})
+	plan, err := deployed.PlanDeploy(first)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if len(plan.Items) != 3 || plan.ResId != "apps" {
+		t.Fatalf("wrong plan %+v", plan)
+	}
+	item := plan.Items[0]
+	if item.Id != "cache" || item.Kind != "Pod" || item.Name != "cache" || item.Namespace != config.DEFAULT_NAMESPACE ||
+		item.ActionName() != "create" || item.Reason != "not deployed yet" {
+		t.Errorf("wrong item %+v", item)
+	}
+	if _, err := deployed.LockAndAdd(first); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	second := newTestCollection("apps", map[string]string{"web": "web", "db": "db2", "queue": "queue"})
+	plan, err = deployed.PlanDeploy(second)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	actions := []string{}
+	for _, item := range plan.Items {
+		actions = append(actions, item.Id+":"+item.ActionName()+":"+item.Reason)
+	}
+	expected := []string{
+		"db:update:changed since the last deploy",
+		"queue:create:added since the last deploy",
+		"cache:delete:removed since the last deploy",
+		"web:none:unchanged since the last deploy",
+	}
+	if !slices.Equal(actions, expected) {
+		t.Errorf("wrong plan %v", actions)
+	}
+
+	plan.Items[0].Skip = true
+	approved := []string{}
+	for _, item := range plan.Approved() {
+		approved = append(approved, item.Id)
+	}
+	if !slices.Equal(approved, []string{"queue", "cache"}) {
+		t.Errorf("wrong approved items %v", approved)
+	}
+}
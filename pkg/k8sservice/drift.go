@@ -0,0 +1,416 @@
+package k8sservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+)
+
+type DriftState string
+
+const (
+	DriftInSync  DriftState = "InSync"
+	DriftDrifted DriftState = "Drifted"
+	DriftMissing DriftState = "Missing"
+	// the live object could not be fetched
+	DriftUnknown DriftState = "Unknown"
+)
+
+// the fields the server sets or owns, they are never compared
+var driftIgnoredFields = []string{
+	"status",
+	"metadata.namespace",
+	"metadata.uid",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.creationTimestamp",
+	"metadata.managedFields",
+}
+
+// DriftField is a field of a deployed cr whose live value is different
+type DriftField struct {
+	Path    string
+	Desired string
+	// empty if the field is not in the live object
+	Live string
+
+	// the keys and indexes to the field
+	path []any
+	// the live value, nil if it is not there
+	liveValue any
+}
+
+// ResourceDrift tells how a deployed resource differs from its live object
+type ResourceDrift struct {
+	Id        string
+	Kind      string
+	Name      string
+	Namespace string
+	State     DriftState
+	Fields    []DriftField
+	Err       string
+	// nil if missing
+	Live *unstructured.Unstructured
+
+	desired *unstructured.Unstructured
+	// the action deploying the cr as deployed
+	action   *common.ResourceInstanceAction
+	targetNs string
+}
+
+// DriftReport is the result of a drift check of a deployment
+type DriftReport struct {
+	Checked   string
+	Resources []*ResourceDrift
+}
+
+// Drifted returns the resources not in sync
+func (r *DriftReport) Drifted() []*ResourceDrift {
+	drifted := make([]*ResourceDrift, 0)
+	for _, res := range r.Resources {
+		if res.State != DriftInSync {
+			drifted = append(drifted, res)
+		}
+	}
+	return drifted
+}
+
+// Summary tells in a few words if the deployment drifted
+func (r *DriftReport) Summary() string {
+	drifted := len(r.Drifted())
+	if drifted == 0 {
+		return "in sync"
+	}
+	return fmt.Sprintf("%d/%d drifted", drifted, len(r.Resources))
+}
+
+func formatPath(path []any) string {
+	var builder strings.Builder
+	for _, p := range path {
+		switch key := p.(type) {
+		case int:
+			builder.WriteString("[" + strconv.Itoa(key) + "]")
+		default:
+			if builder.Len() > 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString(fmt.Sprint(key))
+		}
+	}
+	return builder.String()
+}
+
+func formatValue(value any) string {
+	switch value.(type) {
+	case map[string]any, []any:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// sameScalar compares the values the way the server normalizes them,
+// e.g. 1 and 1.0 or the quantities 1Gi and 1024Mi are the same
+func sameScalar(desired any, live any) bool {
+	if fmt.Sprint(desired) == fmt.Sprint(live) {
+		return true
+	}
+	toFloat := func(v any) (float64, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case int:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+		return 0, false
+	}
+	if d, ok := toFloat(desired); ok {
+		if l, ok := toFloat(live); ok {
+			return d == l
+		}
+	}
+	if d, ok := desired.(string); ok {
+		if l, ok := live.(string); ok {
+			dq, err1 := resource.ParseQuantity(d)
+			lq, err2 := resource.ParseQuantity(l)
+			return err1 == nil && err2 == nil && dq.Cmp(lq) == 0
+		}
+	}
+	return false
+}
+
+func compareFields(path []any, desired any, live any, found bool, fields *[]DriftField) {
+	if slices.Contains(driftIgnoredFields, formatPath(path)) {
+		return
+	}
+	add := func() {
+		field := DriftField{
+			Path:    formatPath(path),
+			Desired: formatValue(desired),
+			path:    slices.Clone(path),
+		}
+		if found {
+			field.Live = formatValue(live)
+			field.liveValue = live
+		}
+		*fields = append(*fields, field)
+	}
+	if !found {
+		// the server drops empty fields
+		if !isEmptyValue(desired) {
+			add()
+		}
+		return
+	}
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			add()
+			return
+		}
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			value, ok := l[key]
+			compareFields(append(path, key), d[key], value, ok, fields)
+		}
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			add()
+			return
+		}
+		for i := range d {
+			compareFields(append(path, i), d[i], l[i], true, fields)
+		}
+	default:
+		if !sameScalar(desired, live) {
+			add()
+		}
+	}
+}
+
+// DriftFields compares the fields of the desired object with the live
+// one. The fields only in the live object are left out, they are
+// defaulted by the server or added by controllers.
+func DriftFields(desired *unstructured.Unstructured, live *unstructured.Unstructured) []DriftField {
+	fields := make([]DriftField, 0)
+	compareFields([]any{}, desired.Object, live.Object, true, &fields)
+	return fields
+}
+
+// CheckDrift compares the resources deployed by detail with their live
+// objects
+func CheckDrift(client K8sService, detail *DeployDetail) *DriftReport {
+	report := &DriftReport{
+		Checked: time.Now().Format(time.RFC3339),
+	}
+	ids := make([]string, 0, len(detail.AllInstances))
+	for id, inst := range detail.AllInstances {
+		if inst.GetAction() != common.Delete && detail.OriginalCrs[id] != nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		cr := detail.OriginalCrs[id]
+		action := copyInstanceAction(detail.AllInstances[id])
+		action.Instance.Cr = cr.Cr
+		action.Inventory = detail.Id
+		drift := &ResourceDrift{
+			Id:        id,
+			Name:      action.GetName(),
+			Namespace: cr.FinalNs,
+			action:    action,
+			targetNs:  cr.FinalNs,
+		}
+		report.Resources = append(report.Resources, drift)
+
+		desired := &unstructured.Unstructured{}
+		dec := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+		_, gvk, err := dec.Decode([]byte(cr.Cr), nil, desired)
+		if err != nil {
+			drift.State = DriftUnknown
+			drift.Err = err.Error()
+			continue
+		}
+		drift.Kind = gvk.Kind
+		drift.Name = desired.GetName()
+		drift.desired = desired
+
+		live, err := client.GetLiveResource(action, cr.FinalNs)
+		switch {
+		case err != nil:
+			drift.State = DriftUnknown
+			drift.Err = err.Error()
+		case live == nil:
+			drift.State = DriftMissing
+		default:
+			drift.Live = live
+			drift.Fields = DriftFields(desired, live)
+			drift.State = DriftInSync
+			if len(drift.Fields) > 0 {
+				drift.State = DriftDrifted
+			}
+		}
+	}
+	return report
+}
+
+// Reapply deploys the cr as deployed again, taking over the fields
+// changed by others
+func (r *ResourceDrift) Reapply(client K8sService) error {
+	action := copyInstanceAction(r.action)
+	action.Force = true
+	if r.State != DriftMissing {
+		action.SetAction(common.Update)
+	}
+	_, _, err := client.DeployResource(action, r.targetNs)
+	return err
+}
+
+// CanAdopt tells whether the live state can be taken as the cr
+func (r *ResourceDrift) CanAdopt() bool {
+	return r.State == DriftDrifted && r.desired != nil
+}
+
+// Adopted returns the deployed cr with the drifted fields set to their
+// live values
+func (r *ResourceDrift) Adopted() (string, error) {
+	if !r.CanAdopt() {
+		return "", fmt.Errorf("%s has no live state to adopt", r.Name)
+	}
+	adopted := r.desired.DeepCopy()
+	for _, field := range r.Fields {
+		setField(adopted.Object, field.path, field.liveValue)
+	}
+	return common.MarshalYaml(adopted)
+}
+
+// setField sets the value at path in obj, a nil value removes it
+func setField(obj any, path []any, value any) {
+	if len(path) == 0 {
+		return
+	}
+	last := len(path) == 1
+	switch key := path[0].(type) {
+	case string:
+		m, ok := obj.(map[string]any)
+		if !ok {
+			return
+		}
+		if last {
+			if value == nil {
+				delete(m, key)
+			} else {
+				m[key] = value
+			}
+			return
+		}
+		setField(m[key], path[1:], value)
+	case int:
+		list, ok := obj.([]any)
+		if !ok || key >= len(list) {
+			return
+		}
+		if last {
+			list[key] = value
+			return
+		}
+		setField(list[key], path[1:], value)
+	}
+}
+
+// Adopt records cr, the adopted live state of the instance id, as what the
+// deployment resId deployed, in a new revision
+func (d *DeployedResources) Adopt(resId string, id string, cr string) error {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return fmt.Errorf("%s is not deployed", resId)
+	}
+	revision := &DeployRevision{
+		Time:       time.Now().Format(time.RFC3339),
+		Instances:  make(map[string]*common.ResourceInstanceAction, len(dd.AllInstances)),
+		Namespaces: make(map[string]string, len(dd.AllInstances)),
+		Outcome:    RevisionAdopted,
+	}
+	for instId, inst := range dd.AllInstances {
+		if inst.GetAction() == common.Delete {
+			continue
+		}
+		revision.Instances[instId] = copyInstanceAction(inst)
+		if old, ok := dd.OriginalCrs[instId]; ok {
+			revision.Namespaces[instId] = old.FinalNs
+		}
+	}
+	adopted, ok := revision.Instances[id]
+	if !ok {
+		return fmt.Errorf("%s is not deployed by %s", id, dd.Name)
+	}
+	adopted.Instance.Cr = cr
+	revision.Message = "adopted the live state of " + adopted.GetName()
+	dd.addRevision(revision)
+	return d.persister.Update()
+}
+
+// driftReports keeps the last drift check of each deployment, they are
+// not persisted
+type driftReports struct {
+	lock    sync.RWMutex
+	reports map[string]*DriftReport
+}
+
+func (r *driftReports) clear() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reports = nil
+}
+
+// SetDrift records the drift check of the deployment resId
+func (d *DeployedResources) SetDrift(resId string, report *DriftReport) {
+	d.drift.lock.Lock()
+	defer d.drift.lock.Unlock()
+	if d.drift.reports == nil {
+		d.drift.reports = make(map[string]*DriftReport)
+	}
+	d.drift.reports[resId] = report
+}
+
+// GetDrift returns the last drift check of the deployment resId, nil if
+// it wasn't checked
+func (d *DeployedResources) GetDrift(resId string) *DriftReport {
+	d.drift.lock.RLock()
+	defer d.drift.lock.RUnlock()
+	return d.drift.reports[resId]
+}
@@ -0,0 +1,197 @@
+package k8sservice
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func driftStates(report *DriftReport) []string {
+	states := []string{}
+	for _, res := range report.Resources {
+		states = append(states, res.Id+":"+string(res.State))
+	}
+	return states
+}
+
+func TestDriftFields(t *testing.T) {
+	desired := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":        "web",
+			"labels":      map[string]any{"app": "web"},
+			"annotations": map[string]any{"note": "keep"},
+		},
+		"spec": map[string]any{
+			"containers": []any{map[string]any{
+				"name":      "web",
+				"resources": map[string]any{"limits": map[string]any{"memory": "1Gi", "cpu": int64(1)}},
+			}},
+			"volumes": []any{},
+		},
+	}}
+	live := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":              "web",
+			"namespace":         "default",
+			"uid":               "1234",
+			"resourceVersion":   "7",
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+			"labels":            map[string]any{"app": "web2"},
+		},
+		"spec": map[string]any{
+			"containers": []any{map[string]any{
+				"name":                     "web",
+				"resources":                map[string]any{"limits": map[string]any{"memory": "1024Mi", "cpu": "1"}},
+				"terminationMessagePath":   "/dev/termination-log",
+				"terminationMessagePolicy": "File",
+			}},
+			"restartPolicy": "Always",
+		},
+		"status": map[string]any{"phase": "Running"},
+	}}
+
+	fields := DriftFields(desired, live)
+	paths := []string{}
+	for _, field := range fields {
+		paths = append(paths, field.Path+"="+field.Desired+">"+field.Live)
+	}
+	if !slices.Equal(paths, []string{"metadata.annotations=" + `{"note":"keep"}` + ">", "metadata.labels.app=web>web2"}) {
+		t.Errorf("wrong drift %v", paths)
+	}
+	if fields := DriftFields(desired, desired.DeepCopy()); len(fields) != 0 {
+		t.Errorf("expected no drift of the same object %v", fields)
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	for _, remote := range []bool{false, true} {
+		client, dynClient := newTestDeployClient(newTestVersionedPod("web", 1, "web2"), newTestVersionedPod("db", 1, "db"))
+		applyPatches(dynClient, "")
+		var service K8sService = &LocalK8sService{localClient: client}
+		if remote {
+			service = startTestAgent(t, &server{
+				client:      client,
+				userClients: make(map[string]*K8sClient),
+			})
+		}
+
+		deployed := &DeployedResources{
+			resIds:    make(map[string]*DeployDetail),
+			persister: &DummyPersister{},
+		}
+		col := newTestCollection("apps", map[string]string{"web": "web", "db": "db", "queue": "queue"})
+		if _, err := deployed.LockAndAdd(col); err != nil {
+			t.Fatalf("failed to add: %v", err)
+		}
+		finalNs := make(map[string]types.NamespacedName)
+		for _, name := range []string{"web", "db", "queue"} {
+			finalNs[name] = types.NamespacedName{Name: name, Namespace: "default"}
+		}
+		deployed.Deployed("apps", finalNs)
+		detail := deployed.resIds["apps"]
+
+		report := CheckDrift(service, detail)
+		if states := driftStates(report); !slices.Equal(states, []string{"db:InSync", "queue:Missing", "web:Drifted"}) {
+			t.Fatalf("remote %v: wrong drift %v", remote, states)
+		}
+		if report.Summary() != "2/3 drifted" {
+			t.Errorf("remote %v: wrong summary %s", remote, report.Summary())
+		}
+		deployed.SetDrift("apps", report)
+		if deployed.GetDrift("apps") != report {
+			t.Errorf("remote %v: drift not recorded", remote)
+		}
+
+		web := report.Resources[2]
+		if len(web.Fields) != 1 || web.Fields[0].Path != "metadata.labels.app" || web.Fields[0].Live != "web2" {
+			t.Errorf("remote %v: wrong fields %v", remote, web.Fields)
+		}
+		for _, res := range report.Drifted() {
+			if err := res.Reapply(service); err != nil {
+				t.Fatalf("remote %v: failed to re-apply %s: %v", remote, res.Name, err)
+			}
+		}
+		if podApp(t, dynClient, "web") != "web" || podApp(t, dynClient, "queue") != "queue" {
+			t.Errorf("remote %v: not re-applied", remote)
+		}
+		if report := CheckDrift(service, detail); report.Summary() != "in sync" {
+			t.Errorf("remote %v: still drifted %v", remote, driftStates(report))
+		}
+	}
+
+	// the agent applies with force as asked
+	forced := newTestPodAction("web", "web", common.Update)
+	forced.Force = true
+	if !NewResourceInstanceAction(deployRequestFor(forced, "default")).Force {
+		t.Errorf("force lost on the way to the agent")
+	}
+}
+
+// failingPersister fails to write the deployments
+type failingPersister struct {
+	DummyPersister
+	err error
+}
+
+func (p *failingPersister) Update() error {
+	return p.err
+}
+
+func TestAdoptDrift(t *testing.T) {
+	client, _ := newTestDeployClient(newTestVersionedPod("web", 1, "web2"))
+	service := &LocalK8sService{localClient: client}
+	deployed := &DeployedResources{
+		resIds:    make(map[string]*DeployDetail),
+		persister: &DummyPersister{},
+	}
+	if _, err := deployed.LockAndAdd(newTestCollection("apps", map[string]string{"web": "web"})); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	deployed.Deployed("apps", map[string]types.NamespacedName{"web": {Name: "web", Namespace: "default"}})
+	detail := deployed.resIds["apps"]
+
+	web := CheckDrift(service, detail).Resources[0]
+	if !web.CanAdopt() {
+		t.Fatalf("expected web to be adoptable, it is %s", web.State)
+	}
+	cr, err := web.Adopted()
+	if err != nil {
+		t.Fatalf("failed to adopt: %v", err)
+	}
+	if !strings.Contains(cr, "app: web2") || strings.Contains(cr, "resourceVersion") {
+		t.Errorf("wrong adopted cr %s", cr)
+	}
+
+	if err := deployed.Adopt("apps", "web", cr); err != nil {
+		t.Fatalf("failed to record the adopted cr: %v", err)
+	}
+	if detail.OriginalCrs["web"].FinalNs != "default" || detail.AllInstances["web"].Instance.GetCR() != cr {
+		t.Errorf("adopted cr not recorded %v", detail.OriginalCrs["web"])
+	}
+	if len(detail.Revisions) != 1 || detail.Revisions[0].Outcome != RevisionAdopted ||
+		detail.Revisions[0].Instances["web"].Instance.GetCR() != cr || detail.Revisions[0].Namespaces["web"] != "default" {
+		t.Errorf("adoption not recorded as a revision %v", detail.Revisions)
+	}
+	if report := CheckDrift(service, detail); report.Summary() != "in sync" {
+		t.Errorf("still drifted after adopting %v", driftStates(report))
+	}
+
+	deployed.persister = &failingPersister{err: ErrDeploymentConflict}
+	if err := deployed.Adopt("apps", "web", cr); !errors.Is(err, ErrDeploymentConflict) {
+		t.Errorf("expected the failure to persist, got %v", err)
+	}
+
+	missing := &ResourceDrift{Name: "queue", State: DriftMissing}
+	if _, err := missing.Adopted(); err == nil || missing.CanAdopt() {
+		t.Errorf("expected a missing resource not to be adoptable")
+	}
+}
@@ -0,0 +1,189 @@
+package k8sservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// grpcCodeFor maps the reason of a k8s api error to the closest grpc code
+func grpcCodeFor(apiStatus *v1.Status) codes.Code {
+	switch apiStatus.Reason {
+	case v1.StatusReasonNotFound:
+		return codes.NotFound
+	case v1.StatusReasonAlreadyExists:
+		return codes.AlreadyExists
+	case v1.StatusReasonConflict:
+		return codes.Aborted
+	case v1.StatusReasonForbidden:
+		return codes.PermissionDenied
+	case v1.StatusReasonUnauthorized:
+		return codes.Unauthenticated
+	case v1.StatusReasonInvalid, v1.StatusReasonBadRequest:
+		return codes.InvalidArgument
+	case v1.StatusReasonTimeout, v1.StatusReasonServerTimeout:
+		return codes.DeadlineExceeded
+	case v1.StatusReasonTooManyRequests, v1.StatusReasonRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case v1.StatusReasonServiceUnavailable:
+		return codes.Unavailable
+	case v1.StatusReasonMethodNotAllowed, v1.StatusReasonNotAcceptable, v1.StatusReasonUnsupportedMediaType:
+		return codes.Unimplemented
+	case v1.StatusReasonGone, v1.StatusReasonExpired:
+		return codes.FailedPrecondition
+	case v1.StatusReasonInternalError:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// toGrpcError turns the error of a k8s call into the status an rpc fails with.
+// The metav1.Status of an api error goes along as an ApiStatus detail.
+func toGrpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var apiErr apierrors.APIStatus
+	if !errors.As(err, &apiErr) {
+		code := codes.Unknown
+		if errors.Is(err, context.Canceled) {
+			code = codes.Canceled
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			code = codes.DeadlineExceeded
+		}
+		return status.Error(code, err.Error())
+	}
+
+	apiStatus := apiErr.Status()
+	// the context the error was wrapped in is kept
+	apiStatus.Message = err.Error()
+	st := status.New(grpcCodeFor(&apiStatus), apiStatus.Message)
+	detail := newApiStatus(&apiStatus)
+	if detail == nil {
+		return st.Err()
+	}
+	if detailed, detailErr := st.WithDetails(detail); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func newApiStatus(apiStatus *v1.Status) *ApiStatus {
+	statusJson, err := json.Marshal(apiStatus)
+	if err != nil {
+		logger.Warn("failed to marshal api status", zap.Error(err))
+		return nil
+	}
+	return &ApiStatus{StatusJson: string(statusJson)}
+}
+
+// apiStatusOf carries err in a reply, with the metav1.Status of an api
+// error. Other errors only have their message.
+func apiStatusOf(err error) *ApiStatus {
+	if err == nil {
+		return nil
+	}
+	var apiErr apierrors.APIStatus
+	if !errors.As(err, &apiErr) {
+		return newApiStatus(&v1.Status{Status: v1.StatusFailure, Message: err.Error()})
+	}
+	apiStatus := apiErr.Status()
+	apiStatus.Message = err.Error()
+	return newApiStatus(&apiStatus)
+}
+
+// toError rebuilds the k8s api error of the status, nil if there is none
+func (s *ApiStatus) toError() error {
+	if s == nil {
+		return nil
+	}
+	statusErr := &apierrors.StatusError{}
+	if err := json.Unmarshal([]byte(s.StatusJson), &statusErr.ErrStatus); err != nil {
+		logger.Warn("invalid api status", zap.Error(err))
+		return nil
+	}
+	if statusErr.ErrStatus.Reason == "" && statusErr.ErrStatus.Code == 0 {
+		// not an api error
+		return errors.New(statusErr.ErrStatus.Message)
+	}
+	return statusErr
+}
+
+// ErrorCauses lists the causes of a k8s api error, like the field errors
+// of an invalid object
+func ErrorCauses(err error) []string {
+	var apiErr apierrors.APIStatus
+	if !errors.As(err, &apiErr) || apiErr.Status().Details == nil {
+		return nil
+	}
+	causes := make([]string, 0)
+	for _, cause := range apiErr.Status().Details.Causes {
+		if cause.Field != "" {
+			causes = append(causes, cause.Field+": "+cause.Message)
+		} else {
+			causes = append(causes, cause.Message)
+		}
+	}
+	return causes
+}
+
+// fromGrpcError rebuilds the k8s api error an rpc failed with,
+// so apierrors.IsNotFound() and the like work as with a local client.
+func fromGrpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+	for _, detail := range st.Details() {
+		if apiStatus, ok := detail.(*ApiStatus); ok {
+			if apiErr := apiStatus.toError(); apiErr != nil {
+				return apiErr
+			}
+		}
+	}
+	if st.Code() == codes.Unknown {
+		// an error of the agent, not of the rpc
+		return errors.New(st.Message())
+	}
+	return fmt.Errorf("failed rpc call %w", err)
+}
+
+// streamStarted waits until the agent accepted a server stream,
+// it returns the error the rpc failed with otherwise.
+func streamStarted[T any](stream grpc.ServerStreamingClient[T]) error {
+	header, err := stream.Header()
+	if err == nil && header == nil {
+		// ended before it started, the status comes with the end
+		if _, err = stream.Recv(); err == io.EOF {
+			err = fmt.Errorf("the stream ended before it started")
+		}
+	}
+	if err != nil {
+		return fromGrpcError(err)
+	}
+	return nil
+}
+
+// sendFailed returns why a send on a client stream failed. A send fails
+// with io.EOF once the rpc ended, its status comes with the next receive.
+func sendFailed[Req any, Res any](stream grpc.BidiStreamingClient[Req, Res], err error) error {
+	if err == io.EOF {
+		if _, recvErr := stream.Recv(); recvErr != nil && recvErr != io.EOF {
+			err = recvErr
+		}
+	}
+	return fromGrpcError(err)
+}
@@ -0,0 +1,71 @@
+package k8sservice
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGrpcErrorRoundTrip(t *testing.T) {
+	invalid := apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, "pod1", field.ErrorList{
+		field.Required(field.NewPath("spec", "containers"), "at least one container"),
+	})
+	wrapped := fmt.Errorf("failed to deploy: %w", invalid)
+
+	grpcErr := toGrpcError(wrapped)
+	if code := status.Code(grpcErr); code != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", code)
+	}
+	err := fromGrpcError(grpcErr)
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("expected an invalid error, got %v", err)
+	}
+	if err.Error() != wrapped.Error() {
+		t.Errorf("expected message %q, got %q", wrapped.Error(), err.Error())
+	}
+	causes := err.(apierrors.APIStatus).Status().Details.Causes
+	if len(causes) != 1 || causes[0].Field != "spec.containers" || causes[0].Type != metav1.CauseTypeFieldValueRequired {
+		t.Errorf("wrong causes %v", causes)
+	}
+
+	if err := fromGrpcError(toGrpcError(fmt.Errorf("plain failure"))); err.Error() != "plain failure" || apierrors.ReasonForError(err) != metav1.StatusReasonUnknown {
+		t.Errorf("wrong plain error %v", err)
+	}
+}
+
+func TestRemoteApiErrors(t *testing.T) {
+	remote, dynClient := newTestAgent(t)
+	dynClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(podsGvr.GroupResource(), "", fmt.Errorf("no access"))
+	})
+	dynClient.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, apierrors.NewForbidden(podsGvr.GroupResource(), "", fmt.Errorf("no access"))
+	})
+	local := &K8sClient{dynClient: dynClient}
+
+	_, localErr := local.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{})
+	_, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{})
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("expected forbidden, got %v", err)
+	}
+	if localErr == nil || err.Error() != localErr.Error() {
+		t.Errorf("expected %v as with a local client, got %v", localErr, err)
+	}
+
+	if _, err := remote.WatchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{}); !apierrors.IsForbidden(err) {
+		t.Errorf("expected forbidden watch, got %v", err)
+	}
+
+	if _, _, err := remote.ListContexts(); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected unimplemented, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package k8sservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// execOutput sends what the command writes to stdout or stderr
+type execOutput struct {
+	lock   *sync.Mutex
+	stream grpc.BidiStreamingServer[ExecRequest, ExecResponse]
+	stderr bool
+}
+
+func (o *execOutput) Write(p []byte) (int, error) {
+	resp := &ExecResponse{}
+	if o.stderr {
+		resp.Stderr = p
+	} else {
+		resp.Stdout = p
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if err := o.stream.Send(resp); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *server) ExecPod(stream grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error {
+	lock := &sync.Mutex{}
+	// a failed command fails the rpc, with the api status if the exec was
+	// rejected by the cluster
+	exited := func(err error) error {
+		if err != nil {
+			return toGrpcError(err)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		return stream.Send(&ExecResponse{Exited: true})
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "exec session not started")
+	}
+	podRaw := &unstructured.Unstructured{}
+	if err := json.Unmarshal([]byte(start.PodRawJson), podRaw); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	resize := make(chan remotecommand.TerminalSize, 1)
+	opts := ExecOptions{
+		Container: start.Container,
+		Command:   start.Command,
+		Tty:       start.Tty,
+		Stdout:    &execOutput{lock: lock, stream: stream},
+		Stderr:    &execOutput{lock: lock, stream: stream, stderr: true},
+		Resize:    resize,
+	}
+	var stdinReader *io.PipeReader
+	var stdinWriter *io.PipeWriter
+	if start.Stdin {
+		stdinReader, stdinWriter = io.Pipe()
+		opts.Stdin = stdinReader
+		// unblocks the writes of stdin the command no longer reads
+		defer stdinReader.Close()
+	}
+
+	go func() {
+		defer close(resize)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if stdinWriter != nil {
+					stdinWriter.CloseWithError(err)
+				}
+				if err != io.EOF {
+					cancel()
+				}
+				return
+			}
+			switch r := req.Request.(type) {
+			case *ExecRequest_Stdin:
+				if stdinWriter != nil {
+					stdinWriter.Write(r.Stdin)
+				}
+			case *ExecRequest_CloseStdin:
+				if stdinWriter != nil {
+					stdinWriter.Close()
+				}
+			case *ExecRequest_Resize:
+				// only the latest size matters, don't hold up stdin for a stale one
+				select {
+				case <-resize:
+				default:
+				}
+				resize <- remotecommand.TerminalSize{Width: uint16(r.Resize.Width), Height: uint16(r.Resize.Height)}
+			}
+		}
+	}()
+
+	return exited(s.clientFor(ctx).ExecPod(ctx, podRaw, opts))
+}
+
+// ExecPod implements K8sService.
+func (r *RemoteK8sService) ExecPod(ctx context.Context, podRaw *unstructured.Unstructured, opts ExecOptions) error {
+	if r.Conn == nil {
+		return fmt.Errorf("no remote connection")
+	}
+
+	podBytes, err := json.Marshal(podRaw)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+	stream, err := grpcClient.ExecPod(ctx)
+	if err != nil {
+		return fromGrpcError(err)
+	}
+
+	var lock sync.Mutex
+	send := func(req *ExecRequest) error {
+		lock.Lock()
+		defer lock.Unlock()
+		return stream.Send(req)
+	}
+
+	err = send(&ExecRequest{
+		Request: &ExecRequest_Start{
+			Start: &ExecStart{
+				PodRawJson: string(podBytes),
+				Container:  opts.Container,
+				Command:    opts.Command,
+				Tty:        opts.Tty,
+				Stdin:      opts.Stdin != nil,
+			},
+		},
+	})
+	if err != nil {
+		return sendFailed(stream, err)
+	}
+
+	if opts.Stdin != nil {
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				n, err := opts.Stdin.Read(buf)
+				if n > 0 {
+					if send(&ExecRequest{Request: &ExecRequest_Stdin{Stdin: bytes.Clone(buf[:n])}}) != nil {
+						return
+					}
+				}
+				if err != nil {
+					send(&ExecRequest{Request: &ExecRequest_CloseStdin{CloseStdin: true}})
+					return
+				}
+			}
+		}()
+	}
+
+	if opts.Tty && opts.Resize != nil {
+		go func() {
+			for {
+				select {
+				case size, ok := <-opts.Resize:
+					if !ok {
+						return
+					}
+					err := send(&ExecRequest{Request: &ExecRequest_Resize{Resize: &TerminalSize{
+						Width:  uint32(size.Width),
+						Height: uint32(size.Height),
+					}}})
+					if err != nil {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("exec ended without the command exiting")
+		}
+		if err != nil {
+			return fromGrpcError(err)
+		}
+		if len(resp.Stdout) > 0 && opts.Stdout != nil {
+			opts.Stdout.Write(resp.Stdout)
+		}
+		if len(resp.Stderr) > 0 && opts.Stderr != nil {
+			opts.Stderr.Write(resp.Stderr)
+		}
+		if resp.Exited {
+			return nil
+		}
+	}
+}
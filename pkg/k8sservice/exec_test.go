@@ -0,0 +1,158 @@
+package k8sservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// echoExecutor echoes the stdin lines to stdout and stderr, a "size" line
+// prints the next terminal size and a "fail" line fails the command, a
+// "forbidden" line fails it as rejected by the cluster.
+type echoExecutor struct {
+}
+
+func (e *echoExecutor) Stream(options remotecommand.StreamOptions) error {
+	return e.StreamWithContext(context.Background(), options)
+}
+
+func (e *echoExecutor) StreamWithContext(ctx context.Context, options remotecommand.StreamOptions) error {
+	scanner := bufio.NewScanner(options.Stdin)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch line {
+		case "fail":
+			return fmt.Errorf("command terminated with exit code 1")
+		case "forbidden":
+			return apierrors.NewForbidden(schema.GroupResource{Resource: "pods/exec"}, "pod1", fmt.Errorf("no exec"))
+		case "size":
+			size := options.TerminalSizeQueue.Next()
+			fmt.Fprintf(options.Stdout, "%dx%d\n", size.Width, size.Height)
+		default:
+			fmt.Fprintf(options.Stdout, "out:%s\n", line)
+			if options.Stderr != nil {
+				fmt.Fprintf(options.Stderr, "err:%s\n", line)
+			}
+		}
+	}
+	return nil
+}
+
+// syncBuffer is written by the exec session while the test reads it
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestRemoteExec(t *testing.T) {
+	saved := newExecutor
+	newExecutor = func(config *rest.Config, execUrl *url.URL) (remotecommand.Executor, error) {
+		if !strings.HasSuffix(execUrl.Path, "/namespaces/default/pods/pod1/exec") {
+			return nil, fmt.Errorf("unexpected exec url %v", execUrl)
+		}
+		return &echoExecutor{}, nil
+	}
+	t.Cleanup(func() {
+		newExecutor = saved
+	})
+
+	remote := startTestAgent(t, &server{
+		client:      &K8sClient{config: &rest.Config{Host: "http://localhost:1"}},
+		userClients: make(map[string]*K8sClient),
+	})
+	pod := newTestPod("default", "pod1")
+
+	t.Run("stdout and stderr", func(t *testing.T) {
+		var stdout, stderr syncBuffer
+		err := remote.ExecPod(context.Background(), pod, ExecOptions{
+			Container: "main",
+			Command:   []string{"/bin/sh"},
+			Stdin:     strings.NewReader("hello\nworld\n"),
+			Stdout:    &stdout,
+			Stderr:    &stderr,
+		})
+		if err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		if stdout.String() != "out:hello\nout:world\n" {
+			t.Errorf("wrong stdout %q", stdout.String())
+		}
+		if stderr.String() != "err:hello\nerr:world\n" {
+			t.Errorf("wrong stderr %q", stderr.String())
+		}
+	})
+
+	t.Run("tty resize", func(t *testing.T) {
+		stdinReader, stdinWriter := io.Pipe()
+		resize := make(chan remotecommand.TerminalSize, 1)
+		var stdout syncBuffer
+		done := make(chan error)
+		go func() {
+			done <- remote.ExecPod(context.Background(), pod, ExecOptions{
+				Command: []string{"/bin/sh"},
+				Tty:     true,
+				Stdin:   stdinReader,
+				Stdout:  &stdout,
+				Resize:  resize,
+			})
+		}()
+		resize <- remotecommand.TerminalSize{Width: 120, Height: 40}
+		io.WriteString(stdinWriter, "size\n")
+		stdinWriter.Close()
+		if err := <-done; err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		if stdout.String() != "120x40\n" {
+			t.Errorf("wrong stdout %q", stdout.String())
+		}
+	})
+
+	t.Run("command failed", func(t *testing.T) {
+		err := remote.ExecPod(context.Background(), pod, ExecOptions{
+			Command: []string{"/bin/sh"},
+			Stdin:   strings.NewReader("fail\n"),
+			Stdout:  io.Discard,
+		})
+		if err == nil || !strings.Contains(err.Error(), "exit code 1") {
+			t.Errorf("expected the command error, got %v", err)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		var stdout syncBuffer
+		err := remote.ExecPod(context.Background(), pod, ExecOptions{
+			Command: []string{"/bin/sh"},
+			Stdin:   strings.NewReader("hello\nforbidden\n"),
+			Stdout:  &stdout,
+		})
+		if !apierrors.IsForbidden(err) {
+			t.Errorf("expected a forbidden error, got %v", err)
+		}
+		if stdout.String() != "out:hello\n" {
+			t.Errorf("output before the failure lost %q", stdout.String())
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package k8sservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type HealthState string
+
+const (
+	HealthReady       HealthState = "Ready"
+	HealthProgressing HealthState = "Progressing"
+	HealthFailed      HealthState = "Failed"
+	// still progressing when the wait timed out
+	HealthTimeout HealthState = "Timeout"
+)
+
+// how often a resource is checked while waiting for it to be ready
+var readyPollInterval = time.Second
+
+// ResourceHealth is how a deployed resource is doing
+type ResourceHealth struct {
+	State   HealthState `yaml:"state"`
+	Message string      `yaml:"message,omitempty"`
+	// when it was checked, RFC3339
+	Checked string `yaml:"checked,omitempty"`
+}
+
+// IsFinal tells whether waiting longer can't change the state
+func (h ResourceHealth) IsFinal() bool {
+	return h.State == HealthReady || h.State == HealthFailed
+}
+
+func (h ResourceHealth) String() string {
+	if h.Message == "" {
+		return string(h.State)
+	}
+	return string(h.State) + ": " + h.Message
+}
+
+func ready(message string) ResourceHealth {
+	return ResourceHealth{State: HealthReady, Message: message}
+}
+
+func progressing(format string, args ...any) ResourceHealth {
+	return ResourceHealth{State: HealthProgressing, Message: fmt.Sprintf(format, args...)}
+}
+
+func failed(format string, args ...any) ResourceHealth {
+	return ResourceHealth{State: HealthFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+// findCondition returns the status, reason and message of the condition
+// of type condType, an empty status if there is no such condition
+func findCondition(obj *unstructured.Unstructured, condType string) (string, string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["type"] != condType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		message, _ := condition["message"].(string)
+		return status, reason, message
+	}
+	return "", "", ""
+}
+
+func nestedInt(obj *unstructured.Unstructured, fields ...string) int64 {
+	value, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+	return value
+}
+
+// specReplicas is the spec.replicas, 1 if not set like the server defaults it
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+		return replicas
+	}
+	return 1
+}
+
+// the controller didn't see the latest spec yet
+func generationPending(obj *unstructured.Unstructured) bool {
+	return nestedInt(obj, "status", "observedGeneration") < obj.GetGeneration()
+}
+
+func deploymentHealth(obj *unstructured.Unstructured) ResourceHealth {
+	if generationPending(obj) {
+		return progressing("waiting for the rollout to start")
+	}
+	if status, reason, message := findCondition(obj, "Progressing"); status == "False" && reason == "ProgressDeadlineExceeded" {
+		return failed("%s", message)
+	}
+	replicas := specReplicas(obj)
+	updated := nestedInt(obj, "status", "updatedReplicas")
+	total := nestedInt(obj, "status", "replicas")
+	available := nestedInt(obj, "status", "availableReplicas")
+	switch {
+	case updated < replicas:
+		return progressing("%d of %d replicas updated", updated, replicas)
+	case total > updated:
+		return progressing("%d old replicas pending termination", total-updated)
+	case available < updated:
+		return progressing("%d of %d updated replicas available", available, updated)
+	}
+	return ready("rollout complete")
+}
+
+func statefulSetHealth(obj *unstructured.Unstructured) ResourceHealth {
+	if generationPending(obj) {
+		return progressing("waiting for the rollout to start")
+	}
+	replicas := specReplicas(obj)
+	readyReplicas := nestedInt(obj, "status", "readyReplicas")
+	if readyReplicas < replicas {
+		return progressing("%d of %d replicas ready", readyReplicas, replicas)
+	}
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return ready("replicas ready")
+	}
+	if updated := nestedInt(obj, "status", "updatedReplicas"); updated < replicas {
+		return progressing("%d of %d replicas updated", updated, replicas)
+	}
+	current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	if current != update {
+		return progressing("waiting for the revision %s", update)
+	}
+	return ready("rollout complete")
+}
+
+func daemonSetHealth(obj *unstructured.Unstructured) ResourceHealth {
+	if generationPending(obj) {
+		return progressing("waiting for the rollout to start")
+	}
+	desired := nestedInt(obj, "status", "desiredNumberScheduled")
+	if updated := nestedInt(obj, "status", "updatedNumberScheduled"); updated < desired {
+		return progressing("%d of %d pods updated", updated, desired)
+	}
+	if available := nestedInt(obj, "status", "numberAvailable"); available < desired {
+		return progressing("%d of %d pods available", available, desired)
+	}
+	return ready("rollout complete")
+}
+
+func jobHealth(obj *unstructured.Unstructured) ResourceHealth {
+	if status, _, _ := findCondition(obj, "Complete"); status == "True" {
+		return ready("succeeded")
+	}
+	if status, reason, message := findCondition(obj, "Failed"); status == "True" {
+		return failed("%s %s", reason, message)
+	}
+	return progressing("%d active, %d succeeded", nestedInt(obj, "status", "active"), nestedInt(obj, "status", "succeeded"))
+}
+
+func pvcHealth(obj *unstructured.Unstructured) ResourceHealth {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Bound":
+		return ready("bound")
+	case "Lost":
+		return failed("the volume is lost")
+	}
+	return progressing("%s", phase)
+}
+
+func podHealth(obj *unstructured.Unstructured) ResourceHealth {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return ready("succeeded")
+	case "Failed":
+		reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+		return failed("%s", reason)
+	}
+	if status, _, _ := findCondition(obj, "Ready"); status == "True" {
+		return ready("ready")
+	}
+	statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		if reason, found, _ := unstructured.NestedString(s.(map[string]any), "state", "waiting", "reason"); found {
+			name, _ := s.(map[string]any)["name"].(string)
+			return progressing("container %s %s", name, reason)
+		}
+	}
+	return progressing("%s", phase)
+}
+
+// genericHealth goes by the Ready or Available condition, if any
+func genericHealth(obj *unstructured.Unstructured) ResourceHealth {
+	for _, condType := range []string{"Ready", "Available"} {
+		status, reason, message := findCondition(obj, condType)
+		switch status {
+		case "True":
+			return ready(condType)
+		case "":
+			continue
+		}
+		return progressing("not %s: %s %s", condType, reason, message)
+	}
+	return ready("")
+}
+
+// AssessHealth tells how obj is doing by its kind
+func AssessHealth(obj *unstructured.Unstructured) ResourceHealth {
+	switch obj.GetKind() {
+	case "Deployment":
+		return deploymentHealth(obj)
+	case "StatefulSet":
+		return statefulSetHealth(obj)
+	case "DaemonSet":
+		return daemonSetHealth(obj)
+	case "Job":
+		return jobHealth(obj)
+	case "PersistentVolumeClaim":
+		return pvcHealth(obj)
+	case "Pod":
+		return podHealth(obj)
+	}
+	return genericHealth(obj)
+}
+
+// WaitForReady waits until the deployed res is ready or failed, its health
+// is passed to progress as it changes. The health is Timeout if it is
+// still progressing after timeout. A failed get other than not found or
+// forbidden is retried, the last one is told in the timeout message.
+func (k *K8sClient) WaitForReady(res *common.ResourceInstanceAction, targetNs string, timeout time.Duration, progress func(ResourceHealth)) (ResourceHealth, error) {
+	obj, dr, _, err := k.resourceTarget(res, targetNs)
+	if err != nil {
+		return ResourceHealth{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var health ResourceHealth
+	// the error of the last get, the resource is not ready yet
+	var lastErr error
+	err = wait.PollUntilContextCancel(ctx, readyPollInterval, true, func(ctx context.Context) (bool, error) {
+		live, err := dr.Get(ctx, obj.GetName(), v1.GetOptions{})
+		if apierrors.IsForbidden(err) {
+			return false, err
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			// not the get cut short by the timeout
+			if ctx.Err() == nil {
+				lastErr = err
+			}
+			return false, nil
+		}
+		lastErr = nil
+		current := progressing("not found")
+		if err == nil {
+			current = AssessHealth(live)
+		}
+		if current != health {
+			health = current
+			progress(health)
+		}
+		return health.IsFinal(), nil
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return health, err
+	}
+	if err != nil {
+		health.State = HealthTimeout
+		health.Message = fmt.Sprintf("not ready after %v, %s", timeout, health.Message)
+		if lastErr != nil {
+			health.Message += fmt.Sprintf(", last error: %v", lastErr)
+		}
+	}
+	health.Checked = time.Now().Format(time.RFC3339)
+	return health, nil
+}
+
+// SetHealth records the health of an instance of the deployment resId
+func (d *DeployedResources) SetHealth(resId string, instId string, health ResourceHealth) {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return
+	}
+	if dd.Health == nil {
+		dd.Health = make(map[string]*ResourceHealth)
+	}
+	dd.Health[instId] = &health
+	d.persister.Update()
+}
+
+// HealthSummary tells how the instances are doing, empty if their
+// health is not known
+func (d *DeployDetail) HealthSummary() string {
+	if len(d.Health) == 0 {
+		return ""
+	}
+	ids := make([]string, 0, len(d.Health))
+	for id := range d.Health {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	readyCount := 0
+	worst := ""
+	worstFailed := false
+	for _, id := range ids {
+		health := d.Health[id]
+		if health.State == HealthReady {
+			readyCount++
+		} else if worst == "" || (health.State == HealthFailed && !worstFailed) {
+			worstFailed = health.State == HealthFailed
+			name := id
+			if inst, ok := d.AllInstances[id]; ok && inst.GetName() != "" {
+				name = inst.GetName()
+			}
+			worst = name + " " + string(health.State)
+		}
+	}
+	if readyCount == len(d.Health) {
+		return "Ready"
+	}
+	return fmt.Sprintf("%d/%d ready, %s", readyCount, len(d.Health), worst)
+}
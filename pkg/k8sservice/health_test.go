@@ -0,0 +1,186 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestObject(kind string, generation int64, fields map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetKind(kind)
+	obj.SetName("test")
+	obj.SetGeneration(generation)
+	for path, value := range fields {
+		unstructured.SetNestedField(obj.Object, value, strings.Split(path, ".")...)
+	}
+	return obj
+}
+
+func conditions(list ...map[string]any) []any {
+	result := []any{}
+	for _, c := range list {
+		result = append(result, c)
+	}
+	return result
+}
+
+func TestAssessHealth(t *testing.T) {
+	cases := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected HealthState
+	}{
+		{"deployment not observed", newTestObject("Deployment", 2, map[string]any{
+			"status.observedGeneration": int64(1)}), HealthProgressing},
+		{"deployment rolling", newTestObject("Deployment", 1, map[string]any{
+			"spec.replicas": int64(3), "status.observedGeneration": int64(1),
+			"status.updatedReplicas": int64(2), "status.replicas": int64(3)}), HealthProgressing},
+		{"deployment unavailable", newTestObject("Deployment", 1, map[string]any{
+			"status.observedGeneration": int64(1), "status.updatedReplicas": int64(1),
+			"status.replicas": int64(1)}), HealthProgressing},
+		{"deployment stuck", newTestObject("Deployment", 1, map[string]any{
+			"status.observedGeneration": int64(1),
+			"status.conditions":         conditions(condition("Progressing", "False", "ProgressDeadlineExceeded"))}), HealthFailed},
+		{"deployment complete", newTestObject("Deployment", 1, map[string]any{
+			"spec.replicas": int64(2), "status.observedGeneration": int64(1), "status.updatedReplicas": int64(2),
+			"status.replicas": int64(2), "status.availableReplicas": int64(2)}), HealthReady},
+		{"statefulset old revision", newTestObject("StatefulSet", 1, map[string]any{
+			"status.observedGeneration": int64(1), "status.readyReplicas": int64(1), "status.updatedReplicas": int64(1),
+			"status.currentRevision": "web-1", "status.updateRevision": "web-2"}), HealthProgressing},
+		{"statefulset complete", newTestObject("StatefulSet", 1, map[string]any{
+			"status.observedGeneration": int64(1), "status.readyReplicas": int64(1), "status.updatedReplicas": int64(1),
+			"status.currentRevision": "web-2", "status.updateRevision": "web-2"}), HealthReady},
+		{"daemonset complete", newTestObject("DaemonSet", 1, map[string]any{
+			"status.observedGeneration": int64(1), "status.desiredNumberScheduled": int64(2),
+			"status.updatedNumberScheduled": int64(2), "status.numberAvailable": int64(2)}), HealthReady},
+		{"job running", newTestObject("Job", 1, map[string]any{"status.active": int64(1)}), HealthProgressing},
+		{"job succeeded", newTestObject("Job", 1, map[string]any{
+			"status.conditions": conditions(condition("Complete", "True", ""))}), HealthReady},
+		{"job failed", newTestObject("Job", 1, map[string]any{
+			"status.conditions": conditions(condition("Failed", "True", "BackoffLimitExceeded"))}), HealthFailed},
+		{"pvc pending", newTestObject("PersistentVolumeClaim", 0, map[string]any{"status.phase": "Pending"}), HealthProgressing},
+		{"pvc bound", newTestObject("PersistentVolumeClaim", 0, map[string]any{"status.phase": "Bound"}), HealthReady},
+		{"pod pulling", newTestObject("Pod", 0, map[string]any{"status.phase": "Pending",
+			"status.containerStatuses": []any{map[string]any{"name": "app",
+				"state": map[string]any{"waiting": map[string]any{"reason": "ImagePullBackOff"}}}}}), HealthProgressing},
+		{"pod ready", newTestObject("Pod", 0, map[string]any{"status.phase": "Running",
+			"status.conditions": conditions(condition("Ready", "True", ""))}), HealthReady},
+		{"pod failed", newTestObject("Pod", 0, map[string]any{"status.phase": "Failed"}), HealthFailed},
+		{"custom resource not ready", newTestObject("Broker", 1, map[string]any{
+			"status.conditions": conditions(condition("Ready", "False", "Provisioning"))}), HealthProgressing},
+		{"custom resource available", newTestObject("Broker", 1, map[string]any{
+			"status.conditions": conditions(condition("Available", "True", ""))}), HealthReady},
+		{"custom resource without conditions", newTestObject("Broker", 1, nil), HealthReady},
+	}
+	for _, c := range cases {
+		if health := AssessHealth(c.obj); health.State != c.expected {
+			t.Errorf("%s: expected %s, got %v", c.name, c.expected, health)
+		}
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	client, dynClient := newTestDeployClient(newTestPod("default", "web"))
+	remote := startTestAgent(t, &server{
+		client:      client,
+		userClients: make(map[string]*K8sClient),
+	})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		pod := newTestPod("default", "web")
+		unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+		unstructured.SetNestedSlice(pod.Object, conditions(condition("Ready", "True", "")), "status", "conditions")
+		dynClient.Resource(podsGvr).Namespace("default").Update(context.TODO(), pod, metav1.UpdateOptions{})
+	}()
+
+	for _, service := range []K8sService{&LocalK8sService{localClient: client}, remote} {
+		progress := &progressRecorder{}
+		health, err := service.WaitForReady(newTestPodAction("web", "web", common.Create), "", 5*time.Second, func(health ResourceHealth) {
+			progress.add(string(health.State))
+		})
+		if err != nil {
+			t.Fatalf("failed to wait: %v", err)
+		}
+		if health.State != HealthReady || health.Checked == "" || !strings.HasSuffix(progress.String(), "Ready") {
+			t.Errorf("wrong health %+v, progress %q", health, progress)
+		}
+	}
+
+	health, err := remote.WaitForReady(newTestPodAction("db", "db", common.Create), "", 100*time.Millisecond, func(ResourceHealth) {})
+	if err != nil || health.State != HealthTimeout || !strings.Contains(health.Message, "not found") {
+		t.Errorf("expected a timeout, got %+v %v", health, err)
+	}
+}
+
+func TestWaitForReadyGetFails(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	pod := newTestPod("default", "web")
+	unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+	unstructured.SetNestedSlice(pod.Object, conditions(condition("Ready", "True", "")), "status", "conditions")
+	client, dynClient := newTestDeployClient(pod)
+	var gets atomic.Int32
+	dynClient.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.GetAction).GetName()
+		switch {
+		case name == "db":
+			return true, nil, apierrors.NewForbidden(podsGvr.GroupResource(), name, fmt.Errorf("no access"))
+		case name == "web" && gets.Add(1) <= 2:
+			return true, nil, apierrors.NewServiceUnavailable("the server is busy")
+		case name == "queue":
+			return true, nil, apierrors.NewTooManyRequests("slow down", 1)
+		}
+		return false, nil, nil
+	})
+
+	// ready once the server is back
+	health, err := client.WaitForReady(newTestPodAction("web", "web", common.Create), "", 5*time.Second, func(ResourceHealth) {})
+	if err != nil || health.State != HealthReady {
+		t.Errorf("expected ready, got %+v %v", health, err)
+	}
+	health, err = client.WaitForReady(newTestPodAction("queue", "queue", common.Create), "", 100*time.Millisecond, func(ResourceHealth) {})
+	if err != nil || health.State != HealthTimeout || !strings.Contains(health.Message, "slow down") {
+		t.Errorf("expected a timeout with the last error, got %+v %v", health, err)
+	}
+	if _, err := client.WaitForReady(newTestPodAction("db", "db", common.Create), "", 5*time.Second, func(ResourceHealth) {}); !apierrors.IsForbidden(err) {
+		t.Errorf("expected forbidden, got %v", err)
+	}
+}
+
+func TestHealthSummary(t *testing.T) {
+	deployed := &DeployedResources{
+		resIds:    make(map[string]*DeployDetail),
+		persister: &DummyPersister{},
+	}
+	if _, err := deployed.LockAndAdd(newTestCollection("apps", map[string]string{"web": "web", "db": "db"})); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	detail := deployed.resIds["apps"]
+	if summary := detail.HealthSummary(); summary != "" {
+		t.Errorf("unexpected health %q", summary)
+	}
+	deployed.SetHealth("apps", "web", ResourceHealth{State: HealthReady})
+	deployed.SetHealth("apps", "db", ResourceHealth{State: HealthTimeout})
+	if summary := detail.HealthSummary(); summary != "1/2 ready, db Timeout" {
+		t.Errorf("wrong health %q", summary)
+	}
+	deployed.SetHealth("apps", "db", ResourceHealth{State: HealthReady})
+	if summary := detail.HealthSummary(); summary != "Ready" {
+		t.Errorf("wrong health %q", summary)
+	}
+}
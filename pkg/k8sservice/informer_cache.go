@@ -0,0 +1,689 @@
+package k8sservice
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// an informer nobody listed or watched for this long is stopped
+var DEFAULT_INFORMER_IDLE_TIMEOUT = 10 * time.Minute
+
+// how long a request waits for a new informer to sync before it goes to the api server
+var informerSyncTimeout = 30 * time.Second
+
+// a resource whose informer failed is served by the api server for this long
+var informerRetryDelay = time.Minute
+
+const (
+	// the events kept to serve watches from a resource version older than the cache
+	informerEventBuffer = 1000
+	// a watcher falling this many events behind is closed as expired
+	informerWatcherBacklog = 10000
+	// continue tokens of the lists served from a cache
+	informerContinuePrefix = "informer:"
+	// the key of the CacheFreshness in a list served from a cache
+	cacheFreshnessKey = "resutilCacheFreshness"
+)
+
+// CacheFreshness tells how current a list served from the agent's informer cache is
+type CacheFreshness struct {
+	// the last time the informer got a change or a relist of the resource
+	LastUpdate time.Time
+	// the informer fails to watch the resource since StaleSince, the list may miss changes
+	Stale      bool
+	StaleSince time.Time
+}
+
+func (f *CacheFreshness) String() string {
+	if f.Stale {
+		return fmt.Sprintf("agent cache is stale since %s", f.StaleSince.Format(time.TimeOnly))
+	}
+	return fmt.Sprintf("served from the agent cache, updated %s ago", time.Since(f.LastUpdate).Truncate(time.Second))
+}
+
+// ListFreshness returns the freshness of a list the agent served from its cache,
+// nil if the list comes from the api server
+func ListFreshness(list *unstructured.UnstructuredList) *CacheFreshness {
+	value, ok := list.Object[cacheFreshnessKey].(map[string]any)
+	if !ok {
+		return nil
+	}
+	f := &CacheFreshness{}
+	f.Stale, _ = value["stale"].(bool)
+	if s, ok := value["lastUpdate"].(string); ok {
+		f.LastUpdate, _ = time.Parse(time.RFC3339, s)
+	}
+	if s, ok := value["staleSince"].(string); ok {
+		f.StaleSince, _ = time.Parse(time.RFC3339, s)
+	}
+	return f
+}
+
+func setListFreshness(list *unstructured.UnstructuredList, f CacheFreshness) {
+	value := map[string]any{
+		"lastUpdate": f.LastUpdate.Format(time.RFC3339),
+		"stale":      f.Stale,
+	}
+	if f.Stale {
+		value["staleSince"] = f.StaleSince.Format(time.RFC3339)
+	}
+	list.Object[cacheFreshnessKey] = value
+}
+
+type informerKey struct {
+	gvr schema.GroupVersionResource
+	ns  string
+}
+
+// InformerCache serves lists and watches from dynamic shared informers, started
+// for a resource when it is first requested and stopped when it is idle.
+// Requests the informers can't answer go to the api server.
+type InformerCache struct {
+	dynClient   dynamic.Interface
+	idleTimeout time.Duration
+
+	lock           sync.Mutex
+	informers      map[informerKey]*gvrInformer
+	failed         map[informerKey]time.Time
+	janitorRunning bool
+}
+
+func NewInformerCache(dynClient dynamic.Interface, idleTimeout time.Duration) *InformerCache {
+	if idleTimeout <= 0 {
+		idleTimeout = DEFAULT_INFORMER_IDLE_TIMEOUT
+	}
+	return &InformerCache{
+		dynClient:   dynClient,
+		idleTimeout: idleTimeout,
+		informers:   make(map[informerKey]*gvrInformer),
+		failed:      make(map[informerKey]time.Time),
+	}
+}
+
+// EnableInformerCache makes the client serve lists and watches from informers.
+// The agent does it for its clients if started with --informer-cache.
+func (k *K8sClient) EnableInformerCache(idleTimeout time.Duration) {
+	if k.dynClient != nil {
+		k.informers = NewInformerCache(k.dynClient, idleTimeout)
+	}
+}
+
+// cacheableSelectors parses the selectors of a request an informer can serve
+func cacheableSelectors(opts metav1.ListOptions) (labels.Selector, fields.Selector, bool) {
+	if opts.ResourceVersionMatch != "" {
+		return nil, nil, false
+	}
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		// the api server tells what is wrong
+		return nil, nil, false
+	}
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, nil, false
+	}
+	for _, req := range fieldSelector.Requirements() {
+		if req.Field != "metadata.name" && req.Field != "metadata.namespace" {
+			return nil, nil, false
+		}
+	}
+	return labelSelector, fieldSelector, true
+}
+
+// informerFor returns the synced informer of a resource, starting it if needed.
+// An informer of all namespaces serves each namespace too.
+func (c *InformerCache) informerFor(gvr schema.GroupVersionResource, ns string) *gvrInformer {
+	key := informerKey{gvr: gvr, ns: ns}
+	c.lock.Lock()
+	if _, all := c.informers[informerKey{gvr: gvr}]; all {
+		key.ns = ""
+	}
+	inf, ok := c.informers[key]
+	if !ok {
+		if failedAt, failed := c.failed[key]; failed && time.Since(failedAt) < informerRetryDelay {
+			c.lock.Unlock()
+			return nil
+		}
+		inf = newGvrInformer(c.dynClient, gvr, ns)
+		c.informers[key] = inf
+		if !c.janitorRunning {
+			c.janitorRunning = true
+			go c.evictIdle()
+		}
+	}
+	inf.touch()
+	c.lock.Unlock()
+
+	if err := inf.waitForSync(informerSyncTimeout); err != nil {
+		logger.Info("informer not synced, using the api server", zap.String("resource", gvr.String()),
+			zap.String("ns", ns), zap.Error(err))
+		c.lock.Lock()
+		if c.informers[key] == inf {
+			delete(c.informers, key)
+			c.failed[key] = time.Now()
+		}
+		c.lock.Unlock()
+		inf.stop()
+		return nil
+	}
+	inf.touch()
+	return inf
+}
+
+// evictIdle stops the idle informers until none is left
+func (c *InformerCache) evictIdle() {
+	ticker := time.NewTicker(c.idleTimeout / 2)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.lock.Lock()
+		for key, inf := range c.informers {
+			if inf.idleFor() > c.idleTimeout {
+				logger.Info("stopping idle informer", zap.String("resource", key.gvr.String()), zap.String("ns", key.ns))
+				delete(c.informers, key)
+				inf.stop()
+			}
+		}
+		if len(c.informers) == 0 {
+			c.janitorRunning = false
+			c.lock.Unlock()
+			return
+		}
+		c.lock.Unlock()
+	}
+}
+
+// Stop stops all informers
+func (c *InformerCache) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, inf := range c.informers {
+		delete(c.informers, key)
+		inf.stop()
+	}
+}
+
+// List serves a list from the informer of the resource, false if it must go to the api server
+func (c *InformerCache) List(gvr schema.GroupVersionResource, ns string, opts metav1.ListOptions) (*unstructured.UnstructuredList, bool) {
+	labelSelector, fieldSelector, ok := cacheableSelectors(opts)
+	if !ok {
+		return nil, false
+	}
+	start := ""
+	if opts.Continue != "" {
+		token, found := strings.CutPrefix(opts.Continue, informerContinuePrefix)
+		if !found {
+			return nil, false
+		}
+		data, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, false
+		}
+		start = string(data)
+	}
+	inf := c.informerFor(gvr, ns)
+	if inf == nil {
+		return nil, false
+	}
+	return inf.list(ns, labelSelector, fieldSelector, opts.Limit, start), true
+}
+
+// Watch serves a watch from the informer of the resource, false if it must go to the api server
+func (c *InformerCache) Watch(gvr schema.GroupVersionResource, ns string, opts metav1.ListOptions) (watch.Interface, bool) {
+	labelSelector, fieldSelector, ok := cacheableSelectors(opts)
+	if !ok {
+		return nil, false
+	}
+	if _, err := parseResourceVersion(opts.ResourceVersion); err != nil {
+		return nil, false
+	}
+	inf := c.informerFor(gvr, ns)
+	if inf == nil {
+		return nil, false
+	}
+	return inf.watch(ns, labelSelector, fieldSelector, opts.ResourceVersion), true
+}
+
+// parseResourceVersion compares resource versions as numbers, as etcd makes them
+func parseResourceVersion(rv string) (uint64, error) {
+	if rv == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(rv, 10, 64)
+}
+
+func objectKey(obj *unstructured.Unstructured) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+type cachedEvent struct {
+	eventType watch.EventType
+	rv        uint64
+	obj       *unstructured.Unstructured
+	// the object before the change, nil if it is added
+	old *unstructured.Unstructured
+}
+
+// gvrInformer keeps the objects of a resource as its event handler sees them,
+// so a list and its resource version always match the events that follow.
+type gvrInformer struct {
+	gvr      schema.GroupVersionResource
+	informer cache.SharedIndexInformer
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	synced   chan struct{}
+	failed   chan error
+
+	lock            sync.Mutex
+	objects         map[string]*unstructured.Unstructured
+	resourceVersion uint64
+	listed          bool
+	// events holds all events after resource version eventsFrom
+	events     []cachedEvent
+	eventsFrom uint64
+	watchers   map[*cacheWatcher]struct{}
+	lastUsed   time.Time
+	lastUpdate time.Time
+	staleSince time.Time
+	// the reflector's resource version when the watch failed
+	staleRv string
+}
+
+func newGvrInformer(dynClient dynamic.Interface, gvr schema.GroupVersionResource, ns string) *gvrInformer {
+	g := &gvrInformer{
+		gvr:      gvr,
+		informer: dynamicinformer.NewFilteredDynamicInformer(dynClient, gvr, ns, 0, cache.Indexers{}, nil).Informer(),
+		stopCh:   make(chan struct{}),
+		synced:   make(chan struct{}),
+		failed:   make(chan error, 1),
+		objects:  make(map[string]*unstructured.Unstructured),
+		watchers: make(map[*cacheWatcher]struct{}),
+	}
+	g.informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		g.setStale(err)
+		cache.DefaultWatchErrorHandler(r, err)
+	})
+	registration, _ := g.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj any, isInInitialList bool) {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				g.apply(watch.Added, u, isInInitialList)
+			}
+		},
+		UpdateFunc: func(_, obj any) {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				g.apply(watch.Modified, u, false)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				g.apply(watch.Deleted, u, false)
+			}
+		},
+	})
+	go g.informer.Run(g.stopCh)
+	go func() {
+		if cache.WaitForCacheSync(g.stopCh, registration.HasSynced) {
+			g.lock.Lock()
+			g.listDone()
+			g.lock.Unlock()
+			close(g.synced)
+		}
+	}()
+	return g
+}
+
+func (g *gvrInformer) waitForSync(timeout time.Duration) error {
+	select {
+	case <-g.synced:
+		return nil
+	default:
+	}
+	select {
+	case <-g.synced:
+		return nil
+	case err := <-g.failed:
+		// the next waiter fails too
+		g.setStale(err)
+		return err
+	case <-g.stopCh:
+		return fmt.Errorf("informer stopped")
+	case <-time.After(timeout):
+		return fmt.Errorf("informer not synced in %v", timeout)
+	}
+}
+
+func (g *gvrInformer) stop() {
+	g.stopOnce.Do(func() {
+		close(g.stopCh)
+	})
+}
+
+func (g *gvrInformer) touch() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.lastUsed = time.Now()
+}
+
+// idleFor is zero while the informer syncs or is watched
+func (g *gvrInformer) idleFor() time.Duration {
+	select {
+	case <-g.synced:
+	default:
+		return 0
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if len(g.watchers) > 0 {
+		return 0
+	}
+	return time.Since(g.lastUsed)
+}
+
+func (g *gvrInformer) setStale(err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.staleSince.IsZero() {
+		g.staleSince = time.Now()
+		g.staleRv = g.informer.LastSyncResourceVersion()
+		logger.Info("informer watch failed", zap.String("resource", g.gvr.String()), zap.Error(err))
+	}
+	select {
+	case g.failed <- err:
+	default:
+	}
+}
+
+func (g *gvrInformer) freshness() CacheFreshness {
+	if !g.staleSince.IsZero() && g.informer.LastSyncResourceVersion() != g.staleRv {
+		// relisted without changes
+		g.staleSince = time.Time{}
+		g.lastUpdate = time.Now()
+	}
+	return CacheFreshness{
+		LastUpdate: g.lastUpdate,
+		Stale:      !g.staleSince.IsZero(),
+		StaleSince: g.staleSince,
+	}
+}
+
+// listDone ends the initial list, the changes after it are events
+func (g *gvrInformer) listDone() {
+	if !g.listed {
+		g.listed = true
+		g.eventsFrom = g.resourceVersion
+	}
+}
+
+func (g *gvrInformer) apply(eventType watch.EventType, obj *unstructured.Unstructured, initial bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.lastUpdate = time.Now()
+	g.staleSince = time.Time{}
+
+	key := objectKey(obj)
+	old := g.objects[key]
+	if eventType == watch.Deleted {
+		delete(g.objects, key)
+	} else {
+		if old != nil && old.GetResourceVersion() == obj.GetResourceVersion() {
+			// a relist of an unchanged object
+			return
+		}
+		g.objects[key] = obj
+	}
+	if rv, err := parseResourceVersion(obj.GetResourceVersion()); err == nil && rv > g.resourceVersion {
+		g.resourceVersion = rv
+	}
+	if initial {
+		return
+	}
+	g.listDone()
+
+	event := cachedEvent{eventType: eventType, rv: g.resourceVersion, obj: obj, old: old}
+	g.events = append(g.events, event)
+	if len(g.events) > informerEventBuffer {
+		g.eventsFrom = g.events[0].rv
+		g.events = slices.Delete(g.events, 0, 1)
+	}
+	for w := range g.watchers {
+		w.send(w.filter(event)...)
+	}
+}
+
+func (g *gvrInformer) list(ns string, labelSelector labels.Selector, fieldSelector fields.Selector, limit int64, start string) *unstructured.UnstructuredList {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	filter := objectFilter{ns: ns, labels: labelSelector, fields: fieldSelector}
+	keys := make([]string, 0)
+	for key, obj := range g.objects {
+		if key > start && filter.matches(obj) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	metadata := map[string]any{}
+	if g.resourceVersion > 0 {
+		metadata["resourceVersion"] = strconv.FormatUint(g.resourceVersion, 10)
+	}
+	if limit > 0 && int64(len(keys)) > limit {
+		keys = keys[:limit]
+		metadata["continue"] = informerContinuePrefix + base64.RawURLEncoding.EncodeToString([]byte(keys[len(keys)-1]))
+	}
+	result := &unstructured.UnstructuredList{Object: map[string]any{
+		"apiVersion": g.gvr.GroupVersion().String(),
+		"kind":       "List",
+		"metadata":   metadata,
+	}}
+	for _, key := range keys {
+		result.Items = append(result.Items, *g.objects[key].DeepCopy())
+	}
+	if len(result.Items) > 0 {
+		result.SetKind(result.Items[0].GetKind() + "List")
+	}
+	setListFreshness(result, g.freshness())
+	return result
+}
+
+// watch follows the changes after resource version rv. Without rv the existing
+// objects come first as added, as from the api server. A rv older than the
+// buffered events gets an expired error, so the caller lists again.
+func (g *gvrInformer) watch(ns string, labelSelector labels.Selector, fieldSelector fields.Selector, rv string) watch.Interface {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	w := newCacheWatcher(g, objectFilter{ns: ns, labels: labelSelector, fields: fieldSelector})
+	from, _ := parseResourceVersion(rv)
+	switch {
+	case rv == "" || rv == "0":
+		keys := make([]string, 0, len(g.objects))
+		for key, obj := range g.objects {
+			if w.matches(obj) {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			w.send(watch.Event{Type: watch.Added, Object: g.objects[key].DeepCopy()})
+		}
+	case from < g.eventsFrom:
+		status := apierrors.NewResourceExpired(fmt.Sprintf("too old resource version: %d (%d)", from, g.eventsFrom)).Status()
+		w.expire(&status)
+		return w
+	default:
+		for _, event := range g.events {
+			if event.rv > from {
+				w.send(w.filter(event)...)
+			}
+		}
+	}
+	g.watchers[w] = struct{}{}
+	return w
+}
+
+func (g *gvrInformer) removeWatcher(w *cacheWatcher) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.watchers, w)
+	g.lastUsed = time.Now()
+}
+
+type objectFilter struct {
+	ns     string
+	labels labels.Selector
+	fields fields.Selector
+}
+
+func (f objectFilter) matches(obj *unstructured.Unstructured) bool {
+	if f.ns != "" && obj.GetNamespace() != f.ns {
+		return false
+	}
+	return f.labels.Matches(labels.Set(obj.GetLabels())) && f.fields.Matches(fields.Set{
+		"metadata.name":      obj.GetName(),
+		"metadata.namespace": obj.GetNamespace(),
+	})
+}
+
+// cacheWatcher is a watch served from an informer. Its events are queued, so
+// the informer never waits for a slow caller.
+type cacheWatcher struct {
+	objectFilter
+	informer *gvrInformer
+	result   chan watch.Event
+	notify   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	lock    sync.Mutex
+	pending []watch.Event
+	// closes the watch once the pending events are sent
+	closing bool
+}
+
+func newCacheWatcher(informer *gvrInformer, filter objectFilter) *cacheWatcher {
+	w := &cacheWatcher{
+		objectFilter: filter,
+		informer:     informer,
+		result:       make(chan watch.Event),
+		notify:       make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// filter turns an event into what the watcher sees, an object changed into
+// or out of its selectors is added or deleted
+func (w *cacheWatcher) filter(event cachedEvent) []watch.Event {
+	matched := w.matches(event.obj)
+	wasMatched := event.old != nil && w.matches(event.old)
+	eventType := event.eventType
+	switch {
+	case eventType == watch.Deleted:
+		if !matched && !wasMatched {
+			return nil
+		}
+	case matched && !wasMatched:
+		eventType = watch.Added
+	case !matched && wasMatched:
+		eventType = watch.Deleted
+	case !matched:
+		return nil
+	}
+	return []watch.Event{{Type: eventType, Object: event.obj.DeepCopy()}}
+}
+
+func (w *cacheWatcher) send(events ...watch.Event) {
+	if len(events) == 0 {
+		return
+	}
+	w.lock.Lock()
+	if w.closing {
+		w.lock.Unlock()
+		return
+	}
+	w.pending = append(w.pending, events...)
+	if len(w.pending) > informerWatcherBacklog {
+		w.lock.Unlock()
+		status := apierrors.NewResourceExpired("watcher is too slow").Status()
+		w.expire(&status)
+		return
+	}
+	w.lock.Unlock()
+	w.wakeUp()
+}
+
+// expire replaces the pending events with an error and closes the watch
+func (w *cacheWatcher) expire(status *metav1.Status) {
+	w.lock.Lock()
+	w.pending = []watch.Event{{Type: watch.Error, Object: status}}
+	w.closing = true
+	w.lock.Unlock()
+	w.wakeUp()
+}
+
+func (w *cacheWatcher) wakeUp() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *cacheWatcher) run() {
+	defer close(w.result)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.informer.stopCh:
+			return
+		case <-w.notify:
+		}
+		w.lock.Lock()
+		events, closing := w.pending, w.closing
+		w.pending = nil
+		w.lock.Unlock()
+		for _, event := range events {
+			select {
+			case w.result <- event:
+			case <-w.done:
+				return
+			}
+		}
+		if closing {
+			w.Stop()
+			return
+		}
+	}
+}
+
+func (w *cacheWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.informer.removeWatcher(w)
+	})
+}
+
+func (w *cacheWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
@@ -0,0 +1,201 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestVersionedPod(name string, rv int, app string) *unstructured.Unstructured {
+	pod := newTestPod("default", name)
+	pod.SetResourceVersion(fmt.Sprintf("%d", rv))
+	pod.SetLabels(map[string]string{"app": app})
+	return pod
+}
+
+// newTestCachedAgent is an agent serving lists and watches from informers
+func newTestCachedAgent(t *testing.T, objects ...runtime.Object) (*RemoteK8sService, *dynamicfake.FakeDynamicClient) {
+	dynClient := newTestDynClient(objects...)
+	client := &K8sClient{dynClient: dynClient}
+	client.EnableInformerCache(time.Minute)
+	t.Cleanup(client.informers.Stop)
+	remote := startTestAgent(t, &server{
+		client:      client,
+		userClients: make(map[string]*K8sClient),
+	})
+	return remote, dynClient
+}
+
+func countActions(dynClient *dynamicfake.FakeDynamicClient, verb string) int {
+	count := 0
+	for _, action := range dynClient.Actions() {
+		if action.GetVerb() == verb {
+			count++
+		}
+	}
+	return count
+}
+
+func TestInformerCacheList(t *testing.T) {
+	remote, dynClient := newTestCachedAgent(t,
+		newTestVersionedPod("web-1", 10, "web"),
+		newTestVersionedPod("web-2", 11, "web"),
+		newTestVersionedPod("db-1", 12, "db"))
+
+	first, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(first.Items) != 2 || first.Items[0].GetName() != "db-1" || first.GetResourceVersion() != "12" {
+		t.Fatalf("wrong first page %v", first.Object)
+	}
+	if !strings.HasPrefix(first.GetContinue(), informerContinuePrefix) {
+		t.Errorf("wrong continue token %q", first.GetContinue())
+	}
+	freshness := ListFreshness(first)
+	if freshness == nil || freshness.Stale || time.Since(freshness.LastUpdate) > time.Minute {
+		t.Errorf("wrong freshness %+v", freshness)
+	}
+
+	next, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{Limit: 2, Continue: first.GetContinue()})
+	if err != nil || len(next.Items) != 1 || next.Items[0].GetName() != "web-2" || next.GetContinue() != "" {
+		t.Fatalf("wrong next page %v %v", next, err)
+	}
+
+	selected, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{
+		LabelSelector: "app=web",
+		FieldSelector: "metadata.name=web-1",
+	})
+	if err != nil || len(selected.Items) != 1 || selected.Items[0].GetName() != "web-1" {
+		t.Fatalf("wrong selection %v %v", selected, err)
+	}
+	if count := countActions(dynClient, "list"); count != 1 {
+		t.Errorf("expected only the informer to list, got %d lists", count)
+	}
+
+	// a field the informer can't select on goes to the api server
+	live, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{FieldSelector: "status.phase=Running"})
+	if err != nil || ListFreshness(live) != nil {
+		t.Errorf("expected a live list: %v", err)
+	}
+	if count := countActions(dynClient, "list"); count != 2 {
+		t.Errorf("expected a live list, got %d lists", count)
+	}
+}
+
+func TestInformerCacheWatch(t *testing.T) {
+	remote, dynClient := newTestCachedAgent(t,
+		newTestVersionedPod("web-1", 10, "web"),
+		newTestVersionedPod("db-1", 11, "db"))
+
+	list, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{LabelSelector: "app=web"})
+	if err != nil || len(list.Items) != 1 {
+		t.Fatalf("failed to list: %v", err)
+	}
+	w, err := remote.WatchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{
+		LabelSelector:   "app=web",
+		ResourceVersion: list.GetResourceVersion(),
+	})
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	defer w.Stop()
+
+	pods := dynClient.Resource(podsGvr).Namespace("default")
+	if _, err := pods.Create(context.TODO(), newTestVersionedPod("web-2", 12, "web"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	event := nextEvent(t, w)
+	if event.Type != watch.Added || event.Object.(*unstructured.Unstructured).GetName() != "web-2" {
+		t.Fatalf("wrong event %v", event)
+	}
+
+	// moving out of the selector is a delete
+	if _, err := pods.Update(context.TODO(), newTestVersionedPod("web-1", 13, "db"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	event = nextEvent(t, w)
+	if event.Type != watch.Deleted || event.Object.(*unstructured.Unstructured).GetName() != "web-1" {
+		t.Fatalf("wrong event %v", event)
+	}
+
+	// a later watch replays what it missed
+	replay, err := remote.WatchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{ResourceVersion: list.GetResourceVersion()})
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	defer replay.Stop()
+	for _, expected := range []string{"web-2", "web-1"} {
+		if event := nextEvent(t, replay); event.Object.(*unstructured.Unstructured).GetName() != expected {
+			t.Fatalf("wrong replayed event %v", event)
+		}
+	}
+
+	// older than the cache
+	expired, err := remote.WatchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{ResourceVersion: "5"})
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	defer expired.Stop()
+	event = nextEvent(t, expired)
+	if status, ok := event.Object.(*metav1.Status); event.Type != watch.Error || !ok || status.Code != 410 {
+		t.Fatalf("expected an expired error, got %v", event)
+	}
+
+	if count := countActions(dynClient, "watch"); count != 1 {
+		t.Errorf("expected only the informer to watch, got %d watches", count)
+	}
+}
+
+func TestInformerCacheEviction(t *testing.T) {
+	dynClient := newTestDynClient(newTestVersionedPod("web-1", 10, "web"))
+	informers := NewInformerCache(dynClient, 50*time.Millisecond)
+	defer informers.Stop()
+
+	if _, ok := informers.List(podsGvr, "default", metav1.ListOptions{}); !ok {
+		t.Fatalf("list not served from the cache")
+	}
+	w, ok := informers.Watch(podsGvr, "", metav1.ListOptions{})
+	if !ok {
+		t.Fatalf("watch not served from the cache")
+	}
+	informerCount := func() int {
+		informers.lock.Lock()
+		defer informers.lock.Unlock()
+		return len(informers.informers)
+	}
+	time.Sleep(200 * time.Millisecond)
+	// the watched informer is kept
+	if informerCount() != 1 {
+		t.Errorf("expected the watched informer only, got %d", informerCount())
+	}
+	w.Stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for informerCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if informerCount() != 0 {
+		t.Errorf("idle informers not evicted")
+	}
+
+	// an informer that can't list leaves the request to the api server
+	dynClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(podsGvr.GroupResource(), "", fmt.Errorf("no access"))
+	})
+	if _, ok := informers.List(podsGvr, "other", metav1.ListOptions{}); ok {
+		t.Errorf("failed informer served the list")
+	}
+	if informerCount() != 0 {
+		t.Errorf("failed informer kept")
+	}
+}
@@ -0,0 +1,190 @@
+package k8sservice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// INVENTORY_LABEL marks a deployed object with the deployment that owns
+// it, so that what a deployment left in the cluster can be found without
+// the local deployments.
+const INVENTORY_LABEL = "k8sutil.gaohoward.tools/deployment"
+
+// INSTANCE_ANNOTATION is the id of the resource instance a deployed object
+// was made from
+const INSTANCE_ANNOTATION = "k8sutil.gaohoward.tools/instance"
+
+// InventoryValue is the value of the inventory label of the deployment
+// resId. An id that is not a valid label value is hashed.
+func InventoryValue(resId string) string {
+	if len(validation.IsValidLabelValue(resId)) == 0 {
+		return resId
+	}
+	sum := sha256.Sum256([]byte(resId))
+	return hex.EncodeToString(sum[:])[:validation.LabelValueMaxLength]
+}
+
+// InventorySelector selects the objects deployed by the deployment resId
+func InventorySelector(resId string) string {
+	return INVENTORY_LABEL + "=" + InventoryValue(resId)
+}
+
+// stampInventory labels obj as deployed by resId from the instance instId
+func stampInventory(obj *unstructured.Unstructured, resId string, instId string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[INVENTORY_LABEL] = InventoryValue(resId)
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[INSTANCE_ANNOTATION] = instId
+	obj.SetAnnotations(annotations)
+}
+
+// InventoryObject is a live object labeled as deployed by a deployment
+type InventoryObject struct {
+	// the instance id, or kind/namespace/name if it has none
+	Id string
+	// the resource type like v1/pods
+	ApiVer string
+	Obj    *unstructured.Unstructured
+}
+
+// action returns the action deleting the object
+func (o *InventoryObject) action() (*common.ResourceInstanceAction, error) {
+	ref := &unstructured.Unstructured{}
+	ref.SetAPIVersion(o.Obj.GetAPIVersion())
+	ref.SetKind(o.Obj.GetKind())
+	ref.SetName(o.Obj.GetName())
+	ref.SetNamespace(o.Obj.GetNamespace())
+	cr, err := common.MarshalYaml(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &common.ResourceInstanceAction{
+		Instance: &common.ResourceInstance{
+			Id:   o.Id,
+			Spec: &common.ResourceSpec{ApiVer: o.ApiVer},
+			Cr:   cr,
+		},
+		Action:    common.Delete,
+		DefaultNs: o.Obj.GetNamespace(),
+	}, nil
+}
+
+// FindInventory queries the cluster for the objects labeled as deployed by
+// the deployment resId, through every resource type that can be listed.
+// The types that can't be listed, e.g. forbidden to the user, are left
+// out.
+func FindInventory(client K8sService, resId string) ([]*InventoryObject, error) {
+	allRes := client.FetchAllApiResources(false)
+	if allRes == nil {
+		return nil, fmt.Errorf("no api resources of the cluster")
+	}
+	keys := make([]string, 0, len(allRes.ResMap))
+	for key, entry := range allRes.ResMap {
+		// subresources like pods/log are not objects
+		if entry.ApiRes == nil || strings.Contains(entry.ApiRes.Name, "/") {
+			continue
+		}
+		if !slices.Contains(entry.ApiRes.Verbs, "list") || !slices.Contains(entry.ApiRes.Verbs, "delete") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	objects := make([]*InventoryObject, 0)
+	// the same object can be served by more than one api
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		entry := allRes.ResMap[key]
+		gv, err := schema.ParseGroupVersion(entry.Gv)
+		if err != nil {
+			continue
+		}
+		list, err := client.FetchGVRInstances(gv.Group, gv.Version, entry.ApiRes.Name, "", v1.ListOptions{LabelSelector: InventorySelector(resId)})
+		if err != nil {
+			if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+				logger.Debug("Skip inventory of", zap.String("resource", key), zap.Error(err))
+				continue
+			}
+			return nil, err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if uid := string(obj.GetUID()); uid != "" {
+				if seen[uid] {
+					continue
+				}
+				seen[uid] = true
+			}
+			id := obj.GetAnnotations()[INSTANCE_ANNOTATION]
+			if id == "" {
+				id = obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+			}
+			objects = append(objects, &InventoryObject{Id: id, ApiVer: key, Obj: obj})
+		}
+	}
+	return objects, nil
+}
+
+// PlanPrune adds to the plan the deletes of the objects the cluster has
+// labeled as deployed by the plan's deployment that are no longer part of
+// it, whether or not the local deployments know them.
+func (p *DeployPlan) PlanPrune(client K8sService) error {
+	objects, err := FindInventory(client, p.ResId)
+	if err != nil {
+		return err
+	}
+	planned := make(map[string]bool, len(p.Items))
+	for _, item := range p.Items {
+		planned[item.Id] = true
+	}
+	actions := make(map[string]*common.ResourceInstanceAction)
+	for _, obj := range objects {
+		if p.desired[obj.Id] || planned[obj.Id] {
+			continue
+		}
+		action, err := obj.action()
+		if err != nil {
+			return err
+		}
+		actions[obj.Id] = action
+	}
+	order, err := UndeployOrder(actions)
+	if err != nil {
+		return err
+	}
+	// after the other actions, before the unchanged
+	at := slices.IndexFunc(p.Items, func(item *DeployPlanItem) bool {
+		return item.Action == nil
+	})
+	if at < 0 {
+		at = len(p.Items)
+	}
+	prunes := make([]*DeployPlanItem, 0, len(order))
+	for _, id := range order {
+		item := newDeployPlanItem(id, actions[id])
+		item.Reason = "left in the cluster by an earlier deploy"
+		prunes = append(prunes, item)
+	}
+	p.Items = slices.Insert(p.Items, at, prunes...)
+	return nil
+}
@@ -0,0 +1,109 @@
+package k8sservice
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func newTestInventoryPod(name string, resId string, instId string) *unstructured.Unstructured {
+	pod := newTestVersionedPod(name, 1, name)
+	stampInventory(pod, resId, instId)
+	return pod
+}
+
+var configMapsGvr = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func TestInventoryValue(t *testing.T) {
+	id := "8c5e4d1f-3a5b-4c2d-9e8f-0a1b2c3d4e5f"
+	if InventoryValue(id) != id {
+		t.Errorf("a valid id should be kept, got %s", InventoryValue(id))
+	}
+	long := InventoryValue(strings.Repeat("a/b ", 30))
+	if errs := validation.IsValidLabelValue(long); len(errs) > 0 {
+		t.Errorf("invalid label value %s: %v", long, errs)
+	}
+	if long == InventoryValue(strings.Repeat("a/b ", 31)) {
+		t.Errorf("different ids have the same label value")
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	for _, remote := range []bool{false, true} {
+		// a config map apps deployed before it was dropped from the collection
+		settings := &unstructured.Unstructured{}
+		settings.SetAPIVersion("v1")
+		settings.SetKind("ConfigMap")
+		settings.SetName("settings")
+		settings.SetNamespace("default")
+		stampInventory(settings, "apps", "settings")
+		client, dynClient, fake := newTestDiscoveryClient(
+			newTestInventoryPod("web", "apps", "web"),
+			newTestInventoryPod("old", "apps", "old"),
+			newTestInventoryPod("other", "others", "old"),
+			newTestVersionedPod("stray", 1, "stray"),
+			settings)
+		fake.Resources[0].APIResources[0].Verbs = []string{"create", "delete", "get", "list", "patch"}
+		fake.Resources[0].APIResources = append(fake.Resources[0].APIResources,
+			metav1.APIResource{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"delete", "list"}})
+		applyPatches(dynClient, "")
+		var service K8sService = &LocalK8sService{localClient: client}
+		if remote {
+			service = startTestAgent(t, &server{
+				client:      client,
+				userClients: make(map[string]*K8sClient),
+			})
+		}
+
+		// nothing deployed as far as this machine knows
+		deployed := &DeployedResources{
+			resIds:    make(map[string]*DeployDetail),
+			persister: &DummyPersister{},
+		}
+		plan, err := deployed.PlanDeploy(newTestCollection("apps", map[string]string{"web": "web2", "queue": "queue"}))
+		if err != nil {
+			t.Fatalf("failed to plan: %v", err)
+		}
+		if err := plan.PlanPrune(service); err != nil {
+			t.Fatalf("remote %v: failed to prune: %v", remote, err)
+		}
+		actions := []string{}
+		for _, item := range plan.Items {
+			actions = append(actions, item.Id+":"+item.ActionName())
+		}
+		if strings.Join(actions, ",") != "queue:create,web:create,old:delete,settings:delete" {
+			t.Fatalf("remote %v: wrong plan %v", remote, actions)
+		}
+
+		for _, item := range plan.Approved() {
+			if _, _, err := service.DeployResource(item.Action, ""); err != nil {
+				t.Fatalf("remote %v: failed to deploy %s: %v", remote, item.Id, err)
+			}
+		}
+		if podApp(t, dynClient, "old") != "absent" || podApp(t, dynClient, "other") != "other" || podApp(t, dynClient, "stray") != "stray" {
+			t.Errorf("remote %v: wrong objects pruned", remote)
+		}
+		if _, err := dynClient.Resource(configMapsGvr).Namespace("default").Get(t.Context(), "settings", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("remote %v: the dropped config map is not pruned: %v", remote, err)
+		}
+		// the deployed objects are stamped as the inventory of apps
+		list, err := dynClient.Resource(podsGvr).Namespace("default").List(t.Context(), metav1.ListOptions{LabelSelector: InventorySelector("apps")})
+		if err != nil {
+			t.Fatalf("failed to list: %v", err)
+		}
+		ids := []string{}
+		for _, pod := range list.Items {
+			ids = append(ids, pod.GetAnnotations()[INSTANCE_ANNOTATION])
+		}
+		slices.Sort(ids)
+		if strings.Join(ids, ",") != "queue,web" {
+			t.Errorf("remote %v: wrong inventory %v", remote, ids)
+		}
+	}
+}
@@ -5,21 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"gaohoward.tools/k8s/resutil/pkg/common"
 	"gaohoward.tools/k8s/resutil/pkg/logs"
 	"gaohoward.tools/k8s/resutil/pkg/options"
 	"go.uber.org/zap"
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 	"gopkg.in/yaml.v3"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 var logger *zap.Logger
@@ -33,32 +41,70 @@ var CACHE_KEY_API_RESOURCES = "api_resources"
 type K8sService interface {
 	IsValid() bool
 	DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error)
+	PreviewResource(res *common.ResourceInstanceAction, targetNs string) (*ResourcePreview, error)
+	// wait for a deployed crd to be established so that its resources can be deployed,
+	// the states of the crd seen while waiting are passed to progress
+	WaitForCrdEstablished(name string, timeout time.Duration, progress func(string)) error
+	// wait for a deployed resource to be ready, its health is passed to progress as it changes
+	WaitForReady(res *common.ResourceInstanceAction, targetNs string, timeout time.Duration, progress func(ResourceHealth)) (ResourceHealth, error)
+	// the live object of res, nil if it doesn't exist
+	GetLiveResource(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, error)
+	// put res back to the object it was before a deploy, delete it if prior is nil
+	RestoreResource(res *common.ResourceInstanceAction, targetNs string, prior *unstructured.Unstructured) error
 	GetClusterInfo() *common.ClusterInfo
 	GetAgent() string
 	// now the resource info no longer persisted (cached in mem only) for remote agent
 	FetchAllApiResources(force bool) *common.ApiResourceInfo
-	FetchGVRInstances(g string, v string, r string, ns string) (*unstructured.UnstructuredList, error)
+	// opts can have label/field selectors and limit/continue for paging
+	FetchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error)
+	// same as FetchGVRInstances bypassing any cache of the results, e.g. to
+	// relist after a watch expired
+	FetchGVRInstancesUncached(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error)
+	// watch for changes of the instances after opts.ResourceVersion, callers must Stop() the watch
+	WatchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (watch.Interface, error)
 	FetchAllNamespaces() ([]string, error)
-	GetPodLog(podRaw *unstructured.Unstructured, container string) (io.ReadCloser, error)
+	// read the log of a container, with opts.Follow it lasts until ctx is done or the reader is closed
+	GetPodLog(ctx context.Context, podRaw *unstructured.Unstructured, container string, opts PodLogOptions) (io.ReadCloser, error)
+	// run a command in a container of the pod until it exits or ctx is done
+	ExecPod(ctx context.Context, podRaw *unstructured.Unstructured, opts ExecOptions) error
+	// forward a local port to a pod or service, for a remote agent the traffic is tunneled through it
+	StartPortForward(spec PortForwardSpec) (*PortForward, error)
+	ListPortForwards() []PortForward
+	StopPortForward(id string) error
 	GetClusterName() string
 	GetCRDFor(resEntry *common.ApiResourceEntry) (string, error)
 	GetDescribeFor(item *unstructured.Unstructured) (string, error)
-	DoRawRequest(s string) (string, error)
+	DoRawRequest(req *RawRequest) (*RawResponse, error)
+	// the kubeconfig contexts available and the one in use
+	ListContexts() ([]string, string, error)
+	// switch to another kubeconfig context, the clients are rebuilt for it
+	SwitchContext(name string) error
+	// the stats of the cache of the results, nil if they are not cached
+	CacheStats() *CacheStats
 }
 
 type LocalK8sService struct {
+	// guards localClient, switched to another context
+	lock        sync.RWMutex
 	localClient *K8sClient
+	forwards    *PortForwardManager
+}
+
+func (l *LocalK8sService) client() *K8sClient {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.localClient
 }
 
 // DoRawRequest implements K8sService.
-func (l *LocalK8sService) DoRawRequest(s string) (string, error) {
-	return l.localClient.DoRawRequest(s)
+func (l *LocalK8sService) DoRawRequest(req *RawRequest) (*RawResponse, error) {
+	return l.client().DoRawRequest(req)
 }
 
 // GetDescribeFor implements K8sService.
 func (l *LocalK8sService) GetDescribeFor(item *unstructured.Unstructured) (string, error) {
 
-	return l.localClient.GetDescribeFor(item)
+	return l.client().GetDescribeFor(item)
 }
 
 // GetAgent implements K8sService.
@@ -68,17 +114,42 @@ func (l *LocalK8sService) GetAgent() string {
 
 // GetCRDFor implements K8sService.
 func (l *LocalK8sService) GetCRDFor(resEntry *common.ApiResourceEntry) (string, error) {
-	return l.localClient.GetCRDFor(resEntry)
+	return l.client().GetCRDFor(resEntry)
 }
 
 // DeployResource implements K8sService.
 func (l *LocalK8sService) DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error) {
-	return l.localClient.DeployResource(res, targetNs)
+	return l.client().DeployResource(res, targetNs)
+}
+
+// PreviewResource implements K8sService.
+func (l *LocalK8sService) PreviewResource(res *common.ResourceInstanceAction, targetNs string) (*ResourcePreview, error) {
+	return l.client().PreviewResource(res, targetNs)
+}
+
+// WaitForReady implements K8sService.
+func (l *LocalK8sService) WaitForReady(res *common.ResourceInstanceAction, targetNs string, timeout time.Duration, progress func(ResourceHealth)) (ResourceHealth, error) {
+	return l.client().WaitForReady(res, targetNs, timeout, progress)
+}
+
+// GetLiveResource implements K8sService.
+func (l *LocalK8sService) GetLiveResource(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, error) {
+	return l.client().GetLiveResource(res, targetNs)
+}
+
+// RestoreResource implements K8sService.
+func (l *LocalK8sService) RestoreResource(res *common.ResourceInstanceAction, targetNs string, prior *unstructured.Unstructured) error {
+	return l.client().RestoreResource(res, targetNs, prior)
+}
+
+// WaitForCrdEstablished implements K8sService.
+func (l *LocalK8sService) WaitForCrdEstablished(name string, timeout time.Duration, progress func(string)) error {
+	return l.client().WaitForCrdEstablished(name, timeout, progress)
 }
 
 // FetchAllApiResources implements K8sService.
 func (l *LocalK8sService) FetchAllApiResources(force bool) *common.ApiResourceInfo {
-	apiInfo := l.localClient.FetchAllApiResources(force)
+	apiInfo := l.client().FetchAllApiResources(force)
 
 	// dont do any persistence at k8s service level. handle it on gui side!
 	// if apiInfo != nil && !apiInfo.Cached {
@@ -91,71 +162,134 @@ func (l *LocalK8sService) FetchAllApiResources(force bool) *common.ApiResourceIn
 
 // FetchAllNamespaces implements K8sService.
 func (l *LocalK8sService) FetchAllNamespaces() ([]string, error) {
-	return l.localClient.FetchAllNamespaces()
+	return l.client().FetchAllNamespaces()
 }
 
 // FetchGVRInstances implements K8sService.
-func (l *LocalK8sService) FetchGVRInstances(g string, v string, r string, ns string) (*unstructured.UnstructuredList, error) {
-	return l.localClient.FetchGVRInstances(g, v, r, ns)
+func (l *LocalK8sService) FetchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return l.client().FetchGVRInstances(g, v, r, ns, opts)
+}
+
+// FetchGVRInstancesUncached implements K8sService, the local results are
+// not cached.
+func (l *LocalK8sService) FetchGVRInstancesUncached(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return l.client().FetchGVRInstances(g, v, r, ns, opts)
 }
 
 // GetClusterInfo implements K8sService.
 func (l *LocalK8sService) GetClusterInfo() *common.ClusterInfo {
-	return l.localClient.GetClusterInfo()
+	return l.client().GetClusterInfo()
 }
 
 // GetClusterName implements K8sService.
 func (l *LocalK8sService) GetClusterName() string {
-	return l.localClient.GetClusterName()
+	return l.client().GetClusterName()
 }
 
 // GetPodLog implements K8sService.
-func (l *LocalK8sService) GetPodLog(podRaw *unstructured.Unstructured, container string) (io.ReadCloser, error) {
-	return l.localClient.GetPodLog(podRaw, container)
+func (l *LocalK8sService) GetPodLog(ctx context.Context, podRaw *unstructured.Unstructured, container string, opts PodLogOptions) (io.ReadCloser, error) {
+	return l.client().GetPodLog(ctx, podRaw, container, opts)
+}
+
+// ExecPod implements K8sService.
+func (l *LocalK8sService) ExecPod(ctx context.Context, podRaw *unstructured.Unstructured, opts ExecOptions) error {
+	return l.client().ExecPod(ctx, podRaw, opts)
 }
 
 // IsValid implements K8sService.
 func (l *LocalK8sService) IsValid() bool {
-	return l.localClient.IsValid()
+	return l.client().IsValid()
+}
+
+// WatchGVRInstances implements K8sService.
+func (l *LocalK8sService) WatchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (watch.Interface, error) {
+	return l.client().WatchGVRInstances(g, v, r, ns, opts)
+}
+
+// ListContexts implements K8sService.
+func (l *LocalK8sService) ListContexts() ([]string, string, error) {
+	contexts, current, err := ListKubeContexts(options.Options.Kubeconfig)
+	if err != nil {
+		return nil, "", err
+	}
+	if client := l.client(); client.context != "" {
+		current = client.context
+	}
+	return contexts, current, nil
+}
+
+// SwitchContext implements K8sService.
+// The current client is kept if the new one can't be set up.
+func (l *LocalK8sService) SwitchContext(name string) error {
+	client := NewK8sClient(options.Options.Kubeconfig, name)
+	if !client.IsValid() {
+		return fmt.Errorf("failed to switch to context %v: %v", name, client.setupErr)
+	}
+	l.lock.Lock()
+	l.localClient = client
+	l.lock.Unlock()
+	setInternalClient(client)
+	options.Options.Context = name
+	// the forwards go to the previous cluster
+	l.forwards.StopAll()
+	return nil
+}
+
+// CacheStats implements K8sService.
+// A local client reads the cluster directly.
+func (l *LocalK8sService) CacheStats() *CacheStats {
+	return nil
 }
 
 type RemoteK8sService struct {
 	agentUrl string
 	Conn     *grpc.ClientConn
 	Cache    *K8sClientCache
+
+	lock sync.Mutex
+	// the agent cluster targeted by the rpcs, empty for the agent's default
+	cluster  string
+	forwards *PortForwardManager
 }
 
-// DoRawRequest implements K8sService.
-func (r *RemoteK8sService) DoRawRequest(req string) (string, error) {
+// CacheStats implements K8sService.
+func (r *RemoteK8sService) CacheStats() *CacheStats {
+	stats := r.Cache.Stats()
+	return &stats
+}
 
-	key := "raw_request: " + req
-	if cached, timeout := r.Cache.GetObject(key); cached != nil {
-		if !timeout {
-			return cached.(string), nil
-		}
-	}
+func (r *RemoteK8sService) getCluster() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.cluster
+}
 
+// DoRawRequest implements K8sService. The responses are not cached,
+// the request can change what the next one reads.
+func (r *RemoteK8sService) DoRawRequest(req *RawRequest) (*RawResponse, error) {
 	if r.Conn == nil {
-		return "", fmt.Errorf("no remote connection")
+		return nil, fmt.Errorf("no remote connection")
 	}
 
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	uriStr := wrapperspb.StringValue{
-		Value: req,
-	}
-
-	reply, err := grpcClient.DoRawRequest(context.Background(), &uriStr)
-
+	reply, err := grpcClient.DoRawRequest(context.Background(), &RawApiRequest{
+		Path:        req.Path,
+		Method:      req.Method,
+		Body:        req.Body,
+		ContentType: req.ContentType,
+		Headers:     headersToProto(req.Headers),
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed rpc call %v", err)
+		return nil, fromGrpcError(err)
 	}
 
-	resp := reply.GetResponse()
-
-	r.Cache.Put(key, resp)
-
-	return resp, nil
+	return &RawResponse{
+		StatusCode: int(reply.StatusCode),
+		Status:     reply.Status,
+		Headers:    headersFromProto(reply.Headers),
+		Body:       reply.Response,
+	}, nil
 }
 
 // GetDescribeFor implements K8sService.
@@ -163,36 +297,30 @@ func (r *RemoteK8sService) GetDescribeFor(item *unstructured.Unstructured) (stri
 
 	key := "describe_for: " + item.GetName() + "/" + item.GetNamespace()
 
-	if cached, timeout := r.Cache.GetObject(key); cached != nil {
-		if !timeout {
-			return cached.(string), nil
+	return GetOrLoad(r.Cache, key, DEFAULT_CACHE_TIMEOUT, func() (string, error) {
+		if r.Conn == nil {
+			return "", fmt.Errorf("no remote connection")
 		}
-	}
-
-	if r.Conn == nil {
-		return "", fmt.Errorf("no remote connection")
-	}
-
-	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	itemJson, err := json.Marshal(item)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal item: %w", err)
-	}
+		grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	itemStr := wrapperspb.StringValue{
-		Value: string(itemJson),
-	}
+		itemJson, err := json.Marshal(item)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal item: %w", err)
+		}
 
-	reply, err := grpcClient.GetDescribeFor(context.Background(), &itemStr)
+		itemStr := wrapperspb.StringValue{
+			Value: string(itemJson),
+		}
 
-	if err != nil {
-		return "", fmt.Errorf("failed rpc call %v", err)
-	}
+		reply, err := grpcClient.GetDescribeFor(context.Background(), &itemStr)
 
-	r.Cache.Put(key, reply.GetDescribe())
+		if err != nil {
+			return "", fromGrpcError(err)
+		}
 
-	return reply.GetDescribe(), nil
+		return reply.GetDescribe(), nil
+	})
 }
 
 // GetAgent implements K8sService.
@@ -203,62 +331,200 @@ func (r *RemoteK8sService) GetAgent() string {
 // GetCRDFor implements K8sService.
 func (r *RemoteK8sService) GetCRDFor(resEntry *common.ApiResourceEntry) (string, error) {
 
-	if cached, timeout := r.Cache.GetString(resEntry.Key()); cached != nil {
-		if !timeout {
-			return *cached, nil
+	return GetOrLoad(r.Cache, resEntry.Key(), DEFAULT_CACHE_TIMEOUT, func() (string, error) {
+		if r.Conn == nil {
+			return "", fmt.Errorf("no connection")
+		}
+
+		grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+		entry := ApiResourceEntry{
+			ApiVer: resEntry.ApiVer,
+			Gv:     resEntry.Gv,
+			Schema: resEntry.Schema,
+		}
+
+		resJson, err := json.Marshal(resEntry.ApiRes)
+		if err != nil {
+			logger.Error("failed to marsh api res", zap.Error(err))
+			return "", err
+		}
+
+		entry.ApiResourceJson = string(resJson)
+
+		reply, err := grpcClient.GetCRDFor(context.Background(), &entry)
+
+		if err != nil {
+			logger.Error("failed rpc call", zap.Error(err))
+			return "", fromGrpcError(err)
 		}
+
+		return reply.Crd, nil
+	})
+}
+
+// DeployResource implements K8sService.
+func (r *RemoteK8sService) DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error) {
+	if r.Conn == nil {
+		return types.NamespacedName{}, nil, fmt.Errorf("no connection")
+	}
+
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+	reply, err := grpcClient.DeployResource(context.Background(), deployRequestFor(res, targetNs))
+	if err != nil {
+		return types.NamespacedName{}, nil, fromGrpcError(err)
 	}
 
+	instance := &unstructured.Unstructured{}
+
+	if reply != nil {
+		err = yaml.Unmarshal([]byte(reply.ReplyJson), instance)
+		if err != nil {
+			logger.Info("error unmarshalling result", zap.Error(err))
+		}
+	}
+
+	return types.NamespacedName{Name: reply.Name, Namespace: reply.Namespace}, instance, nil
+}
+
+// PreviewResource implements K8sService.
+func (r *RemoteK8sService) PreviewResource(res *common.ResourceInstanceAction, targetNs string) (*ResourcePreview, error) {
 	if r.Conn == nil {
-		return "", fmt.Errorf("no connection")
+		return nil, fmt.Errorf("no connection")
 	}
 
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	entry := ApiResourceEntry{
-		ApiVer: resEntry.ApiVer,
-		Gv:     resEntry.Gv,
-		Schema: resEntry.Schema,
+	reply, err := grpcClient.PreviewResource(context.Background(), deployRequestFor(res, targetNs))
+	if err != nil {
+		return nil, fromGrpcError(err)
 	}
 
-	resJson, err := json.Marshal(resEntry.ApiRes)
+	preview := &ResourcePreview{
+		Name:      types.NamespacedName{Name: reply.Name, Namespace: reply.Namespace},
+		Action:    res.GetAction(),
+		DryRunErr: reply.DryRunStatus.toError(),
+	}
+	if preview.Live, err = unmarshalObject(reply.LiveJson); err != nil {
+		return nil, err
+	}
+	if preview.DryRun, err = unmarshalObject(reply.DryRunJson); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// GetLiveResource implements K8sService.
+func (r *RemoteK8sService) GetLiveResource(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, error) {
+	if r.Conn == nil {
+		return nil, fmt.Errorf("no connection")
+	}
+
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+	reply, err := grpcClient.GetLiveResource(context.Background(), deployRequestFor(res, targetNs))
 	if err != nil {
-		logger.Error("failed to marsh api res", zap.Error(err))
-		return "", err
+		return nil, fromGrpcError(err)
 	}
+	return unmarshalObject(reply.Value)
+}
 
-	entry.ApiResourceJson = string(resJson)
+// RestoreResource implements K8sService.
+func (r *RemoteK8sService) RestoreResource(res *common.ResourceInstanceAction, targetNs string, prior *unstructured.Unstructured) error {
+	if r.Conn == nil {
+		return fmt.Errorf("no connection")
+	}
 
-	reply, err := grpcClient.GetCRDFor(context.Background(), &entry)
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
+	priorJson, err := marshalObject(prior)
 	if err != nil {
-		logger.Error("failed rpc call", zap.Error(err))
-		return "", err
+		return err
 	}
+	_, err = grpcClient.RestoreResource(context.Background(), &RestoreResourceRequest{
+		Resource:  deployRequestFor(res, targetNs),
+		PriorJson: priorJson,
+	})
+	return fromGrpcError(err)
+}
 
-	if reply.Error != "" {
-		return "", fmt.Errorf("%s", reply.Error)
+// WaitForCrdEstablished implements K8sService.
+func (r *RemoteK8sService) WaitForCrdEstablished(name string, timeout time.Duration, progress func(string)) error {
+	if r.Conn == nil {
+		return fmt.Errorf("no connection")
 	}
 
-	r.Cache.Put(resEntry.Key(), reply.Crd)
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	return reply.Crd, nil
+	streamClient, err := grpcClient.WaitForCrdEstablished(context.Background(), &WaitForCrdRequest{
+		Name:      name,
+		TimeoutMs: timeout.Milliseconds(),
+	})
+	if err != nil {
+		return fromGrpcError(err)
+	}
+	for {
+		message, err := streamClient.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fromGrpcError(err)
+		}
+		progress(message.GetValue())
+	}
+	// the agent knows the new resources now
+	r.Cache.Remove(CACHE_KEY_API_RESOURCES)
+	return nil
 }
 
-// DeployResource implements K8sService.
-func (r *RemoteK8sService) DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error) {
+// WaitForReady implements K8sService.
+func (r *RemoteK8sService) WaitForReady(res *common.ResourceInstanceAction, targetNs string, timeout time.Duration, progress func(ResourceHealth)) (ResourceHealth, error) {
 	if r.Conn == nil {
-		return types.NamespacedName{}, nil, fmt.Errorf("no connection")
+		return ResourceHealth{}, fmt.Errorf("no connection")
 	}
 
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
+	streamClient, err := grpcClient.WaitForReady(context.Background(), &WaitForReadyRequest{
+		Resource:  deployRequestFor(res, targetNs),
+		TimeoutMs: timeout.Milliseconds(),
+	})
+	if err != nil {
+		return ResourceHealth{}, fromGrpcError(err)
+	}
+	var health ResourceHealth
+	for {
+		reply, err := streamClient.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return health, fromGrpcError(err)
+		}
+		health = ResourceHealth{
+			State:   HealthState(reply.State),
+			Message: reply.Message,
+			Checked: reply.Checked,
+		}
+		// the result comes with the time it was checked
+		if health.Checked == "" {
+			progress(health)
+		}
+	}
+	return health, nil
+}
+
+func deployRequestFor(res *common.ResourceInstanceAction, targetNs string) *DeployResourceRequest {
 	request := DeployResourceRequest{}
 	request.Action = int32(res.Action)
 	request.Cr = res.Instance.Cr
 	request.DefaultNs = res.DefaultNs
 	request.Id = res.Instance.Id
-	request.Order = int32(*res.Instance.Order)
+	if res.Instance.Order != nil {
+		request.Order = int32(*res.Instance.Order)
+	}
 	request.Spec = &ResourceSpec{
 		ApiVer: res.Instance.Spec.ApiVer,
 		Schema: res.Instance.Spec.Schema,
@@ -269,35 +535,32 @@ func (r *RemoteK8sService) DeployResource(res *common.ResourceInstanceAction, ta
 	request.InstName = res.Instance.InstName
 	request.Label = res.Instance.Label
 	request.TargetNs = targetNs
-
-	reply, err := grpcClient.DeployResource(context.Background(), &request)
-	if err != nil {
-		return types.NamespacedName{}, nil, err
-	}
-
-	instance := &unstructured.Unstructured{}
-
-	if reply != nil {
-		err = yaml.Unmarshal([]byte(reply.ReplyJson), instance)
-		if err != nil {
-			logger.Info("error unmarshalling result", zap.Error(err))
-		}
-	}
-
-	return types.NamespacedName{Name: reply.Name, Namespace: reply.Namespace}, instance, nil
+	request.DryRun = res.DryRun
+	request.Force = res.Force
+	request.Inventory = res.Inventory
+	return &request
 }
 
 // FetchAllApiResources implements K8sService.
 func (r *RemoteK8sService) FetchAllApiResources(force bool) *common.ApiResourceInfo {
-
-	if cached, timeout := r.Cache.GetObject(CACHE_KEY_API_RESOURCES); cached != nil {
-		if !timeout {
-			return cached.(*common.ApiResourceInfo)
-		}
+	// the api resources seldom change
+	fetch := GetOrLoad[*common.ApiResourceInfo]
+	if force {
+		fetch = Reload[*common.ApiResourceInfo]
+	}
+	apiInfo, err := fetch(r.Cache, CACHE_KEY_API_RESOURCES, 24*time.Hour, func() (*common.ApiResourceInfo, error) {
+		return r.fetchAllApiResources(force)
+	})
+	if err != nil {
+		logger.Error("failed rpc call", zap.Error(err))
+		return nil
 	}
+	return apiInfo
+}
 
+func (r *RemoteK8sService) fetchAllApiResources(force bool) (*common.ApiResourceInfo, error) {
 	if r.Conn == nil {
-		return nil
+		return nil, fmt.Errorf("no connection")
 	}
 
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
@@ -307,12 +570,7 @@ func (r *RemoteK8sService) FetchAllApiResources(force bool) *common.ApiResourceI
 	}
 	reply, err := grpcClient.FetchAllApiResources(context.Background(), request)
 	if err != nil {
-		logger.Error("failed rpc call", zap.Error(err))
-		return nil
-	}
-
-	if reply == nil {
-		return nil
+		return nil, fromGrpcError(err)
 	}
 
 	apiResList := make([]*v1.APIResourceList, 0)
@@ -352,63 +610,61 @@ func (r *RemoteK8sService) FetchAllApiResources(force bool) *common.ApiResourceI
 	// 	logger.Info("saved updated api-resources", zap.Int("list", len(apiInfo.ResList)), zap.Int("map", len(apiInfo.ResMap)), zap.Bool("cached", apiInfo.Cached))
 	// }
 
-	r.Cache.Put("api_resources", apiInfo)
-	return apiInfo
+	return apiInfo, nil
 }
 
 // FetchAllNamespaces implements K8sService.
 func (r *RemoteK8sService) FetchAllNamespaces() ([]string, error) {
 
-	if cached, timeout := r.Cache.GetObject("namespaces"); cached != nil {
-		if !timeout {
-			return cached.([]string), nil
+	return GetOrLoad(r.Cache, "namespaces", DEFAULT_CACHE_TIMEOUT, func() ([]string, error) {
+		if r.Conn == nil {
+			return nil, fmt.Errorf("no connection")
 		}
-	}
-
-	if r.Conn == nil {
-		return nil, fmt.Errorf("no connection")
-	}
 
-	grpcClient := NewGrpcK8SServiceClient(r.Conn)
-	empty := emptypb.Empty{}
-	reply, err := grpcClient.FetchAllNamespaces(context.Background(), &empty)
-	if err != nil {
-		return nil, fmt.Errorf("failed rpc call %v", err)
-	}
-	if reply.Error != "" {
-		return nil, fmt.Errorf("remote service error: %s", reply.Error)
-	}
-
-	r.Cache.Put("namespaces", reply.Namespaces)
+		grpcClient := NewGrpcK8SServiceClient(r.Conn)
+		empty := emptypb.Empty{}
+		reply, err := grpcClient.FetchAllNamespaces(context.Background(), &empty)
+		if err != nil {
+			return nil, fromGrpcError(err)
+		}
 
-	return reply.Namespaces, nil
+		return reply.Namespaces, nil
+	})
 }
 
 // FetchGVRInstances implements K8sService.
-func (r *RemoteK8sService) FetchGVRInstances(g string, v string, res string, ns string) (*unstructured.UnstructuredList, error) {
+func (r *RemoteK8sService) FetchGVRInstances(g string, v string, res string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
 
-	key := g + v + res + ns
-	if cached, timeout := r.Cache.GetObject(key); cached != nil {
-		if !timeout {
-			return cached.(*unstructured.UnstructuredList), nil
-		}
-	}
+	key := strings.Join([]string{g, v, res, ns, opts.LabelSelector, opts.FieldSelector,
+		strconv.FormatInt(opts.Limit, 10), opts.Continue, opts.ResourceVersion}, "|")
+	return GetOrLoad(r.Cache, key, DEFAULT_CACHE_TIMEOUT, func() (*unstructured.UnstructuredList, error) {
+		return r.FetchGVRInstancesUncached(g, v, res, ns, opts)
+	})
+}
 
+// FetchGVRInstancesUncached implements K8sService.
+func (r *RemoteK8sService) FetchGVRInstancesUncached(g string, v string, res string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
 	if r.Conn == nil {
 		return nil, fmt.Errorf("no connection")
 	}
 
+	request := newFetchGvrRequest(g, v, res, ns, opts)
+	request.Encoding = ItemEncodingCbor
+	result, err := r.streamGVRInstances(request)
+	if status.Code(err) == codes.Unimplemented {
+		// an agent older than StreamGVRInstances
+		result, err = r.fetchGVRInstancesJson(request)
+	}
+	return result, err
+}
+
+// fetchGVRInstancesJson reads the list as a whole json document
+func (r *RemoteK8sService) fetchGVRInstancesJson(request *FetchGvrRequest, callOpts ...grpc.CallOption) (*unstructured.UnstructuredList, error) {
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	request := &FetchGvrRequest{
-		G:  g,
-		V:  v,
-		R:  res,
-		Ns: ns,
-	}
-	reply, err := grpcClient.FetchGVRInstances(context.Background(), request)
+	reply, err := grpcClient.FetchGVRInstances(context.Background(), request, callOpts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed rpc call %v", err)
+		return nil, fromGrpcError(err)
 	}
 
 	result := &unstructured.UnstructuredList{}
@@ -417,105 +673,201 @@ func (r *RemoteK8sService) FetchGVRInstances(g string, v string, res string, ns
 		if err != nil {
 			return nil, fmt.Errorf("failed to unmarshal unstructured list: %w", err)
 		}
-		r.Cache.Put(key, result)
 		return result, nil
 	}
 	return nil, fmt.Errorf("no unstructured list returned from remote service")
 }
 
-// GetClusterInfo implements K8sService.
-func (r *RemoteK8sService) GetClusterInfo() *common.ClusterInfo {
+func newFetchGvrRequest(g string, v string, res string, ns string, opts v1.ListOptions) *FetchGvrRequest {
+	return &FetchGvrRequest{
+		G:               g,
+		V:               v,
+		R:               res,
+		Ns:              ns,
+		ResourceVersion: opts.ResourceVersion,
+		LabelSelector:   opts.LabelSelector,
+		FieldSelector:   opts.FieldSelector,
+		Limit:           opts.Limit,
+		Continue:        opts.Continue,
+	}
+}
+
+// RemoteWatcher turns the WatchGVRInstances stream into a watch.Interface
+type RemoteWatcher struct {
+	cancel context.CancelFunc
+	result chan watch.Event
+}
+
+// ResultChan implements watch.Interface.
+func (w *RemoteWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+// Stop implements watch.Interface.
+func (w *RemoteWatcher) Stop() {
+	w.cancel()
+}
 
-	if cached, timeout := r.Cache.GetObject("cluster_info"); cached != nil {
-		if !timeout {
-			return cached.(*common.ClusterInfo)
+func (w *RemoteWatcher) receive(ctx context.Context, streamClient grpc.ServerStreamingClient[WatchEvent]) {
+	defer close(w.result)
+	for {
+		remoteEvent, err := streamClient.Recv()
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				logger.Info("watch stream closed", zap.Error(err))
+			}
+			return
+		}
+		event := watch.Event{
+			Type: watch.EventType(remoteEvent.Type),
+		}
+		if event.Type == watch.Error {
+			status := &v1.Status{}
+			if err := json.Unmarshal([]byte(remoteEvent.ObjectJson), status); err != nil {
+				logger.Warn("failed to unmarshal watch error", zap.Error(err))
+				continue
+			}
+			event.Object = status
+		} else {
+			obj := &unstructured.Unstructured{}
+			if err := json.Unmarshal([]byte(remoteEvent.ObjectJson), obj); err != nil {
+				logger.Warn("failed to unmarshal watch object", zap.Error(err))
+				continue
+			}
+			event.Object = obj
+		}
+		select {
+		case w.result <- event:
+		case <-ctx.Done():
+			return
 		}
 	}
+}
 
+// WatchGVRInstances implements K8sService.
+func (r *RemoteK8sService) WatchGVRInstances(g string, v string, res string, ns string, opts v1.ListOptions) (watch.Interface, error) {
 	if r.Conn == nil {
-		return nil
+		return nil, fmt.Errorf("no connection")
 	}
 
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	empty := emptypb.Empty{}
-
-	reply, err := grpcClient.GetClusterInfo(context.Background(), &empty)
+	request := newFetchGvrRequest(g, v, res, ns, opts)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	streamClient, err := grpcClient.WatchGVRInstances(ctx, request)
 	if err != nil {
-		return nil
+		cancel()
+		return nil, fromGrpcError(err)
+	}
+	if err := streamStarted(streamClient); err != nil {
+		cancel()
+		return nil, err
 	}
 
-	clusterInfo := &common.ClusterInfo{
-		Host: reply.Host,
-		Id:   reply.Id,
+	watcher := &RemoteWatcher{
+		cancel: cancel,
+		result: make(chan watch.Event),
 	}
+	go watcher.receive(ctx, streamClient)
+
+	return watcher, nil
+}
 
-	r.Cache.Put("cluster_info", clusterInfo)
+// GetClusterInfo implements K8sService.
+func (r *RemoteK8sService) GetClusterInfo() *common.ClusterInfo {
+
+	clusterInfo, err := GetOrLoad(r.Cache, "cluster_info", DEFAULT_CACHE_TIMEOUT, func() (*common.ClusterInfo, error) {
+		if r.Conn == nil {
+			return nil, fmt.Errorf("no connection")
+		}
 
+		grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+		empty := emptypb.Empty{}
+
+		reply, err := grpcClient.GetClusterInfo(context.Background(), &empty)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &common.ClusterInfo{
+			Host: reply.Host,
+			Id:   reply.Id,
+		}, nil
+	})
+	if err != nil {
+		return nil
+	}
 	return clusterInfo
 }
 
 // GetClusterName implements K8sService.
 func (r *RemoteK8sService) GetClusterName() string {
 
-	if cached, timeout := r.Cache.GetString("cluster_name"); cached != nil {
-		if !timeout {
-			return *cached
+	name, _ := GetOrLoad(r.Cache, "cluster_name", DEFAULT_CACHE_TIMEOUT, func() (string, error) {
+		if r.Conn == nil {
+			return "", fmt.Errorf("no connection")
 		}
-	}
-
-	if r.Conn == nil {
-		return ""
-	}
 
-	grpcClient := NewGrpcK8SServiceClient(r.Conn)
+		grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	empty := emptypb.Empty{}
+		empty := emptypb.Empty{}
 
-	value, err := grpcClient.GetClusterName(context.Background(), &empty)
+		value, err := grpcClient.GetClusterName(context.Background(), &empty)
 
-	if err != nil {
-		return ""
-	}
-	r.Cache.Put("cluster_name", value.Value)
-	return value.Value
+		if err != nil {
+			return "", err
+		}
+		return value.Value, nil
+	})
+	return name
 }
 
 type LogReader struct {
 	streamClient grpc.ServerStreamingClient[wrapperspb.StringValue]
+	cancel       context.CancelFunc
+	// what is left of the last segment when p was too small for it
+	pending []byte
 }
 
 // Close implements io.ReadCloser.
 func (l *LogReader) Close() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
 	return nil
 }
 
 // Read implements io.ReadCloser.
 func (l *LogReader) Read(p []byte) (n int, err error) {
-	logSeg, err := l.streamClient.Recv()
-
-	num := 0
-	if logSeg != nil {
-		copy(p, []byte(logSeg.Value))
-		num = len(logSeg.Value)
+	if len(l.pending) == 0 {
+		logSeg, err := l.streamClient.Recv()
+		if err == io.EOF {
+			return 0, err
+		}
+		if err != nil {
+			return 0, fromGrpcError(err)
+		}
+		l.pending = []byte(logSeg.Value)
 	}
 
-	if err != nil {
-		return num, err
-	}
+	num := copy(p, l.pending)
+	l.pending = l.pending[num:]
 
 	return num, nil
 }
 
-func NewLogReader(streamClient grpc.ServerStreamingClient[wrapperspb.StringValue]) (io.ReadCloser, error) {
+func NewLogReader(streamClient grpc.ServerStreamingClient[wrapperspb.StringValue], cancel context.CancelFunc) (io.ReadCloser, error) {
 	return &LogReader{
 		streamClient: streamClient,
+		cancel:       cancel,
 	}, nil
 }
 
 // GetPodLog implements K8sService.
-func (r *RemoteK8sService) GetPodLog(podRaw *unstructured.Unstructured, container string) (io.ReadCloser, error) {
+func (r *RemoteK8sService) GetPodLog(ctx context.Context, podRaw *unstructured.Unstructured, container string, opts PodLogOptions) (io.ReadCloser, error) {
 	if r.Conn == nil {
 		return nil, fmt.Errorf("no remote connection")
 	}
@@ -523,7 +875,15 @@ func (r *RemoteK8sService) GetPodLog(podRaw *unstructured.Unstructured, containe
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
 	request := &PodLogRequest{
-		Container: container,
+		Container:    container,
+		Follow:       opts.Follow,
+		TailLines:    opts.TailLines,
+		SinceSeconds: opts.SinceSeconds,
+		Timestamps:   opts.Timestamps,
+		Previous:     opts.Previous,
+	}
+	if opts.SinceTime != nil {
+		request.SinceTime = opts.SinceTime.Format(time.RFC3339)
 	}
 	podBytes, err := json.Marshal(podRaw)
 	if err != nil {
@@ -531,14 +891,20 @@ func (r *RemoteK8sService) GetPodLog(podRaw *unstructured.Unstructured, containe
 	}
 	request.PodRawJson = string(podBytes)
 
-	streamClient, err := grpcClient.GetPodLog(context.Background(), request)
+	ctx, cancel := context.WithCancel(ctx)
+	streamClient, err := grpcClient.GetPodLog(ctx, request)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed rpc call %v", err)
+		cancel()
+		return nil, fromGrpcError(err)
+	}
+	if err := streamStarted(streamClient); err != nil {
+		cancel()
+		return nil, err
 	}
 
-	// wrap it in io.readcloser
-	return NewLogReader(streamClient)
+	// wrap it in io.readcloser, closing it ends the stream
+	return NewLogReader(streamClient, cancel)
 }
 
 // IsValid implements K8sService.
@@ -546,28 +912,63 @@ func (r *RemoteK8sService) IsValid() bool {
 
 	key := "is_client_valid"
 
-	if cached, timeout := r.Cache.GetBool(key); cached != nil {
-		if !timeout {
-			return *cached
+	valid, _ := GetOrLoad(r.Cache, key, DEFAULT_CACHE_TIMEOUT, func() (bool, error) {
+		if r.Conn == nil {
+			return false, fmt.Errorf("no connection")
 		}
-	}
 
+		grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+		result, err := grpcClient.IsValid(context.Background(), &emptypb.Empty{})
+
+		if err != nil {
+			logger.Error("failed rpc", zap.Error(err))
+			return false, err
+		}
+
+		return result.Value, nil
+	})
+	return valid
+}
+
+// ListContexts implements K8sService.
+// The contexts are the clusters served by the agent.
+func (r *RemoteK8sService) ListContexts() ([]string, string, error) {
 	if r.Conn == nil {
-		return false
+		return nil, "", fmt.Errorf("no connection to agent %v", r.agentUrl)
 	}
 
 	grpcClient := NewGrpcK8SServiceClient(r.Conn)
 
-	result, err := grpcClient.IsValid(context.Background(), &emptypb.Empty{})
-
+	reply, err := grpcClient.ListClusters(context.Background(), &emptypb.Empty{})
 	if err != nil {
-		logger.Error("failed rpc", zap.Error(err))
-		return false
+		return nil, "", fromGrpcError(err)
 	}
 
-	r.Cache.Put(key, result.Value)
+	current := r.getCluster()
+	if current == "" {
+		current = reply.DefaultCluster
+	}
+	return reply.Clusters, current, nil
+}
 
-	return result.Value
+// SwitchContext implements K8sService.
+func (r *RemoteK8sService) SwitchContext(name string) error {
+	clusters, _, err := r.ListContexts()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(clusters, name) {
+		return fmt.Errorf("cluster %v is not served by agent %v", name, r.agentUrl)
+	}
+	r.lock.Lock()
+	r.cluster = name
+	r.lock.Unlock()
+	// the cached results belong to the previous cluster
+	r.Cache.Clear()
+	// the forwards go to the previous cluster
+	r.portForwards().StopAll()
+	return nil
 }
 
 var k8sService K8sService
@@ -575,7 +976,8 @@ var k8sService K8sService
 func NewRemoteK8sService(agentUrl string) *RemoteK8sService {
 
 	service := &RemoteK8sService{
-		Cache: NewK8sCache(),
+		Cache:   NewK8sCache(),
+		cluster: options.Options.Context,
 	}
 
 	parts := strings.Split(agentUrl, ":")
@@ -590,10 +992,36 @@ func NewRemoteK8sService(agentUrl string) *RemoteK8sService {
 
 	opts := make([]grpc.DialOption, 0)
 	credOpts := grpc.WithTransportCredentials(insecure.NewCredentials())
+	if options.Options.Tls.Enabled {
+		creds, err := NewClientCredentials(&options.Options.Tls)
+		if err != nil {
+			logger.Error("failed to setup tls", zap.Error(err))
+			return service
+		}
+		credOpts = grpc.WithTransportCredentials(creds)
+		logger.Info("using tls for the agent connection")
+	}
 	opts = append(opts, credOpts)
+	if options.Options.Token != "" {
+		if !options.Options.Tls.Enabled {
+			logger.Warn("sending the agent token without tls")
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(&tokenCredentials{
+			token:  options.Options.Token,
+			secure: options.Options.Tls.Enabled,
+		}))
+	}
+	opts = append(opts, grpc.WithPerRPCCredentials(&clusterCredentials{
+		service: service,
+	}))
 	if options.Options.UseCompressor {
-		logger.Info("using compression in grpc")
-		zipOpts := grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name))
+		compressor := options.Options.Compressor
+		if encoding.GetCompressor(compressor) == nil {
+			logger.Warn("unknown grpc compressor, using gzip", zap.String("compressor", compressor))
+			compressor = gzip.Name
+		}
+		logger.Info("using compression in grpc", zap.String("compressor", compressor))
+		zipOpts := grpc.WithDefaultCallOptions(grpc.UseCompressor(compressor))
 		opts = append(opts, zipOpts)
 	}
 
@@ -611,8 +1039,9 @@ func NewRemoteK8sService(agentUrl string) *RemoteK8sService {
 
 func NewLocalK8sService() *LocalK8sService {
 	localService := &LocalK8sService{
-		localClient: internalClient,
+		localClient: getInternalClient(),
 	}
+	localService.forwards = NewPortForwardManager(localService.dialPortForward)
 
 	return localService
 }
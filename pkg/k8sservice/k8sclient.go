@@ -8,9 +8,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,28 +30,51 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/openapi/cached"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/kubectl/pkg/cmd/describe"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/explain"
 	ktlexplain "k8s.io/kubectl/pkg/explain/v2"
+	kubectlutil "k8s.io/kubectl/pkg/util"
 )
 
-var internalClient *K8sClient
+var (
+	internalLock   sync.RWMutex
+	internalClient *K8sClient
+)
+
+func getInternalClient() *K8sClient {
+	internalLock.RLock()
+	defer internalLock.RUnlock()
+	return internalClient
+}
+
+func setInternalClient(client *K8sClient) {
+	internalLock.Lock()
+	defer internalLock.Unlock()
+	internalClient = client
+}
 
 var NoApiResourceInfo = common.ApiResourceInfo{}
 
@@ -127,38 +153,31 @@ type K8sClient struct {
 	config          *rest.Config
 	discoveryClient discovery.CachedDiscoveryInterface
 	mapper          *restmapper.DeferredDiscoveryRESTMapper
-	dynClient       *dynamic.DynamicClient
+	dynClient       dynamic.Interface
 	setupErr        string
 	allRes          *common.ApiResourceInfo
 	clusterInfo     *common.ClusterInfo
 	generator       ktlexplain.Generator
+	// the kubeconfig context the config is built from, empty for the current context
+	context string
+	// serves lists and watches in the agent if enabled, see EnableInformerCache
+	informers *InformerCache
 	//	client    *rest.Config
 
 }
 
-func (k *K8sClient) DoRawRequest(s string) (string, error) {
-	clientset, err := kubernetes.NewForConfig(k.config)
-	if err != nil {
-		return err.Error(), fmt.Errorf("error in getting access to K8S")
-	}
-	resp := clientset.RESTClient().Get().AbsPath(s).Do(context.TODO())
-	if err = resp.Error(); err != nil {
-		return err.Error(), err
-	}
-
-	if data, err := resp.Raw(); err == nil {
-		return string(data), nil
-	} else {
-		return err.Error(), err
-	}
-
-}
-
 func (k *K8sClient) createNewKubectlDescribeCommand(ns *string, inReader io.Reader, outWriter io.Writer, errWriter io.Writer) *cobra.Command {
 	ioStreams := genericiooptions.IOStreams{In: inReader, Out: outWriter, ErrOut: errWriter}
 	cfgFlags := genericclioptions.NewConfigFlags(false).WithDeprecatedPasswordFlag().WithDiscoveryBurst(300).WithDiscoveryQPS(50.0)
 
 	cfgFlags.KubeConfig = &options.Options.Kubeconfig
+	if k.context != "" {
+		cfgFlags.Context = &k.context
+	}
+	if k.config != nil && k.config.Impersonate.UserName != "" {
+		cfgFlags.Impersonate = &k.config.Impersonate.UserName
+		cfgFlags.ImpersonateGroup = &k.config.Impersonate.Groups
+	}
 	if ns != nil {
 		cfgFlags.Namespace = ns
 	}
@@ -262,31 +281,22 @@ func (k *K8sClient) K8sObjectToYaml(obj runtime.Object) string {
 	return writer.String()
 }
 
-func (k *K8sClient) DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error) {
-
-	finalNamespace := types.NamespacedName{
-		Name:      res.GetName(),
-		Namespace: "",
-	}
-
-	if !k.IsValid() {
-		logger.Warn("k8s client is not set updid you have a cluster up and running?")
-		return finalNamespace, nil, fmt.Errorf("no rest client")
-	}
-
+// resourceTarget decodes the cr of res and finds the api of its resource. The
+// namespace of the object is the one it is deployed to, empty if cluster wide.
+func (k *K8sClient) resourceTarget(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, dynamic.ResourceInterface, string, error) {
 	obj := &unstructured.Unstructured{}
 	dec := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
 	_, gvk, err := dec.Decode([]byte(res.Instance.GetCR()), nil, obj)
 
 	if err != nil {
-		return finalNamespace, nil, err
+		return nil, nil, "", err
 	}
 
 	mapping, err := k.RetrieveMapping(gvk.GroupKind(), gvk.Version, true)
 
 	if err != nil {
 		logger.Info("failed to get mapping", zap.String("err", err.Error()))
-		return finalNamespace, nil, err
+		return nil, nil, "", err
 	}
 	if obj.GetNamespace() == "" {
 		if targetNs != "" {
@@ -295,9 +305,11 @@ func (k *K8sClient) DeployResource(res *common.ResourceInstanceAction, targetNs
 			obj.SetNamespace(res.GetDefaultNamespace())
 		}
 	}
-
-	//update deployDetail
-	finalNamespace.Namespace = obj.GetNamespace()
+	// the deploy detail keeps the namespace even for cluster wide resources
+	finalNs := obj.GetNamespace()
+	if res.Inventory != "" && res.GetAction() != common.Delete {
+		stampInventory(obj, res.Inventory, res.Instance.GetId())
+	}
 
 	var dr dynamic.ResourceInterface
 	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
@@ -308,19 +320,52 @@ func (k *K8sClient) DeployResource(res *common.ResourceInstanceAction, targetNs
 		dr = k.dynClient.Resource(mapping.Resource)
 		obj.SetNamespace("")
 	}
+	return obj, dr, finalNs, nil
+}
+
+func (k *K8sClient) DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error) {
+
+	finalNamespace := types.NamespacedName{
+		Name:      res.GetName(),
+		Namespace: "",
+	}
+
+	if !k.IsValid() {
+		logger.Warn("k8s client is not set updid you have a cluster up and running?")
+		return finalNamespace, nil, fmt.Errorf("no rest client")
+	}
+
+	obj, dr, finalNs, err := k.resourceTarget(res, targetNs)
+	if err != nil {
+		return finalNamespace, nil, err
+	}
+
+	//update deployDetail
+	finalNamespace.Namespace = finalNs
+
 	data, err := json.Marshal(obj)
 	if err != nil {
 		return finalNamespace, nil, err
 	}
 
+	var dryRun []string
+	if res.DryRun {
+		dryRun = []string{v1.DryRunAll}
+	}
+
+	var force *bool
+	if res.Force {
+		force = &res.Force
+	}
+
 	var finalResp *unstructured.Unstructured = nil
 
 	switch res.GetAction() {
 	case common.Create:
-		logger.Info("CREATE resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
-		if resp, err := dr.Apply(context.TODO(), obj.GetName(), obj, v1.ApplyOptions{FieldManager: common.APP_NAME}); err == nil {
+		logger.Info("CREATE resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()), zap.Bool("dryRun", res.DryRun))
+		if resp, err := dr.Apply(context.TODO(), obj.GetName(), obj, v1.ApplyOptions{FieldManager: common.APP_NAME, DryRun: dryRun, Force: res.Force}); err == nil {
 			finalResp = resp
-		} else if resp, err := dr.Create(context.TODO(), obj, v1.CreateOptions{}); err == nil {
+		} else if resp, err := dr.Create(context.TODO(), obj, v1.CreateOptions{DryRun: dryRun}); err == nil {
 			// some resources like tokenreview only accept create word
 			// so try create if apply fails
 			finalResp = resp
@@ -330,17 +375,17 @@ func (k *K8sClient) DeployResource(res *common.ResourceInstanceAction, targetNs
 		}
 		logger.Info("Resource created successfully", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
 	case common.Delete:
-		logger.Info("DELETE resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
-		if err := dr.Delete(context.TODO(), obj.GetName(), v1.DeleteOptions{}); err != nil {
+		logger.Info("DELETE resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()), zap.Bool("dryRun", res.DryRun))
+		if err := dr.Delete(context.TODO(), obj.GetName(), v1.DeleteOptions{DryRun: dryRun}); err != nil {
 			logger.Error("Failed to delete resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()), zap.Error(err))
 			return finalNamespace, nil, err
 		}
 		logger.Info("Resource deleted successfully", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
 	case common.Update:
-		logger.Info("UPDATE resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
+		logger.Info("UPDATE resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()), zap.Bool("dryRun", res.DryRun))
 		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 			// ApplyPatchType means server side apply
-			if resp, err := dr.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, v1.PatchOptions{FieldManager: common.APP_NAME}); err == nil {
+			if resp, err := dr.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, v1.PatchOptions{FieldManager: common.APP_NAME, DryRun: dryRun, Force: force}); err == nil {
 				finalResp = resp
 			} else {
 				logger.Error("Failed to update resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
@@ -360,16 +405,51 @@ func (k *K8sClient) NewRestMapper() {
 	k.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(k.discoveryClient))
 }
 
+// RetrieveMapping maps kind to its resource. With retry the discovery is
+// refreshed once if the kind is not found, e.g. its crd was just created.
 func (k *K8sClient) RetrieveMapping(kind schema.GroupKind, version string, retry bool) (*meta.RESTMapping, error) {
 	mapping, err := k.mapper.RESTMapping(kind, version)
 	if err != nil && retry {
 		logger.Info("Retry retrieving mapping", zap.String("err", err.Error()))
-		k.NewRestMapper()
+		k.InvalidateDiscovery()
 		return k.RetrieveMapping(kind, version, false)
 	}
 	return mapping, err
 }
 
+// Impersonate returns a client acting as the given k8s user and groups.
+// Discovery is shared with k, only calls on resources are made as the user.
+func (k *K8sClient) Impersonate(user string, groups []string) *K8sClient {
+	if k.config == nil {
+		return k
+	}
+	cfg := rest.CopyConfig(k.config)
+	cfg.Impersonate = rest.ImpersonationConfig{
+		UserName: user,
+		Groups:   groups,
+	}
+	userClient := &K8sClient{
+		config:          cfg,
+		discoveryClient: k.discoveryClient,
+		mapper:          k.mapper,
+		setupErr:        k.setupErr,
+		clusterInfo:     k.clusterInfo,
+		generator:       k.generator,
+		context:         k.context,
+	}
+	if dyn, err := dynamic.NewForConfig(cfg); err == nil {
+		userClient.dynClient = dyn
+	} else {
+		userClient.setupErr = err.Error()
+	}
+	if k.informers != nil {
+		// the user's own informers, so only what the user can see is cached
+		userClient.EnableInformerCache(k.informers.idleTimeout)
+	}
+	logger.Info("created impersonating client", zap.String("user", user), zap.Strings("groups", groups))
+	return userClient
+}
+
 func (k *K8sClient) IsValid() bool {
 	return k.setupErr == ""
 }
@@ -407,9 +487,43 @@ func (k *K8sClient) SetupClients() {
 	}
 }
 
+// PodLogOptions selects which part of a container log is read
+type PodLogOptions struct {
+	// keep streaming new lines until the reader is closed
+	Follow bool
+	// only the last lines if > 0
+	TailLines int64
+	// only the lines newer than this many seconds if > 0, otherwise newer than SinceTime if set
+	SinceSeconds int64
+	SinceTime    *time.Time
+	Timestamps   bool
+	// the log of the previous instance of the container, e.g. before it crashed
+	Previous bool
+}
+
+func (o PodLogOptions) toPodLogOptions(container string) *corev1.PodLogOptions {
+	podLogOpts := &corev1.PodLogOptions{
+		Container:  container,
+		Follow:     o.Follow,
+		Timestamps: o.Timestamps,
+		Previous:   o.Previous,
+	}
+	if o.TailLines > 0 {
+		podLogOpts.TailLines = &o.TailLines
+	}
+	if o.SinceSeconds > 0 {
+		podLogOpts.SinceSeconds = &o.SinceSeconds
+	} else if o.SinceTime != nil {
+		since := v1.NewTime(*o.SinceTime)
+		podLogOpts.SinceTime = &since
+	}
+	return podLogOpts
+}
+
 // Get log for a single container of a pod
 // Note: callers are responsible for closing the io.ReadCloser
-func (k *K8sClient) GetPodLog(podRaw *unstructured.Unstructured, container string) (io.ReadCloser, error) {
+// The log is streamed until ctx is done or the reader is closed
+func (k *K8sClient) GetPodLog(ctx context.Context, podRaw *unstructured.Unstructured, container string, opts PodLogOptions) (io.ReadCloser, error) {
 	if k.IsValid() {
 
 		pod := &corev1.Pod{}
@@ -425,34 +539,288 @@ func (k *K8sClient) GetPodLog(podRaw *unstructured.Unstructured, container strin
 		if err != nil {
 			return nil, fmt.Errorf("error in getting access to K8S")
 		}
-		podLogOpts := corev1.PodLogOptions{
-			Container: container,
-		}
-		req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts)
+		req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts.toPodLogOptions(container))
 
-		podLogs, err := req.Stream(context.TODO())
+		podLogs, err := req.Stream(ctx)
 		if err != nil {
 			return nil, err
 		}
 
 		return podLogs, nil
 
-		// buf := new(bytes.Buffer)
-		// _, err = io.Copy(buf, podLogs)
-		// if err != nil {
-		// 	return "error in copy information from podLogs to buf"
-		// }
-		// str := buf.String()
-		// return str
-
 	} else {
 		return nil, fmt.Errorf("not connected")
 	}
 }
 
-func InitInternalK8sClient(configPath *string) {
-	logger.Info("Init local k8sclient", zap.String("config", *configPath))
-	config, err := clientcmd.BuildConfigFromFlags("", *configPath)
+// ExecOptions describes a command run in a container. A nil Stdin means
+// no input, with Tty set stderr is merged into Stdout.
+type ExecOptions struct {
+	Container string
+	Command   []string
+	Tty       bool
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	// terminal size changes, closed when the session ends. Only used with Tty
+	Resize <-chan remotecommand.TerminalSize
+}
+
+// terminalSizeQueue feeds the resize events to the executor
+type terminalSizeQueue <-chan remotecommand.TerminalSize
+
+func (q terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+// newExecutor uses websocket and falls back to spdy if the api server can't upgrade to it
+var newExecutor = func(config *rest.Config, execUrl *url.URL) (remotecommand.Executor, error) {
+	spdyExec, err := remotecommand.NewSPDYExecutor(config, "POST", execUrl)
+	if err != nil {
+		return nil, err
+	}
+	wsExec, err := remotecommand.NewWebSocketExecutor(config, "GET", execUrl.String())
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(wsExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
+
+// ExecPod runs a command in a container of the pod until it exits or ctx is done.
+func (k *K8sClient) ExecPod(ctx context.Context, podRaw *unstructured.Unstructured, opts ExecOptions) error {
+	if !k.IsValid() {
+		return fmt.Errorf("not connected")
+	}
+	clientset, err := kubernetes.NewForConfig(k.config)
+	if err != nil {
+		return fmt.Errorf("error in getting access to K8S")
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podRaw.GetName()).
+		Namespace(podRaw.GetNamespace()).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: opts.Container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.Tty,
+			TTY:       opts.Tty,
+		}, scheme.ParameterCodec)
+
+	executor, err := newExecutor(k.config, req.URL())
+	if err != nil {
+		return err
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Tty:    opts.Tty,
+	}
+	if !opts.Tty {
+		streamOpts.Stderr = opts.Stderr
+	}
+	if opts.Tty && opts.Resize != nil {
+		streamOpts.TerminalSizeQueue = terminalSizeQueue(opts.Resize)
+	}
+	return executor.StreamWithContext(ctx, streamOpts)
+}
+
+// newPortForwardDialer uses spdy over websocket and falls back to spdy if the api server can't upgrade to it
+var newPortForwardDialer = func(config *rest.Config, pfUrl *url.URL) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	spdyDialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", pfUrl)
+	wsDialer, err := portforward.NewSPDYOverWebsocketDialer(pfUrl, config)
+	if err != nil {
+		return nil, err
+	}
+	return portforward.NewFallbackDialer(wsDialer, spdyDialer, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	}), nil
+}
+
+// podPortConn is a connection to a port of a pod, over a streaming connection of its own
+type podPortConn struct {
+	name      string
+	conn      httpstream.Connection
+	data      httpstream.Stream
+	errors    <-chan error
+	closeOnce sync.Once
+}
+
+func (c *podPortConn) Read(p []byte) (int, error) {
+	return c.data.Read(p)
+}
+
+func (c *podPortConn) Write(p []byte) (int, error) {
+	return c.data.Write(p)
+}
+
+// CloseWrite tells the pod no more data is sent
+func (c *podPortConn) CloseWrite() error {
+	return c.data.Close()
+}
+
+func (c *podPortConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		// discards unsent data, otherwise the error stream may never be closed
+		c.data.Reset()
+		if streamErr := <-c.errors; streamErr != nil {
+			logger.Info("error forwarding port", zap.String("target", c.name), zap.Error(streamErr))
+		}
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// dialPodPort opens a connection to the port of the pod
+var dialPodPort = func(k *K8sClient, namespace string, pod string, port int32) (io.ReadWriteCloser, error) {
+	clientset, err := kubernetes.NewForConfig(k.config)
+	if err != nil {
+		return nil, fmt.Errorf("error in getting access to K8S")
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward")
+
+	dialer, err := newPortForwardDialer(k.config, req.URL())
+	if err != nil {
+		return nil, err
+	}
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to pod %s/%s: %w", namespace, pod, err)
+	}
+
+	// one request per connection
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(int(port)))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		streamConn.Close()
+		return nil, err
+	}
+	// we're not writing to this stream
+	errorStream.Close()
+
+	errors := make(chan error, 1)
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errors <- err
+		case len(message) > 0:
+			errors <- fmt.Errorf("%s", message)
+		}
+		close(errors)
+	}()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		streamConn.Close()
+		return nil, err
+	}
+
+	return &podPortConn{
+		name:   fmt.Sprintf("%s/%s:%d", namespace, pod, port),
+		conn:   streamConn,
+		data:   dataStream,
+		errors: errors,
+	}, nil
+}
+
+// resolvePortForward returns the pod and its port a forward to the target connects to.
+// A service is resolved to the first of its running pods.
+func (k *K8sClient) resolvePortForward(ctx context.Context, target PortForwardTarget) (string, int32, error) {
+	switch target.Kind {
+	case "Pod":
+		return target.Name, target.Port, nil
+	case "Service":
+	default:
+		return "", 0, fmt.Errorf("can't forward to a %s", target.Kind)
+	}
+
+	svcRaw, err := k.dynClient.Resource(servicesGvr).Namespace(target.Namespace).Get(ctx, target.Name, v1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	svc := &corev1.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(svcRaw.Object, svc); err != nil {
+		return "", 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s has no selector", target.Name)
+	}
+
+	pods, err := k.dynClient.Resource(podsGvr).Namespace(target.Namespace).List(ctx, v1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	for _, item := range pods.Items {
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, pod); err != nil {
+			return "", 0, err
+		}
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		port, err := kubectlutil.LookupContainerPortNumberByServicePort(*svc, *pod, target.Port)
+		if err != nil {
+			return "", 0, err
+		}
+		return pod.Name, port, nil
+	}
+	return "", 0, fmt.Errorf("no running pod for service %s", target.Name)
+}
+
+// DialPortForward connects to the port of the target. A service is resolved
+// on each call so the connections follow its pods when they are replaced.
+func (k *K8sClient) DialPortForward(ctx context.Context, target PortForwardTarget) (io.ReadWriteCloser, error) {
+	if !k.IsValid() {
+		return nil, fmt.Errorf("not connected")
+	}
+	pod, port, err := k.resolvePortForward(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return dialPodPort(k, target.Namespace, pod, port)
+}
+
+// NewK8sClient creates a client from the given context of the kubeconfig.
+// An empty context means the current context of the kubeconfig.
+func NewK8sClient(configPath string, kubeContext string) *K8sClient {
+	logger.Info("Init local k8sclient", zap.String("config", configPath), zap.String("context", kubeContext))
+	var config *rest.Config
+	var err error
+	if configPath == "" {
+		// falls back to the in-cluster config
+		config, err = clientcmd.BuildConfigFromFlags("", "")
+	} else {
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: configPath},
+			&clientcmd.ConfigOverrides{CurrentContext: kubeContext}).ClientConfig()
+	}
 	setupErr := ""
 
 	if err != nil {
@@ -461,12 +829,35 @@ func InitInternalK8sClient(configPath *string) {
 		setupErr = err.Error()
 	}
 
-	internalClient = &K8sClient{
+	client := &K8sClient{
 		config:   config,
 		setupErr: setupErr,
+		context:  kubeContext,
 	}
 
-	internalClient.SetupClients()
+	client.SetupClients()
+	return client
+}
+
+func InitInternalK8sClient(configPath *string) {
+	setInternalClient(NewK8sClient(*configPath, options.Options.Context))
+}
+
+// ListKubeContexts returns the sorted context names of the kubeconfig
+// and its current context
+func ListKubeContexts(configPath string) ([]string, string, error) {
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: configPath},
+		&clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	contexts := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	slices.Sort(contexts)
+	return contexts, rawConfig.CurrentContext, nil
 }
 
 func ToApiVer(userInput string) (string, error) {
@@ -565,14 +956,21 @@ func NewInstance(apiVer string, name string, order int) (*common.ResourceInstanc
 	return nil, fmt.Errorf("no such resource %v", apiVer)
 }
 
-func (k *K8sClient) FetchGVRInstances(g string, v string, r string, ns string) (*unstructured.UnstructuredList, error) {
+// FetchGVRInstances lists the instances of a resource. Use opts.Limit and
+// opts.Continue to page through big lists.
+func (k *K8sClient) FetchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
 	if k.IsValid() {
 		gvr := schema.GroupVersionResource{
 			Group:    g,
 			Version:  v,
 			Resource: r,
 		}
-		instList, err := k.dynClient.Resource(gvr).Namespace(ns).List(context.TODO(), v1.ListOptions{})
+		if k.informers != nil {
+			if instList, ok := k.informers.List(gvr, ns, opts); ok {
+				return instList, nil
+			}
+		}
+		instList, err := k.dynClient.Resource(gvr).Namespace(ns).List(context.TODO(), opts)
 		if err != nil {
 			return nil, err
 		}
@@ -581,10 +979,30 @@ func (k *K8sClient) FetchGVRInstances(g string, v string, r string, ns string) (
 	return nil, fmt.Errorf("cluster not connected")
 }
 
+// WatchGVRInstances watches the instances of a resource starting from opts.ResourceVersion
+// (usually taken from the list). Callers must Stop() the returned watch.
+func (k *K8sClient) WatchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (watch.Interface, error) {
+	if k.IsValid() {
+		gvr := schema.GroupVersionResource{
+			Group:    g,
+			Version:  v,
+			Resource: r,
+		}
+		if k.informers != nil {
+			if w, ok := k.informers.Watch(gvr, ns, opts); ok {
+				return w, nil
+			}
+		}
+		opts.Watch = true
+		return k.dynClient.Resource(gvr).Namespace(ns).Watch(context.TODO(), opts)
+	}
+	return nil, fmt.Errorf("cluster not connected")
+}
+
 func (k *K8sClient) FetchAllNamespaces() ([]string, error) {
 	allNs := make([]string, 0)
 
-	nsList, err := k.FetchGVRInstances("", "v1", "namespaces", "")
+	nsList, err := k.FetchGVRInstances("", "v1", "namespaces", "", v1.ListOptions{})
 	if err != nil {
 		return allNs, err
 	}
@@ -607,6 +1025,9 @@ func GetPersister() DeploymentPersister {
 		logger.Info("Deployment is disabled because no valid cluster available")
 		return &DummyPersister{}
 	}
+	if persister := clusterPersister(); persister != nil {
+		return persister
+	}
 
 	clustersDir := filepath.Join(cfgDir, "clusters")
 	basePath := filepath.Join(clustersDir, GetK8sService().GetClusterName())
@@ -666,6 +1087,34 @@ func GetPersister() DeploymentPersister {
 	return persister
 }
 
+// clusterPersister returns the persister keeping the deployments in the
+// cluster if the config of the cluster asks for it, nil otherwise
+func clusterPersister() DeploymentPersister {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		logger.Warn("Cannot read the config", zap.Error(err))
+		return nil
+	}
+	info := GetK8sService().GetClusterInfo()
+	if info == nil {
+		return nil
+	}
+	clusterCfg := cfg.GetClusterConfig(info.Id, info.Host)
+	if clusterCfg == nil {
+		return nil
+	}
+	switch store := clusterCfg.Deployments.Store; store {
+	case config.DEPLOYMENTS_STORE_CONFIGMAP, config.DEPLOYMENTS_STORE_SECRET:
+		logger.Info("Deployments are kept in the cluster", zap.String("store", store), zap.String("namespace", clusterCfg.Deployments.Namespace))
+		return NewClusterDeploymentPersister(GetK8sService(), store, clusterCfg.Deployments.Namespace)
+	case "", config.DEPLOYMENTS_STORE_FILE:
+		return nil
+	default:
+		logger.Warn("Unknown deployments store, using the file", zap.String("store", store))
+		return nil
+	}
+}
+
 type FileDeploymentPersister struct {
 	FilePath string
 	cache    []*DeployDetail
@@ -809,12 +1258,22 @@ type DeployedResources struct {
 	resIds    map[string]*DeployDetail
 	list      []*DeployDetail
 	persister DeploymentPersister
+	drift     driftReports
 }
 
 func (d *DeployedResources) GetPersister() DeploymentPersister {
 	return d.persister
 }
 
+// Reset drops the deployments and re-points the persister to the
+// current cluster, e.g. after switching the kubeconfig context
+func (d *DeployedResources) Reset() {
+	d.resIds = make(map[string]*DeployDetail)
+	d.list = nil
+	d.persister = GetPersister()
+	d.drift.clear()
+}
+
 func (d *DeployedResources) GetSelectedDeployments() []*DeployDetail {
 	deps := make([]*DeployDetail, 0)
 	for _, itm := range d.list {
@@ -834,10 +1293,25 @@ func (d *DeployedResources) AnySelected() bool {
 	return false
 }
 
+// InDeploy tells whether any deployment is being deployed
+func (d *DeployedResources) InDeploy() bool {
+	for _, itm := range d.list {
+		if itm.Status == common.StateInDeploy {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *DeployedResources) Get(index int) *DeployDetail {
 	return d.list[index]
 }
 
+// All returns the deployments in the order they are listed
+func (d *DeployedResources) All() []*DeployDetail {
+	return slices.Clone(d.list)
+}
+
 func (d *DeployedResources) Size() int {
 	return len(d.resIds)
 }
@@ -859,6 +1333,16 @@ func (d *DeployedResources) LockAndAdd(resNode common.INode) (map[string]*common
 	return actions, err
 }
 
+// PendingActions returns the actions a deploy of resNode would take,
+// the deployed resources are not changed
+func (d *DeployedResources) PendingActions(resNode common.INode) (map[string]*common.ResourceInstanceAction, error) {
+	newDetail := NewDeployDetail(resNode)
+	if dd, exists := d.resIds[resNode.GetId()]; exists && len(dd.AllInstances) > 0 {
+		return dd.diffActions(newDetail)
+	}
+	return newDetail.ParseResources()
+}
+
 func (d *DeployedResources) AddDetail(dd *DeployDetail, persist bool) {
 	d.resIds[dd.Id] = dd
 	d.list = append(d.list, dd)
@@ -884,6 +1368,9 @@ func (d *DeployedResources) Deployed(resId string, finalNs map[string]types.Name
 	d.resIds[resId].Status = common.StateDeployed
 	d.resIds[resId].Namespace = common.MapToKeysString(finalNs)
 	d.resIds[resId].SetFinalNs(finalNs)
+	// they were of the previous deploy
+	d.resIds[resId].Health = nil
+	d.resIds[resId].Rollback = nil
 	d.persister.Update()
 }
 
@@ -914,8 +1401,15 @@ type DeployDetail struct {
 	Namespace string `yaml:"namespace,omitempty"`
 	ApiVer    string `yaml:"apiVer,omitempty"`
 
-	Status      common.DeployState `yaml:"status,omitempty"`
-	Creation    string             `yaml:"creation,omitempty"`
+	Status   common.DeployState `yaml:"status,omitempty"`
+	Creation string             `yaml:"creation,omitempty"`
+	// the health of the instances after the last deploy, if it waited for them
+	Health map[string]*ResourceHealth `yaml:"health,omitempty"`
+	// the report of the last deploy if it was rolled back
+	Rollback *RollbackReport `yaml:"rollback,omitempty"`
+	// the last MAX_REVISIONS deploys, the latest last
+	Revisions []*DeployRevision `yaml:"revisions,omitempty"`
+
 	checkStatus widget.Bool
 	btn         widget.Clickable
 }
@@ -956,10 +1450,22 @@ func (d *DeployDetail) GetCheckStatus() *widget.Bool {
 }
 
 func (d *DeployDetail) Merge(newDeploy *DeployDetail) error {
-	resActions, err := newDeploy.ParseResources()
+	resActions, err := d.diffActions(newDeploy)
 	if err != nil {
 		return err
 	}
+	//now swap
+	d.AllInstances = resActions
+	return nil
+}
+
+// diffActions returns the actions that bring the deployed resources to
+// those of newDeploy, d is not changed
+func (d *DeployDetail) diffActions(newDeploy *DeployDetail) (map[string]*common.ResourceInstanceAction, error) {
+	resActions, err := newDeploy.ParseResources()
+	if err != nil {
+		return nil, err
+	}
 	for id, existingAct := range d.AllInstances {
 		if newAct, ok := resActions[id]; ok {
 			oldCr := d.OriginalCrs[id]
@@ -972,13 +1478,12 @@ func (d *DeployDetail) Merge(newDeploy *DeployDetail) error {
 			}
 		} else {
 			//delete the resource
-			existingAct.SetAction(common.Delete)
-			resActions[id] = existingAct
+			deleteAct := *existingAct
+			deleteAct.SetAction(common.Delete)
+			resActions[id] = &deleteAct
 		}
 	}
-	//now swap
-	d.AllInstances = resActions
-	return nil
+	return resActions, nil
 }
 
 func (d *DeployDetail) ParseResources() (map[string]*common.ResourceInstanceAction, error) {
@@ -1,80 +1,217 @@
 package k8sservice
 
-import "time"
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
 
 var DEFAULT_CACHE_TIMEOUT = 5 * time.Second
 
-type CacheValue struct {
-	value     any
-	timestamp time.Time
-	timeout   time.Duration
+// the entries kept at most, the least recently used go first
+var DEFAULT_CACHE_SIZE = 1000
+
+// CacheStats tells how well the cache serves
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	// the loads done for the misses, the other misses waited for a load in flight
+	Loads     int64
+	Evictions int64
+	Size      int
+	Capacity  int
 }
 
-func newCacheValue(value any, ownTimeout time.Duration) *CacheValue {
-	return &CacheValue{
-		value:     value,
-		timestamp: time.Now(),
-		timeout:   ownTimeout,
+// HitRatio is the share of the gets served from the cache
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
 	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("cache %.0f%% hits (%d/%d), %d loads, %d/%d entries",
+		s.HitRatio()*100, s.Hits, s.Hits+s.Misses, s.Loads, s.Size, s.Capacity)
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
 }
 
 // The cache is used by k8sService to
-// improve performance
+// improve performance. It is safe for concurrent use.
 type K8sClientCache struct {
-	cache      map[string]*CacheValue
+	lock       sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	capacity   int
 	defTimeout time.Duration
+	stats      CacheStats
+	group      singleflight.Group
+	// bumped by Clear and Reload, the loads started before are not cached
+	generation uint64
 }
 
 func NewK8sCache() *K8sClientCache {
+	return NewK8sCacheWithSize(DEFAULT_CACHE_SIZE)
+}
+
+func NewK8sCacheWithSize(capacity int) *K8sClientCache {
 	return &K8sClientCache{
-		cache:      make(map[string]*CacheValue),
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		capacity:   capacity,
 		defTimeout: DEFAULT_CACHE_TIMEOUT,
 	}
 }
 
-func (c *K8sClientCache) Put(key string, value any) {
-	cacheValue := newCacheValue(value, 0)
-	if key == CACHE_KEY_API_RESOURCES {
-		// hack. Need to change on api level to pass in specific timeout
-		// Put(key string, value any, timeout time.Duration)
-		cacheValue.timeout = 24 * time.Hour
+// Put caches value for ttl, the cache's default timeout if ttl is not positive
+func (c *K8sClientCache) Put(key string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defTimeout
 	}
-	c.cache[key] = cacheValue
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.put(key, value, ttl)
+}
 
+func (c *K8sClientCache) put(key string, value any, ttl time.Duration) {
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = time.Now().Add(ttl)
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(ttl),
+	})
+	for c.lru.Len() > c.capacity {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
 }
 
-func (c *K8sClientCache) IsTimedOut(value *CacheValue) bool {
-	if value == nil {
-		return true
+// Get returns the value of key if it is cached and not timed out
+func (c *K8sClientCache) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	elem, ok := c.entries[key]
+	if ok && time.Now().After(elem.Value.(*cacheEntry).expires) {
+		c.removeElement(elem)
+		ok = false
 	}
-	if value.timeout > 0 {
-		return time.Since(value.timestamp) > value.timeout
+	if !ok {
+		c.stats.Misses++
+		return nil, false
 	}
-	return time.Since(value.timestamp) > c.defTimeout
+	c.stats.Hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).value, true
 }
 
-// return nil means no cache, true means the cached value is outdated
-func (c *K8sClientCache) GetString(key string) (*string, bool) {
-	if cacheValue, ok := c.cache[key]; ok {
-		if value, ok := cacheValue.value.(string); ok {
-			return &value, c.IsTimedOut(cacheValue)
-		}
+// Remove drops the value of key
+func (c *K8sClientCache) Remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
 	}
-	return nil, true
 }
 
-func (c *K8sClientCache) GetObject(key string) (any, bool) {
-	if cacheValue, ok := c.cache[key]; ok {
-		return cacheValue.value, c.IsTimedOut(cacheValue)
+// Clear drops all values, the stats are kept. The loads in flight are
+// not cached when they finish and are not shared with later gets.
+func (c *K8sClientCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.generation++
+}
+
+func (c *K8sClientCache) currentGeneration() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generation
+}
+
+// putLoaded caches the value loaded in the generation, unless the cache
+// was cleared since
+func (c *K8sClientCache) putLoaded(key string, value any, ttl time.Duration, generation uint64) {
+	if ttl <= 0 {
+		ttl = c.defTimeout
 	}
-	return nil, true
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.generation == generation {
+		c.put(key, value, ttl)
+	}
+}
+
+func (c *K8sClientCache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+// Stats returns the counts since the cache was created
+func (c *K8sClientCache) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	stats.Capacity = c.capacity
+	return stats
 }
 
-func (c *K8sClientCache) GetBool(key string) (*bool, bool) {
-	if cacheValue, ok := c.cache[key]; ok {
-		if value, ok := cacheValue.value.(bool); ok {
-			return &value, c.IsTimedOut(cacheValue)
+// GetOrLoad returns the cached value of key, or loads and caches it for ttl.
+// Concurrent misses of a key share one load, a failed load is not cached,
+// nor is one that was in flight when the cache was cleared.
+func GetOrLoad[T any](c *K8sClientCache, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	if value, ok := c.Get(key); ok {
+		if typed, ok := value.(T); ok {
+			return typed, nil
 		}
 	}
-	return nil, true
+	return load(c, "", key, ttl, c.currentGeneration(), loader)
+}
+
+// Reload loads and caches the value of key even if it is cached. It does
+// not share the loads of GetOrLoad in flight, nor are those cached when
+// they finish, as they may be older.
+func Reload[T any](c *K8sClientCache, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	c.lock.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+	c.generation++
+	generation := c.generation
+	c.lock.Unlock()
+	return load(c, "reload|", key, ttl, generation, loader)
+}
+
+// load runs the loader once for the concurrent callers of the same flight
+// and caches the value if the cache is not cleared meanwhile
+func load[T any](c *K8sClientCache, flight string, key string, ttl time.Duration, generation uint64, loader func() (T, error)) (T, error) {
+	value, err, _ := c.group.Do(flight+strconv.FormatUint(generation, 10)+"|"+key, func() (any, error) {
+		c.lock.Lock()
+		c.stats.Loads++
+		c.lock.Unlock()
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		c.putLoaded(key, value, ttl, generation)
+		return value, nil
+	})
+	typed, _ := value.(T)
+	return typed, err
 }
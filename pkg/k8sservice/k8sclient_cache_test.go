@@ -0,0 +1,167 @@
+package k8sservice
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestK8sClientCacheLru(t *testing.T) {
+	cache := NewK8sCacheWithSize(2)
+	cache.Put("a", 1, time.Minute)
+	cache.Put("b", 2, time.Minute)
+	// a is the most recently used
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Fatalf("expected a=1, got %v %v", value, ok)
+	}
+	cache.Put("c", 3, time.Minute)
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("b should be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("a should be kept")
+	}
+
+	cache.Put("short", "x", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("short should be timed out")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Evictions != 2 || stats.Size != 1 || stats.Capacity != 2 {
+		t.Errorf("wrong stats %+v", stats)
+	}
+
+	cache.Clear()
+	if _, ok := cache.Get("a"); ok || cache.Stats().Size != 0 {
+		t.Errorf("cache not cleared")
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	cache := NewK8sCache()
+	release := make(chan struct{})
+	loads := atomic.Int32{}
+	load := func() (string, error) {
+		loads.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	const callers = 10
+	wg := sync.WaitGroup{}
+	results := make(chan string, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrLoad(cache, "key", time.Minute, load)
+			if err != nil {
+				t.Errorf("failed to load: %v", err)
+			}
+			results <- value
+		}()
+	}
+	// all callers miss before the load is done
+	for cache.Stats().Misses < callers {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+	for value := range results {
+		if value != "value" {
+			t.Errorf("wrong value %q", value)
+		}
+	}
+	if loads.Load() != 1 {
+		t.Errorf("expected one load, got %d", loads.Load())
+	}
+	if value, _ := GetOrLoad(cache, "key", time.Minute, load); value != "value" || loads.Load() != 1 {
+		t.Errorf("value not cached")
+	}
+
+	// failures are not cached
+	if _, err := GetOrLoad(cache, "failing", time.Minute, func() (int, error) {
+		return 0, fmt.Errorf("no connection")
+	}); err == nil {
+		t.Errorf("expected the load error")
+	}
+	if _, ok := cache.Get("failing"); ok {
+		t.Errorf("failed load cached")
+	}
+
+	stats := cache.Stats()
+	if stats.Loads != 2 || stats.Hits != 1 {
+		t.Errorf("wrong stats %+v", stats)
+	}
+}
+
+func TestGetOrLoadCleared(t *testing.T) {
+	cache := NewK8sCache()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	oldDone := make(chan string)
+	go func() {
+		value, _ := GetOrLoad(cache, "pods", time.Minute, func() (string, error) {
+			close(started)
+			<-release
+			return "old cluster", nil
+		})
+		oldDone <- value
+	}()
+	<-started
+
+	// switched to another cluster while the load is in flight
+	cache.Clear()
+	value, err := GetOrLoad(cache, "pods", time.Minute, func() (string, error) {
+		return "new cluster", nil
+	})
+	if err != nil || value != "new cluster" {
+		t.Errorf("joined the load of before the clear: %q %v", value, err)
+	}
+	close(release)
+	if value := <-oldDone; value != "old cluster" {
+		t.Errorf("wrong value of the old load %q", value)
+	}
+	if value, ok := cache.Get("pods"); !ok || value != "new cluster" {
+		t.Errorf("the load of before the clear was cached: %v", value)
+	}
+}
+
+func TestReload(t *testing.T) {
+	cache := NewK8sCache()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	oldDone := make(chan string)
+	go func() {
+		value, _ := GetOrLoad(cache, "api", time.Minute, func() (string, error) {
+			close(started)
+			<-release
+			return "old", nil
+		})
+		oldDone <- value
+	}()
+	<-started
+
+	// a forced load doesn't join the one in flight
+	value, err := Reload(cache, "api", time.Minute, func() (string, error) {
+		return "new", nil
+	})
+	if err != nil || value != "new" {
+		t.Errorf("joined the load in flight: %q %v", value, err)
+	}
+	close(release)
+	if value := <-oldDone; value != "old" {
+		t.Errorf("wrong value of the old load %q", value)
+	}
+	if value, ok := cache.Get("api"); !ok || value != "new" {
+		t.Errorf("the older load was cached: %v", value)
+	}
+	if value, _ := Reload(cache, "api", time.Minute, func() (string, error) { return "newer", nil }); value != "newer" {
+		t.Errorf("the cached value is not reloaded: %q", value)
+	}
+}
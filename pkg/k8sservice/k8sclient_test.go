@@ -0,0 +1,64 @@
+package k8sservice
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: admin
+  user:
+    token: test-token
+contexts:
+- name: dev-admin
+  context:
+    cluster: dev
+    user: admin
+- name: prod-admin
+  context:
+    cluster: prod
+    user: admin
+current-context: dev-admin
+`
+
+func TestKubeContexts(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	contexts, current, err := ListKubeContexts(configPath)
+	if err != nil {
+		t.Fatalf("failed to list contexts: %v", err)
+	}
+	if !slices.Equal(contexts, []string{"dev-admin", "prod-admin"}) || current != "dev-admin" {
+		t.Errorf("wrong contexts %v, current %v", contexts, current)
+	}
+
+	devClient := NewK8sClient(configPath, "")
+	prodClient := NewK8sClient(configPath, "prod-admin")
+	if !devClient.IsValid() || !prodClient.IsValid() {
+		t.Fatalf("clients not valid: %v %v", devClient.setupErr, prodClient.setupErr)
+	}
+	if prodClient.GetClusterInfo().Host != "https://prod.example.com:6443" {
+		t.Errorf("context not applied, host %v", prodClient.GetClusterInfo().Host)
+	}
+	if devClient.GetClusterName() == prodClient.GetClusterName() {
+		t.Errorf("clusters should have different ids")
+	}
+
+	if NewK8sClient(configPath, "missing").IsValid() {
+		t.Errorf("client of unknown context should not be valid")
+	}
+}
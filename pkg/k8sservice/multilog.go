@@ -0,0 +1,256 @@
+package k8sservice
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// LogLine is a line of an aggregated log and where it comes from
+type LogLine struct {
+	Pod       string
+	Container string
+	Text      string
+}
+
+// SelectorOf returns the label selector of the pods of a workload,
+// e.g. a Deployment, StatefulSet, Job or Service.
+func SelectorOf(workload *unstructured.Unstructured) (string, error) {
+	selector, found, _ := unstructured.NestedFieldNoCopy(workload.Object, "spec", "selector")
+	if !found {
+		return "", fmt.Errorf("%s %s has no pod selector", workload.GetKind(), workload.GetName())
+	}
+	fields, ok := selector.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("invalid selector of %s %s", workload.GetKind(), workload.GetName())
+	}
+
+	_, hasLabels := fields["matchLabels"]
+	_, hasExpressions := fields["matchExpressions"]
+	if hasLabels || hasExpressions {
+		labelSelector := &v1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, labelSelector); err != nil {
+			return "", err
+		}
+		parsed, err := v1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return "", err
+		}
+		return parsed.String(), nil
+	}
+
+	// a plain label map, like the selector of a Service
+	set := labels.Set{}
+	for key, value := range fields {
+		text, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("invalid selector of %s %s", workload.GetKind(), workload.GetName())
+		}
+		set[key] = text
+	}
+	if len(set) == 0 {
+		return "", fmt.Errorf("%s %s has an empty pod selector", workload.GetKind(), workload.GetName())
+	}
+	return set.AsSelector().String(), nil
+}
+
+// logTail is the reading of a container log
+type logTail struct {
+	cancel context.CancelFunc
+}
+
+// podLogAggregator tails the running containers of the pods it is told about
+type podLogAggregator struct {
+	service K8sService
+	opts    PodLogOptions
+	out     func(LogLine)
+
+	lock sync.Mutex
+	// the tails being read, keyed by pod/container
+	tails map[string]*logTail
+	// when the tail of a container ended, a new tail starts from there
+	ended map[string]time.Time
+	wg    sync.WaitGroup
+}
+
+func (a *podLogAggregator) podChanged(ctx context.Context, pod *unstructured.Unstructured) {
+	if pod.GetDeletionTimestamp() != nil {
+		a.podRemoved(pod.GetName())
+		return
+	}
+	statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		status, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		container, _, _ := unstructured.NestedString(status, "name")
+		if _, running, _ := unstructured.NestedMap(status, "state", "running"); !running {
+			continue
+		}
+		a.startTail(ctx, pod, container)
+	}
+}
+
+func (a *podLogAggregator) startTail(ctx context.Context, pod *unstructured.Unstructured, container string) {
+	key := pod.GetName() + "/" + container
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, ok := a.tails[key]; ok {
+		return
+	}
+	opts := a.opts
+	if endTime, ok := a.ended[key]; ok {
+		// restarted, only what is new since the last tail
+		opts.TailLines = 0
+		opts.SinceSeconds = 0
+		opts.SinceTime = &endTime
+	}
+	tailCtx, cancel := context.WithCancel(ctx)
+	t := &logTail{cancel: cancel}
+	a.tails[key] = t
+	a.wg.Add(1)
+	go a.tail(tailCtx, t, pod.DeepCopy(), container, key, opts)
+}
+
+func (a *podLogAggregator) tail(ctx context.Context, t *logTail, pod *unstructured.Unstructured, container string, key string, opts PodLogOptions) {
+	defer a.wg.Done()
+	defer t.cancel()
+
+	reader, err := a.service.GetPodLog(ctx, pod, container, opts)
+	if err != nil {
+		a.out(LogLine{Pod: pod.GetName(), Container: container, Text: "- failed to read log: " + err.Error()})
+	} else {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			a.out(LogLine{Pod: pod.GetName(), Container: container, Text: scanner.Text()})
+		}
+		reader.Close()
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.tails[key] == t {
+		// the container stopped, it is tailed again if it is restarted
+		delete(a.tails, key)
+		a.ended[key] = time.Now()
+	}
+}
+
+func (a *podLogAggregator) podRemoved(name string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for key, t := range a.tails {
+		if pod, _, _ := strings.Cut(key, "/"); pod == name {
+			t.cancel()
+			delete(a.tails, key)
+		}
+	}
+	for key := range a.ended {
+		if pod, _, _ := strings.Cut(key, "/"); pod == name {
+			delete(a.ended, key)
+		}
+	}
+}
+
+// keepOnly stops the tails of the pods not in names
+func (a *podLogAggregator) keepOnly(names map[string]bool) {
+	a.lock.Lock()
+	removed := make([]string, 0)
+	for key := range a.tails {
+		if pod, _, _ := strings.Cut(key, "/"); !names[pod] {
+			removed = append(removed, pod)
+		}
+	}
+	a.lock.Unlock()
+	for _, pod := range removed {
+		a.podRemoved(pod)
+	}
+}
+
+// watch handles the pod events until the watch ends or ctx is done
+func (a *podLogAggregator) watch(ctx context.Context, w watch.Interface) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			pod, isPod := event.Object.(*unstructured.Unstructured)
+			switch {
+			case event.Type == watch.Error:
+				logger.Info("pod watch failed", zap.Any("status", event.Object))
+				return
+			case !isPod:
+			case event.Type == watch.Deleted:
+				a.podRemoved(pod.GetName())
+			case event.Type == watch.Added || event.Type == watch.Modified:
+				a.podChanged(ctx, pod)
+			}
+		}
+	}
+}
+
+// StreamPodLogs follows the logs of all running containers of the pods matching
+// the selector, new pods and restarted containers are picked up as they come.
+// It lasts until ctx is done, out is called from several goroutines.
+func StreamPodLogs(ctx context.Context, service K8sService, namespace string, selector string, opts PodLogOptions, out func(LogLine)) error {
+	opts.Follow = true
+	opts.Previous = false
+	agg := &podLogAggregator{
+		service: service,
+		opts:    opts,
+		out:     out,
+		tails:   make(map[string]*logTail),
+		ended:   make(map[string]time.Time),
+	}
+	// no lines after it returns
+	defer agg.wg.Wait()
+
+	for ctx.Err() == nil {
+		// the watch starts from the version of the list, a cached list
+		// could be too old for it or miss the pods changed meanwhile
+		list, err := service.FetchGVRInstancesUncached("", "v1", "pods", namespace, v1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return err
+		}
+		names := make(map[string]bool)
+		for i := range list.Items {
+			names[list.Items[i].GetName()] = true
+			agg.podChanged(ctx, &list.Items[i])
+		}
+		// the pods gone while not watching
+		agg.keepOnly(names)
+
+		w, err := service.WatchGVRInstances("", "v1", "pods", namespace, v1.ListOptions{
+			LabelSelector:   selector,
+			ResourceVersion: list.GetResourceVersion(),
+		})
+		if err != nil {
+			return err
+		}
+		agg.watch(ctx, w)
+
+		// the watch expired, list again after a while
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+	return nil
+}
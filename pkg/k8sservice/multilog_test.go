@@ -0,0 +1,194 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestAppPod(name string, app string, running []string, waiting []string) *unstructured.Unstructured {
+	pod := newTestPod("default", name)
+	pod.SetLabels(map[string]string{"app": app})
+	statuses := make([]any, 0)
+	for _, c := range running {
+		statuses = append(statuses, map[string]any{"name": c, "state": map[string]any{"running": map[string]any{}}})
+	}
+	for _, c := range waiting {
+		statuses = append(statuses, map[string]any{"name": c, "state": map[string]any{"waiting": map[string]any{}}})
+	}
+	pod.Object["status"] = map[string]any{"containerStatuses": statuses}
+	return pod
+}
+
+func TestSelectorOf(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]any{
+		"kind":     "Deployment",
+		"metadata": map[string]any{"name": "web"},
+		"spec": map[string]any{"selector": map[string]any{
+			"matchLabels": map[string]any{"app": "web"},
+			"matchExpressions": []any{map[string]any{
+				"key": "tier", "operator": "In", "values": []any{"front", "edge"},
+			}},
+		}},
+	}}
+	if selector, err := SelectorOf(deployment); err != nil || selector != "app=web,tier in (edge,front)" {
+		t.Errorf("wrong deployment selector %q, %v", selector, err)
+	}
+
+	service := newTestService("default", "web", map[string]any{"app": "web", "tier": "front"}, 80, int64(8080))
+	if selector, err := SelectorOf(service); err != nil || selector != "app=web,tier=front" {
+		t.Errorf("wrong service selector %q, %v", selector, err)
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]any{"kind": "ConfigMap"}}
+	if _, err := SelectorOf(configMap); err == nil {
+		t.Errorf("a config map has no pods")
+	}
+}
+
+func TestStreamPodLogs(t *testing.T) {
+	// greets with the container then follows until the caller is gone
+	gone := make(chan string, 10)
+	logServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pod := strings.Split(r.URL.Path, "/")[6]
+		source := pod + "/" + r.URL.Query().Get("container")
+		fmt.Fprintf(w, "hello from %s\n", source)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		gone <- source
+	}))
+	t.Cleanup(logServer.Close)
+
+	dynClient := newTestDynClient(
+		newTestAppPod("web-1", "web", []string{"a"}, []string{"b"}),
+		newTestAppPod("db-1", "db", []string{"a"}, nil),
+	)
+	fakeWatch := watch.NewFake()
+	dynClient.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, fakeWatch, nil
+	})
+	remote := startTestAgent(t, &server{
+		client:      &K8sClient{config: &rest.Config{Host: logServer.URL}, dynClient: dynClient},
+		userClients: make(map[string]*K8sClient),
+	})
+
+	lines := make(chan LogLine, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- StreamPodLogs(ctx, remote, "default", "app=web", PodLogOptions{TailLines: 10}, func(line LogLine) {
+			lines <- line
+		})
+	}()
+
+	nextLine := func(expected LogLine) {
+		t.Helper()
+		select {
+		case line := <-lines:
+			if line != expected {
+				t.Errorf("expected %v, got %v", expected, line)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("no line, expected %v", expected)
+		}
+	}
+	nextGone := func(expected string) {
+		t.Helper()
+		select {
+		case source := <-gone:
+			if source != expected {
+				t.Errorf("expected %v to be stopped, got %v", expected, source)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%v not stopped", expected)
+		}
+	}
+
+	nextLine(LogLine{Pod: "web-1", Container: "a", Text: "hello from web-1/a"})
+
+	// a new pod, its container starts later
+	fakeWatch.Add(newTestAppPod("web-2", "web", nil, []string{"c"}))
+	fakeWatch.Modify(newTestAppPod("web-2", "web", []string{"c"}, nil))
+	nextLine(LogLine{Pod: "web-2", Container: "c", Text: "hello from web-2/c"})
+
+	fakeWatch.Delete(newTestAppPod("web-1", "web", []string{"a"}, []string{"b"}))
+	nextGone("web-1/a")
+
+	cancel()
+	nextGone("web-2/c")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("stream failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("stream not ended")
+	}
+	if len(lines) != 0 {
+		t.Errorf("unexpected line %v", <-lines)
+	}
+}
+
+func TestStreamPodLogsRelist(t *testing.T) {
+	gone := make(chan string, 10)
+	logServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pod := strings.Split(r.URL.Path, "/")[6]
+		fmt.Fprintf(w, "hello from %s\n", pod)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		gone <- pod
+	}))
+	t.Cleanup(logServer.Close)
+
+	dynClient := newTestDynClient(newTestAppPod("web-1", "web", []string{"a"}, nil))
+	expiring := watch.NewFakeWithChanSize(1, false)
+	watches := []watch.Interface{expiring, watch.NewFake()}
+	dynClient.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watches[0]
+		if len(watches) > 1 {
+			watches = watches[1:]
+		}
+		return true, w, nil
+	})
+	remote := startTestAgent(t, &server{
+		client:      &K8sClient{config: &rest.Config{Host: logServer.URL}, dynClient: dynClient},
+		userClients: make(map[string]*K8sClient),
+	})
+
+	lines := make(chan LogLine, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go StreamPodLogs(ctx, remote, "default", "app=web", PodLogOptions{}, func(line LogLine) {
+		lines <- line
+	})
+	select {
+	case <-lines:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("no line of web-1")
+	}
+
+	// web-1 goes while the watch is expired, the relist must not come from
+	// the cache of the first list
+	if err := dynClient.Resource(podsGvr).Namespace("default").Delete(ctx, "web-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	expiring.Error(&metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired})
+	select {
+	case pod := <-gone:
+		if pod != "web-1" {
+			t.Errorf("expected web-1 to be stopped, got %s", pod)
+		}
+	case <-time.After(DEFAULT_CACHE_TIMEOUT / 2):
+		t.Fatalf("web-1 not stopped after the relist")
+	}
+}
@@ -0,0 +1,121 @@
+package k8sservice
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// newTestLogServer serves the log of default/pod1 with one line per query
+// parameter, a followed log then keeps sending lines until the caller is gone.
+func newTestLogServer(t *testing.T) (*httptest.Server, <-chan url.Values, <-chan struct{}) {
+	queries := make(chan url.Values, 10)
+	gone := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/default/pods/pod1/log" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		queries <- query
+		for key := range query {
+			fmt.Fprintf(w, "%s=%s\n", key, query.Get(key))
+		}
+		w.(http.Flusher).Flush()
+		if query.Get("follow") != "true" {
+			return
+		}
+		for i := 0; ; i++ {
+			select {
+			case <-r.Context().Done():
+				gone <- struct{}{}
+				return
+			case <-time.After(10 * time.Millisecond):
+				fmt.Fprintf(w, "line%d\n", i)
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, queries, gone
+}
+
+func TestRemotePodLog(t *testing.T) {
+	logServer, queries, gone := newTestLogServer(t)
+	remote := startTestAgent(t, &server{
+		client:      &K8sClient{config: &rest.Config{Host: logServer.URL}},
+		userClients: make(map[string]*K8sClient),
+	})
+	pod := newTestPod("default", "pod1")
+
+	t.Run("options", func(t *testing.T) {
+		since := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		reader, err := remote.GetPodLog(context.Background(), pod, "main", PodLogOptions{
+			TailLines:  10,
+			SinceTime:  &since,
+			Timestamps: true,
+			Previous:   true,
+		})
+		if err != nil {
+			t.Fatalf("failed to get log: %v", err)
+		}
+		defer reader.Close()
+		scanner := bufio.NewScanner(reader)
+		lines := 0
+		for scanner.Scan() {
+			lines++
+		}
+		query := <-queries
+		expected := map[string]string{
+			"container":  "main",
+			"tailLines":  "10",
+			"sinceTime":  "2024-05-01T10:00:00Z",
+			"timestamps": "true",
+			"previous":   "true",
+		}
+		for key, value := range expected {
+			if query.Get(key) != value {
+				t.Errorf("wrong %s %q", key, query.Get(key))
+			}
+		}
+		if query.Has("follow") || query.Has("sinceSeconds") {
+			t.Errorf("unexpected options %v", query)
+		}
+		if lines != len(query) {
+			t.Errorf("read %d lines, expected %d", lines, len(query))
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		reader, err := remote.GetPodLog(context.Background(), pod, "main", PodLogOptions{
+			Follow:       true,
+			SinceSeconds: 60,
+		})
+		if err != nil {
+			t.Fatalf("failed to get log: %v", err)
+		}
+		query := <-queries
+		if query.Get("follow") != "true" || query.Get("sinceSeconds") != "60" {
+			t.Errorf("wrong options %v", query)
+		}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if scanner.Text() == "line2" {
+				break
+			}
+		}
+		reader.Close()
+		select {
+		case <-gone:
+		case <-time.After(10 * time.Second):
+			t.Errorf("log still followed after the reader is closed")
+		}
+	})
+}
@@ -0,0 +1,400 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var podsGvr = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+var servicesGvr = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+
+// PortForwardTarget is the port of a pod or service a forward connects to
+type PortForwardTarget struct {
+	Namespace string
+	// Pod or Service
+	Kind string
+	Name string
+	Port int32
+}
+
+func (t PortForwardTarget) String() string {
+	return fmt.Sprintf("%s/%s/%s:%d", strings.ToLower(t.Kind), t.Namespace, t.Name, t.Port)
+}
+
+// PortForwardSpec asks for a local port forwarded to the target,
+// a zero LocalPort picks a free one.
+type PortForwardSpec struct {
+	Target    PortForwardTarget
+	LocalPort int
+}
+
+// PortForward is a forward listening on a local address
+type PortForward struct {
+	Id           string
+	Target       PortForwardTarget
+	LocalAddress string
+	// the connections being forwarded
+	Connections int
+	// why the last connection couldn't be forwarded
+	LastError string
+}
+
+// portDialer connects to the target of a forward
+type portDialer func(ctx context.Context, target PortForwardTarget) (io.ReadWriteCloser, error)
+
+type activeForward struct {
+	seq      int
+	info     PortForward
+	listener net.Listener
+	cancel   context.CancelFunc
+}
+
+// PortForwardManager owns the local listeners of the forwards,
+// each accepted connection is dialed to the target on its own.
+type PortForwardManager struct {
+	lock     sync.Mutex
+	dial     portDialer
+	nextSeq  int
+	forwards map[string]*activeForward
+}
+
+func NewPortForwardManager(dial portDialer) *PortForwardManager {
+	return &PortForwardManager{
+		dial:     dial,
+		forwards: make(map[string]*activeForward),
+	}
+}
+
+// Start listens on the local port, connections to it are forwarded until Stop
+func (m *PortForwardManager) Start(spec PortForwardSpec) (*PortForward, error) {
+	if spec.Target.Name == "" || spec.Target.Port <= 0 {
+		return nil, fmt.Errorf("invalid port forward target %v", spec.Target)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(spec.LocalPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on local port %d: %w", spec.LocalPort, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.lock.Lock()
+	m.nextSeq++
+	fwd := &activeForward{
+		seq: m.nextSeq,
+		info: PortForward{
+			Id:           strconv.Itoa(m.nextSeq),
+			Target:       spec.Target,
+			LocalAddress: listener.Addr().String(),
+		},
+		listener: listener,
+		cancel:   cancel,
+	}
+	m.forwards[fwd.info.Id] = fwd
+	info := fwd.info
+	m.lock.Unlock()
+
+	logger.Info("port forward started", zap.String("local", info.LocalAddress), zap.String("target", info.Target.String()))
+	go m.serve(ctx, fwd)
+	return &info, nil
+}
+
+// List returns the active forwards in the order they were started
+func (m *PortForwardManager) List() []PortForward {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	active := make([]*activeForward, 0, len(m.forwards))
+	for _, fwd := range m.forwards {
+		active = append(active, fwd)
+	}
+	slices.SortFunc(active, func(a, b *activeForward) int {
+		return a.seq - b.seq
+	})
+	result := make([]PortForward, 0, len(active))
+	for _, fwd := range active {
+		result = append(result, fwd.info)
+	}
+	return result
+}
+
+// Stop closes the listener of the forward and its connections
+func (m *PortForwardManager) Stop(id string) error {
+	m.lock.Lock()
+	fwd, ok := m.forwards[id]
+	delete(m.forwards, id)
+	m.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("no port forward %s", id)
+	}
+	fwd.cancel()
+	fwd.listener.Close()
+	logger.Info("port forward stopped", zap.String("local", fwd.info.LocalAddress), zap.String("target", fwd.info.Target.String()))
+	return nil
+}
+
+func (m *PortForwardManager) StopAll() {
+	for _, fwd := range m.List() {
+		m.Stop(fwd.Id)
+	}
+}
+
+func (m *PortForwardManager) update(fwd *activeForward, change func(info *PortForward)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	change(&fwd.info)
+}
+
+func (m *PortForwardManager) serve(ctx context.Context, fwd *activeForward) {
+	for {
+		conn, err := fwd.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("port forward stopped accepting", zap.String("local", fwd.info.LocalAddress), zap.Error(err))
+				m.Stop(fwd.info.Id)
+			}
+			return
+		}
+		go m.forward(ctx, fwd, conn)
+	}
+}
+
+func (m *PortForwardManager) forward(ctx context.Context, fwd *activeForward, conn net.Conn) {
+	m.update(fwd, func(info *PortForward) { info.Connections++ })
+	defer m.update(fwd, func(info *PortForward) { info.Connections-- })
+
+	remote, err := m.dial(ctx, fwd.info.Target)
+	if err != nil {
+		logger.Info("failed to forward connection", zap.String("target", fwd.info.Target.String()), zap.Error(err))
+		m.update(fwd, func(info *PortForward) { info.LastError = err.Error() })
+		conn.Close()
+		return
+	}
+	m.update(fwd, func(info *PortForward) { info.LastError = "" })
+
+	// the connections go away with the forward
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+		remote.Close()
+	})
+	defer stop()
+
+	pipePortConns(conn, remote)
+}
+
+// closeWrite half closes the connection if it can, otherwise closes it
+func closeWrite(conn io.Closer) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		cw.CloseWrite()
+	} else {
+		conn.Close()
+	}
+}
+
+// pipePortConns copies both ways until the remote side is done
+func pipePortConns(local net.Conn, remote io.ReadWriteCloser) {
+	go func() {
+		io.Copy(remote, local)
+		// the remote side may still reply
+		closeWrite(remote)
+	}()
+	io.Copy(local, remote)
+	local.Close()
+	remote.Close()
+}
+
+func (s *server) PortForward(stream grpc.BidiStreamingServer[PortForwardRequest, PortForwardResponse]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	start := first.GetStart()
+	if start == nil {
+		return status.Error(codes.InvalidArgument, "port forward not started")
+	}
+
+	ctx := stream.Context()
+	conn, err := s.clientFor(ctx).DialPortForward(ctx, PortForwardTarget{
+		Namespace: start.Namespace,
+		Kind:      start.Kind,
+		Name:      start.Name,
+		Port:      start.Port,
+	})
+	if err != nil {
+		return toGrpcError(err)
+	}
+	defer conn.Close()
+
+	if err := stream.Send(&PortForwardResponse{}); err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				closeWrite(conn)
+				return
+			}
+			if err != nil {
+				conn.Close()
+				return
+			}
+			if _, err := conn.Write(req.GetData()); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&PortForwardResponse{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return toGrpcError(err)
+		}
+	}
+}
+
+// tunnelConn is a forwarded connection tunneled over a PortForward rpc
+type tunnelConn struct {
+	stream  grpc.BidiStreamingClient[PortForwardRequest, PortForwardResponse]
+	cancel  context.CancelFunc
+	pending []byte
+}
+
+func (c *tunnelConn) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		resp, err := c.stream.Recv()
+		if err == io.EOF {
+			return 0, err
+		}
+		if err != nil {
+			return 0, fromGrpcError(err)
+		}
+		c.pending = resp.Data
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *tunnelConn) Write(p []byte) (int, error) {
+	if err := c.stream.Send(&PortForwardRequest{Request: &PortForwardRequest_Data{Data: p}}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CloseWrite tells the agent no more data is sent
+func (c *tunnelConn) CloseWrite() error {
+	return c.stream.CloseSend()
+}
+
+func (c *tunnelConn) Close() error {
+	c.cancel()
+	return nil
+}
+
+// dialPortForward opens a tunnel to the target through the agent
+func (r *RemoteK8sService) dialPortForward(ctx context.Context, target PortForwardTarget) (io.ReadWriteCloser, error) {
+	if r.Conn == nil {
+		return nil, fmt.Errorf("no remote connection")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+	stream, err := grpcClient.PortForward(ctx)
+	if err != nil {
+		cancel()
+		return nil, fromGrpcError(err)
+	}
+
+	err = stream.Send(&PortForwardRequest{
+		Request: &PortForwardRequest_Start{
+			Start: &PortForwardStart{
+				Namespace: target.Namespace,
+				Kind:      target.Kind,
+				Name:      target.Name,
+				Port:      target.Port,
+			},
+		},
+	})
+	if err != nil {
+		err = sendFailed(stream, err)
+		cancel()
+		return nil, err
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		cancel()
+		return nil, fromGrpcError(err)
+	}
+
+	return &tunnelConn{
+		stream: stream,
+		cancel: cancel,
+	}, nil
+}
+
+func (r *RemoteK8sService) portForwards() *PortForwardManager {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.forwards == nil {
+		r.forwards = NewPortForwardManager(r.dialPortForward)
+	}
+	return r.forwards
+}
+
+// StartPortForward implements K8sService.
+func (r *RemoteK8sService) StartPortForward(spec PortForwardSpec) (*PortForward, error) {
+	return r.portForwards().Start(spec)
+}
+
+// ListPortForwards implements K8sService.
+func (r *RemoteK8sService) ListPortForwards() []PortForward {
+	return r.portForwards().List()
+}
+
+// StopPortForward implements K8sService.
+func (r *RemoteK8sService) StopPortForward(id string) error {
+	return r.portForwards().Stop(id)
+}
+
+func (l *LocalK8sService) dialPortForward(ctx context.Context, target PortForwardTarget) (io.ReadWriteCloser, error) {
+	return l.client().DialPortForward(ctx, target)
+}
+
+// StartPortForward implements K8sService.
+func (l *LocalK8sService) StartPortForward(spec PortForwardSpec) (*PortForward, error) {
+	return l.forwards.Start(spec)
+}
+
+// ListPortForwards implements K8sService.
+func (l *LocalK8sService) ListPortForwards() []PortForward {
+	return l.forwards.List()
+}
+
+// StopPortForward implements K8sService.
+func (l *LocalK8sService) StopPortForward(id string) error {
+	return l.forwards.Stop(id)
+}
@@ -0,0 +1,142 @@
+package k8sservice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestService(ns string, name string, selector map[string]any, port int64, targetPort any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"namespace": ns, "name": name},
+		"spec": map[string]any{
+			"selector": selector,
+			"ports":    []any{map[string]any{"port": port, "targetPort": targetPort}},
+		},
+	}}
+}
+
+func newTestServingPod(ns string, name string, app string, phase string, portName string, port int64) *unstructured.Unstructured {
+	pod := newTestPod(ns, name)
+	pod.SetLabels(map[string]string{"app": app})
+	pod.Object["spec"] = map[string]any{
+		"containers": []any{map[string]any{
+			"name":  "main",
+			"ports": []any{map[string]any{"name": portName, "containerPort": port}},
+		}},
+	}
+	pod.Object["status"] = map[string]any{"phase": phase}
+	return pod
+}
+
+// echoPodPort greets with the pod and port it is connected to, then echoes
+func echoPodPort(k *K8sClient, namespace string, pod string, port int32) (io.ReadWriteCloser, error) {
+	local, remote := net.Pipe()
+	go func() {
+		defer remote.Close()
+		fmt.Fprintf(remote, "%s:%d\n", pod, port)
+		io.Copy(remote, remote)
+	}()
+	return local, nil
+}
+
+func TestRemotePortForward(t *testing.T) {
+	saved := dialPodPort
+	dialPodPort = echoPodPort
+	t.Cleanup(func() {
+		dialPodPort = saved
+	})
+
+	remote, _ := newTestAgent(t,
+		newTestService("default", "web", map[string]any{"app": "web"}, 80, "http"),
+		newTestService("default", "idle", map[string]any{"app": "idle"}, 80, int64(8080)),
+		newTestServingPod("default", "web-1", "web", "Pending", "http", 8080),
+		newTestServingPod("default", "web-2", "web", "Running", "http", 8081),
+		newTestServingPod("default", "db-1", "db", "Running", "http", 5432),
+	)
+	t.Cleanup(remote.portForwards().StopAll)
+
+	connect := func(t *testing.T, fwd *PortForward) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", fwd.LocalAddress)
+		if err != nil {
+			t.Fatalf("failed to connect to forward: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+		return conn, bufio.NewReader(conn)
+	}
+
+	cases := []struct {
+		name     string
+		target   PortForwardTarget
+		greeting string
+	}{
+		{"pod", PortForwardTarget{Namespace: "default", Kind: "Pod", Name: "pod1", Port: 9090}, "pod1:9090\n"},
+		{"service", PortForwardTarget{Namespace: "default", Kind: "Service", Name: "web", Port: 80}, "web-2:8081\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fwd, err := remote.StartPortForward(PortForwardSpec{Target: c.target})
+			if err != nil {
+				t.Fatalf("failed to start forward: %v", err)
+			}
+			conn, reader := connect(t, fwd)
+			if line, err := reader.ReadString('\n'); err != nil || line != c.greeting {
+				t.Fatalf("wrong greeting %q, %v", line, err)
+			}
+			io.WriteString(conn, "ping\n")
+			if line, err := reader.ReadString('\n'); err != nil || line != "ping\n" {
+				t.Fatalf("wrong echo %q, %v", line, err)
+			}
+		})
+	}
+
+	forwards := remote.ListPortForwards()
+	if len(forwards) != 2 || forwards[0].Target.Kind != "Pod" || forwards[1].Target.Kind != "Service" {
+		t.Fatalf("wrong forwards %v", forwards)
+	}
+
+	t.Run("no running pod", func(t *testing.T) {
+		fwd, err := remote.StartPortForward(PortForwardSpec{
+			Target: PortForwardTarget{Namespace: "default", Kind: "Service", Name: "idle", Port: 80},
+		})
+		if err != nil {
+			t.Fatalf("failed to start forward: %v", err)
+		}
+		_, reader := connect(t, fwd)
+		if _, err := reader.ReadString('\n'); err != io.EOF {
+			t.Errorf("connection should be closed, got %v", err)
+		}
+		for _, f := range remote.ListPortForwards() {
+			if f.Id == fwd.Id && !strings.Contains(f.LastError, "no running pod") {
+				t.Errorf("wrong last error %q", f.LastError)
+			}
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		for _, fwd := range remote.ListPortForwards() {
+			if err := remote.StopPortForward(fwd.Id); err != nil {
+				t.Errorf("failed to stop forward: %v", err)
+			}
+			if conn, err := net.Dial("tcp", fwd.LocalAddress); err == nil {
+				conn.Close()
+				t.Errorf("forward %v still listening", fwd.LocalAddress)
+			}
+		}
+		if forwards := remote.ListPortForwards(); len(forwards) != 0 {
+			t.Errorf("forwards not stopped %v", forwards)
+		}
+		if err := remote.StopPortForward("missing"); err == nil {
+			t.Errorf("stopping an unknown forward should fail")
+		}
+	})
+}
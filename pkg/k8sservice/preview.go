@@ -0,0 +1,117 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ResourcePreview is what deploying a resource would change, as a server
+// side dry run of the deploy tells
+type ResourcePreview struct {
+	Name   types.NamespacedName
+	Action common.ResourceAction
+	// nil if the resource doesn't exist
+	Live *unstructured.Unstructured
+	// the object after the deploy, nil for a delete or if the dry run failed
+	DryRun *unstructured.Unstructured
+	// why the dry run failed, e.g. the namespace is created by the same deploy,
+	// an api error keeps its reason and causes
+	DryRunErr error
+}
+
+// Change tells in a word what the deploy does to the resource
+func (p *ResourcePreview) Change() string {
+	switch {
+	case p.DryRunErr != nil:
+		return "failed"
+	case p.Action == common.Delete:
+		if p.Live == nil {
+			return "absent"
+		}
+		return "delete"
+	case p.Live == nil:
+		return "create"
+	case common.HasChanges(p.Diff()):
+		return "update"
+	}
+	return "unchanged"
+}
+
+// Diff compares the live object with the dry run result. The managedFields
+// and status are left out, the server keeps them.
+func (p *ResourcePreview) Diff() []common.DiffLine {
+	return common.DiffLines(diffYaml(p.Live), diffYaml(p.DryRun))
+}
+
+func diffYaml(obj *unstructured.Unstructured) string {
+	if obj == nil {
+		return ""
+	}
+	stripped := obj.DeepCopy()
+	unstructured.RemoveNestedField(stripped.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(stripped.Object, "status")
+	text, err := common.MarshalYaml(stripped)
+	if err != nil {
+		return err.Error()
+	}
+	return text
+}
+
+// PreviewResource fetches the live object of res and dry runs its action
+func (k *K8sClient) PreviewResource(res *common.ResourceInstanceAction, targetNs string) (*ResourcePreview, error) {
+	if !k.IsValid() {
+		return nil, fmt.Errorf("cluster not connected")
+	}
+	obj, dr, finalNs, err := k.resourceTarget(res, targetNs)
+	if err != nil {
+		return nil, err
+	}
+	preview := &ResourcePreview{
+		Name:   types.NamespacedName{Name: obj.GetName(), Namespace: finalNs},
+		Action: res.GetAction(),
+	}
+	live, err := dr.Get(context.TODO(), obj.GetName(), v1.GetOptions{})
+	if err == nil {
+		preview.Live = live
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if res.GetAction() == common.Delete && preview.Live == nil {
+		// nothing to delete
+		return preview, nil
+	}
+
+	dryRun := *res
+	dryRun.DryRun = true
+	if _, result, err := k.DeployResource(&dryRun, targetNs); err != nil {
+		preview.DryRunErr = err
+	} else {
+		preview.DryRun = result
+	}
+	return preview, nil
+}
+
+func marshalObject(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := obj.MarshalJSON()
+	return string(data), err
+}
+
+func unmarshalObject(data string) (*unstructured.Unstructured, error) {
+	if data == "" {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(data)); err != nil {
+		return nil, fmt.Errorf("failed to decode object: %w", err)
+	}
+	return obj, nil
+}
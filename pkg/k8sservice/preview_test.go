@@ -0,0 +1,182 @@
+package k8sservice
+
+import (
+	"strings"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/discovery/cached/memory"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestDeployClient is a client that can map and deploy pods
+func newTestDeployClient(objects ...runtime.Object) (*K8sClient, *dynamicfake.FakeDynamicClient) {
+	client, dynClient, _ := newTestDiscoveryClient(objects...)
+	return client, dynClient
+}
+
+// newTestDiscoveryClient is like newTestDeployClient, the resources
+// discovered can be changed through the returned fake
+func newTestDiscoveryClient(objects ...runtime.Object) (*K8sClient, *dynamicfake.FakeDynamicClient, *k8stesting.Fake) {
+	dynClient := newTestDynClient(objects...)
+	fake := &k8stesting.Fake{
+		Resources: []*metav1.APIResourceList{{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}},
+		}},
+	}
+	discovery := memory.NewMemCacheClient(&discoveryfake.FakeDiscovery{Fake: fake})
+	client := &K8sClient{
+		dynClient:       dynClient,
+		discoveryClient: discovery,
+		mapper:          restmapper.NewDeferredDiscoveryRESTMapper(discovery),
+	}
+	return client, dynClient, fake
+}
+
+func newTestPodAction(name string, app string, action common.ResourceAction) *common.ResourceInstanceAction {
+	return &common.ResourceInstanceAction{
+		Instance: &common.ResourceInstance{
+			Id:   name,
+			Spec: &common.ResourceSpec{ApiVer: "v1/pods"},
+			Cr:   "apiVersion: v1\nkind: Pod\nmetadata:\n  name: " + name + "\n  labels:\n    app: " + app + "\n",
+		},
+		Action:    action,
+		DefaultNs: "default",
+	}
+}
+
+// dryRunPatches answers the apply patches like a server dry run,
+// the object is returned with the server fields and not stored
+func dryRunPatches(dynClient *dynamicfake.FakeDynamicClient) {
+	dynClient.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()); err != nil {
+			return true, nil, err
+		}
+		obj.SetUID("server-uid")
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: common.APP_NAME}})
+		unstructured.SetNestedField(obj.Object, "Pending", "status", "phase")
+		return true, obj, nil
+	})
+	dynClient.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+}
+
+func TestPreviewResource(t *testing.T) {
+	live := newTestVersionedPod("web", 10, "web")
+	live.SetUID("server-uid")
+	live.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+	unstructured.SetNestedField(live.Object, "Running", "status", "phase")
+	client, dynClient := newTestDeployClient(live)
+	dryRunPatches(dynClient)
+	// a pod named bad is rejected
+	dynClient.PrependReactor("*", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := ""
+		switch a := action.(type) {
+		case k8stesting.PatchAction:
+			name = a.GetName()
+		case k8stesting.CreateAction:
+			name = a.GetObject().(*unstructured.Unstructured).GetName()
+		}
+		if name != "bad" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, "bad", field.ErrorList{
+			field.Required(field.NewPath("spec", "containers"), "a pod needs a container"),
+		})
+	})
+	remote := startTestAgent(t, &server{
+		client:      client,
+		userClients: make(map[string]*K8sClient),
+	})
+
+	for _, service := range []K8sService{&LocalK8sService{localClient: client}, remote} {
+		update, err := service.PreviewResource(newTestPodAction("web", "web2", common.Update), "")
+		if err != nil {
+			t.Fatalf("failed to preview: %v", err)
+		}
+		if update.Change() != "update" || update.Name.String() != "default/web" {
+			t.Errorf("wrong preview %s %s", update.Name, update.Change())
+		}
+		diff := update.Diff()
+		changed := []string{}
+		for _, line := range diff {
+			if line.Op != common.DiffSame {
+				changed = append(changed, strings.TrimSpace(line.String()))
+			}
+		}
+		// the resourceVersion is not in the dry run of the fake server
+		if strings.Join(changed, ",") != "-     app: web,+     app: web2,-   resourceVersion: \"10\"" {
+			t.Errorf("wrong diff %v", changed)
+		}
+
+		create, err := service.PreviewResource(newTestPodAction("db", "db", common.Create), "")
+		if err != nil || create.Change() != "create" || create.Live != nil || create.DryRun == nil {
+			t.Errorf("wrong create preview %v %v", create, err)
+		}
+
+		absent, err := service.PreviewResource(newTestPodAction("db", "db", common.Delete), "")
+		if err != nil || absent.Change() != "absent" {
+			t.Errorf("wrong absent preview %v %v", absent, err)
+		}
+		remove, err := service.PreviewResource(newTestPodAction("web", "web", common.Delete), "")
+		if err != nil || remove.Change() != "delete" {
+			t.Errorf("wrong delete preview %v %v", remove, err)
+		}
+
+		// the dry run rejection keeps its reason and field errors
+		bad, err := service.PreviewResource(newTestPodAction("bad", "bad", common.Create), "")
+		if err != nil || bad.Change() != "failed" || !apierrors.IsInvalid(bad.DryRunErr) {
+			t.Fatalf("wrong failed preview %v %v", bad, err)
+		}
+		if causes := ErrorCauses(bad.DryRunErr); len(causes) != 1 || causes[0] != "spec.containers: Required value: a pod needs a container" {
+			t.Errorf("wrong causes %v", causes)
+		}
+	}
+
+	// the dry run leaves the cluster alone
+	if _, err := dynClient.Resource(podsGvr).Namespace("default").Get(t.Context(), "db", metav1.GetOptions{}); err == nil {
+		t.Errorf("dry run created the pod")
+	}
+}
+
+func TestPendingActions(t *testing.T) {
+	deployed := &DeployedResources{
+		resIds:    make(map[string]*DeployDetail),
+		persister: &DummyPersister{},
+	}
+	action := newTestPodAction("web", "web", common.Create)
+	node := common.NewResourceNode(action.Instance)
+
+	pending, err := deployed.PendingActions(node)
+	if err != nil || len(pending) != 1 || pending["web"].Action != common.Create {
+		t.Fatalf("wrong pending actions %v %v", pending, err)
+	}
+	if len(deployed.resIds) != 0 {
+		t.Errorf("pending actions added a deployment")
+	}
+
+	if _, err := deployed.LockAndAdd(node); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	action.Instance.Cr = newTestPodAction("web", "web2", common.Create).Instance.Cr
+	pending, err = deployed.PendingActions(node)
+	if err != nil || len(pending) != 1 || pending["web"].Action != common.Update {
+		t.Fatalf("wrong pending actions %v %v", pending, err)
+	}
+	detail := deployed.resIds["web"]
+	if detail.AllInstances["web"].Action != common.Create || detail.OriginalCrs["web"].Cr == action.Instance.Cr {
+		t.Errorf("pending actions changed the deployment")
+	}
+}
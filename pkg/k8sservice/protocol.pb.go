@@ -22,17 +22,20 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type CrdReply struct {
+type ExecStart struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Crd   string `protobuf:"bytes,1,opt,name=crd,proto3" json:"crd,omitempty"`
-	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	PodRawJson string   `protobuf:"bytes,1,opt,name=pod_raw_json,json=podRawJson,proto3" json:"pod_raw_json,omitempty"`
+	Container  string   `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
+	Command    []string `protobuf:"bytes,3,rep,name=command,proto3" json:"command,omitempty"`
+	Tty        bool     `protobuf:"varint,4,opt,name=tty,proto3" json:"tty,omitempty"`
+	Stdin      bool     `protobuf:"varint,5,opt,name=stdin,proto3" json:"stdin,omitempty"`
 }
 
-func (x *CrdReply) Reset() {
-	*x = CrdReply{}
+func (x *ExecStart) Reset() {
+	*x = ExecStart{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[0]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -40,13 +43,13 @@ func (x *CrdReply) Reset() {
 	}
 }
 
-func (x *CrdReply) String() string {
+func (x *ExecStart) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CrdReply) ProtoMessage() {}
+func (*ExecStart) ProtoMessage() {}
 
-func (x *CrdReply) ProtoReflect() protoreflect.Message {
+func (x *ExecStart) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[0]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -58,36 +61,57 @@ func (x *CrdReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CrdReply.ProtoReflect.Descriptor instead.
-func (*CrdReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExecStart.ProtoReflect.Descriptor instead.
+func (*ExecStart) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{0}
 }
 
-func (x *CrdReply) GetCrd() string {
+func (x *ExecStart) GetPodRawJson() string {
 	if x != nil {
-		return x.Crd
+		return x.PodRawJson
 	}
 	return ""
 }
 
-func (x *CrdReply) GetError() string {
+func (x *ExecStart) GetContainer() string {
 	if x != nil {
-		return x.Error
+		return x.Container
 	}
 	return ""
 }
 
-type GetDescribeForReply struct {
+func (x *ExecStart) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *ExecStart) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
+}
+
+func (x *ExecStart) GetStdin() bool {
+	if x != nil {
+		return x.Stdin
+	}
+	return false
+}
+
+type TerminalSize struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Describe string `protobuf:"bytes,1,opt,name=describe,proto3" json:"describe,omitempty"`
-	Error    string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Width  uint32 `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height uint32 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
 }
 
-func (x *GetDescribeForReply) Reset() {
-	*x = GetDescribeForReply{}
+func (x *TerminalSize) Reset() {
+	*x = TerminalSize{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -95,13 +119,13 @@ func (x *GetDescribeForReply) Reset() {
 	}
 }
 
-func (x *GetDescribeForReply) String() string {
+func (x *TerminalSize) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDescribeForReply) ProtoMessage() {}
+func (*TerminalSize) ProtoMessage() {}
 
-func (x *GetDescribeForReply) ProtoReflect() protoreflect.Message {
+func (x *TerminalSize) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -113,36 +137,40 @@ func (x *GetDescribeForReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDescribeForReply.ProtoReflect.Descriptor instead.
-func (*GetDescribeForReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use TerminalSize.ProtoReflect.Descriptor instead.
+func (*TerminalSize) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *GetDescribeForReply) GetDescribe() string {
+func (x *TerminalSize) GetWidth() uint32 {
 	if x != nil {
-		return x.Describe
+		return x.Width
 	}
-	return ""
+	return 0
 }
 
-func (x *GetDescribeForReply) GetError() string {
+func (x *TerminalSize) GetHeight() uint32 {
 	if x != nil {
-		return x.Error
+		return x.Height
 	}
-	return ""
+	return 0
 }
 
-type RawRequestReply struct {
+type ExecRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Response string `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
-	Error    string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Types that are assignable to Request:
+	//	*ExecRequest_Start
+	//	*ExecRequest_Stdin
+	//	*ExecRequest_Resize
+	//	*ExecRequest_CloseStdin
+	Request isExecRequest_Request `protobuf_oneof:"request"`
 }
 
-func (x *RawRequestReply) Reset() {
-	*x = RawRequestReply{}
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -150,13 +178,13 @@ func (x *RawRequestReply) Reset() {
 	}
 }
 
-func (x *RawRequestReply) String() string {
+func (x *ExecRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RawRequestReply) ProtoMessage() {}
+func (*ExecRequest) ProtoMessage() {}
 
-func (x *RawRequestReply) ProtoReflect() protoreflect.Message {
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -168,36 +196,88 @@ func (x *RawRequestReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RawRequestReply.ProtoReflect.Descriptor instead.
-func (*RawRequestReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *RawRequestReply) GetResponse() string {
-	if x != nil {
-		return x.Response
+func (m *ExecRequest) GetRequest() isExecRequest_Request {
+	if m != nil {
+		return m.Request
 	}
-	return ""
+	return nil
 }
 
-func (x *RawRequestReply) GetError() string {
-	if x != nil {
-		return x.Error
+func (x *ExecRequest) GetStart() *ExecStart {
+	if x, ok := x.GetRequest().(*ExecRequest_Start); ok {
+		return x.Start
 	}
-	return ""
+	return nil
 }
 
-type PodLogRequest struct {
+func (x *ExecRequest) GetStdin() []byte {
+	if x, ok := x.GetRequest().(*ExecRequest_Stdin); ok {
+		return x.Stdin
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetResize() *TerminalSize {
+	if x, ok := x.GetRequest().(*ExecRequest_Resize); ok {
+		return x.Resize
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetCloseStdin() bool {
+	if x, ok := x.GetRequest().(*ExecRequest_CloseStdin); ok {
+		return x.CloseStdin
+	}
+	return false
+}
+
+type isExecRequest_Request interface {
+	isExecRequest_Request()
+}
+
+type ExecRequest_Start struct {
+	Start *ExecStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type ExecRequest_Stdin struct {
+	Stdin []byte `protobuf:"bytes,2,opt,name=stdin,proto3,oneof"`
+}
+
+type ExecRequest_Resize struct {
+	Resize *TerminalSize `protobuf:"bytes,3,opt,name=resize,proto3,oneof"`
+}
+
+type ExecRequest_CloseStdin struct {
+	// no more stdin
+	CloseStdin bool `protobuf:"varint,4,opt,name=close_stdin,json=closeStdin,proto3,oneof"`
+}
+
+func (*ExecRequest_Start) isExecRequest_Request() {}
+
+func (*ExecRequest_Stdin) isExecRequest_Request() {}
+
+func (*ExecRequest_Resize) isExecRequest_Request() {}
+
+func (*ExecRequest_CloseStdin) isExecRequest_Request() {}
+
+type ExecResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PodRawJson string `protobuf:"bytes,1,opt,name=pod_raw_json,json=podRawJson,proto3" json:"pod_raw_json,omitempty"`
-	Container  string `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
+	Stdout []byte `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr []byte `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	// the command is finished, a failed one fails the rpc
+	Exited bool `protobuf:"varint,3,opt,name=exited,proto3" json:"exited,omitempty"`
 }
 
-func (x *PodLogRequest) Reset() {
-	*x = PodLogRequest{}
+func (x *ExecResponse) Reset() {
+	*x = ExecResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -205,13 +285,13 @@ func (x *PodLogRequest) Reset() {
 	}
 }
 
-func (x *PodLogRequest) String() string {
+func (x *ExecResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodLogRequest) ProtoMessage() {}
+func (*ExecResponse) ProtoMessage() {}
 
-func (x *PodLogRequest) ProtoReflect() protoreflect.Message {
+func (x *ExecResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -223,36 +303,46 @@ func (x *PodLogRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodLogRequest.ProtoReflect.Descriptor instead.
-func (*PodLogRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
+func (*ExecResponse) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *PodLogRequest) GetPodRawJson() string {
+func (x *ExecResponse) GetStdout() []byte {
 	if x != nil {
-		return x.PodRawJson
+		return x.Stdout
 	}
-	return ""
+	return nil
 }
 
-func (x *PodLogRequest) GetContainer() string {
+func (x *ExecResponse) GetStderr() []byte {
 	if x != nil {
-		return x.Container
+		return x.Stderr
 	}
-	return ""
+	return nil
 }
 
-type AllNamespacesReply struct {
+func (x *ExecResponse) GetExited() bool {
+	if x != nil {
+		return x.Exited
+	}
+	return false
+}
+
+type PortForwardStart struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Namespaces []string `protobuf:"bytes,1,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
-	Error      string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Pod or Service
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Port int32  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
 }
 
-func (x *AllNamespacesReply) Reset() {
-	*x = AllNamespacesReply{}
+func (x *PortForwardStart) Reset() {
+	*x = PortForwardStart{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -260,13 +350,13 @@ func (x *AllNamespacesReply) Reset() {
 	}
 }
 
-func (x *AllNamespacesReply) String() string {
+func (x *PortForwardStart) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AllNamespacesReply) ProtoMessage() {}
+func (*PortForwardStart) ProtoMessage() {}
 
-func (x *AllNamespacesReply) ProtoReflect() protoreflect.Message {
+func (x *PortForwardStart) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -278,37 +368,52 @@ func (x *AllNamespacesReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AllNamespacesReply.ProtoReflect.Descriptor instead.
-func (*AllNamespacesReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use PortForwardStart.ProtoReflect.Descriptor instead.
+func (*PortForwardStart) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *AllNamespacesReply) GetNamespaces() []string {
+func (x *PortForwardStart) GetNamespace() string {
 	if x != nil {
-		return x.Namespaces
+		return x.Namespace
 	}
-	return nil
+	return ""
 }
 
-func (x *AllNamespacesReply) GetError() string {
+func (x *PortForwardStart) GetKind() string {
 	if x != nil {
-		return x.Error
+		return x.Kind
 	}
 	return ""
 }
 
-type GvrReply struct {
+func (x *PortForwardStart) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PortForwardStart) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+type PortForwardRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// *unstructured.UnstructuredList json
-	UnstructuredListJson string `protobuf:"bytes,1,opt,name=unstructured_list_json,json=unstructuredListJson,proto3" json:"unstructured_list_json,omitempty"`
-	Error                string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Types that are assignable to Request:
+	//	*PortForwardRequest_Start
+	//	*PortForwardRequest_Data
+	Request isPortForwardRequest_Request `protobuf_oneof:"request"`
 }
 
-func (x *GvrReply) Reset() {
-	*x = GvrReply{}
+func (x *PortForwardRequest) Reset() {
+	*x = PortForwardRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -316,13 +421,13 @@ func (x *GvrReply) Reset() {
 	}
 }
 
-func (x *GvrReply) String() string {
+func (x *PortForwardRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GvrReply) ProtoMessage() {}
+func (*PortForwardRequest) ProtoMessage() {}
 
-func (x *GvrReply) ProtoReflect() protoreflect.Message {
+func (x *PortForwardRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -334,38 +439,59 @@ func (x *GvrReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GvrReply.ProtoReflect.Descriptor instead.
-func (*GvrReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use PortForwardRequest.ProtoReflect.Descriptor instead.
+func (*PortForwardRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GvrReply) GetUnstructuredListJson() string {
-	if x != nil {
-		return x.UnstructuredListJson
+func (m *PortForwardRequest) GetRequest() isPortForwardRequest_Request {
+	if m != nil {
+		return m.Request
 	}
-	return ""
+	return nil
 }
 
-func (x *GvrReply) GetError() string {
-	if x != nil {
-		return x.Error
+func (x *PortForwardRequest) GetStart() *PortForwardStart {
+	if x, ok := x.GetRequest().(*PortForwardRequest_Start); ok {
+		return x.Start
 	}
-	return ""
+	return nil
 }
 
-type FetchGvrRequest struct {
+func (x *PortForwardRequest) GetData() []byte {
+	if x, ok := x.GetRequest().(*PortForwardRequest_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+type isPortForwardRequest_Request interface {
+	isPortForwardRequest_Request()
+}
+
+type PortForwardRequest_Start struct {
+	Start *PortForwardStart `protobuf:"bytes,1,opt,name=start,proto3,oneof"`
+}
+
+type PortForwardRequest_Data struct {
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*PortForwardRequest_Start) isPortForwardRequest_Request() {}
+
+func (*PortForwardRequest_Data) isPortForwardRequest_Request() {}
+
+// a failure to start or of the forwarded connection fails the rpc
+type PortForwardResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	G  string `protobuf:"bytes,1,opt,name=g,proto3" json:"g,omitempty"`
-	V  string `protobuf:"bytes,2,opt,name=v,proto3" json:"v,omitempty"`
-	R  string `protobuf:"bytes,3,opt,name=r,proto3" json:"r,omitempty"`
-	Ns string `protobuf:"bytes,4,opt,name=ns,proto3" json:"ns,omitempty"`
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 }
 
-func (x *FetchGvrRequest) Reset() {
-	*x = FetchGvrRequest{}
+func (x *PortForwardResponse) Reset() {
+	*x = PortForwardResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -373,13 +499,13 @@ func (x *FetchGvrRequest) Reset() {
 	}
 }
 
-func (x *FetchGvrRequest) String() string {
+func (x *PortForwardResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FetchGvrRequest) ProtoMessage() {}
+func (*PortForwardResponse) ProtoMessage() {}
 
-func (x *FetchGvrRequest) ProtoReflect() protoreflect.Message {
+func (x *PortForwardResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -391,50 +517,31 @@ func (x *FetchGvrRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FetchGvrRequest.ProtoReflect.Descriptor instead.
-func (*FetchGvrRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PortForwardResponse.ProtoReflect.Descriptor instead.
+func (*PortForwardResponse) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *FetchGvrRequest) GetG() string {
-	if x != nil {
-		return x.G
-	}
-	return ""
-}
-
-func (x *FetchGvrRequest) GetV() string {
-	if x != nil {
-		return x.V
-	}
-	return ""
-}
-
-func (x *FetchGvrRequest) GetR() string {
-	if x != nil {
-		return x.R
-	}
-	return ""
-}
-
-func (x *FetchGvrRequest) GetNs() string {
+func (x *PortForwardResponse) GetData() []byte {
 	if x != nil {
-		return x.Ns
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-// v1.APIResourceList json string
-type ApiResourceList struct {
+type ListClustersReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ApiResourceListJson string `protobuf:"bytes,1,opt,name=api_resource_list_json,json=apiResourceListJson,proto3" json:"api_resource_list_json,omitempty"`
+	// the kubeconfig contexts served by the agent
+	Clusters []string `protobuf:"bytes,1,rep,name=clusters,proto3" json:"clusters,omitempty"`
+	// the one used if an rpc has no target cluster
+	DefaultCluster string `protobuf:"bytes,2,opt,name=default_cluster,json=defaultCluster,proto3" json:"default_cluster,omitempty"`
 }
 
-func (x *ApiResourceList) Reset() {
-	*x = ApiResourceList{}
+func (x *ListClustersReply) Reset() {
+	*x = ListClustersReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -442,13 +549,13 @@ func (x *ApiResourceList) Reset() {
 	}
 }
 
-func (x *ApiResourceList) String() string {
+func (x *ListClustersReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApiResourceList) ProtoMessage() {}
+func (*ListClustersReply) ProtoMessage() {}
 
-func (x *ApiResourceList) ProtoReflect() protoreflect.Message {
+func (x *ListClustersReply) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -460,32 +567,35 @@ func (x *ApiResourceList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApiResourceList.ProtoReflect.Descriptor instead.
-func (*ApiResourceList) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListClustersReply.ProtoReflect.Descriptor instead.
+func (*ListClustersReply) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ApiResourceList) GetApiResourceListJson() string {
+func (x *ListClustersReply) GetClusters() []string {
 	if x != nil {
-		return x.ApiResourceListJson
+		return x.Clusters
+	}
+	return nil
+}
+
+func (x *ListClustersReply) GetDefaultCluster() string {
+	if x != nil {
+		return x.DefaultCluster
 	}
 	return ""
 }
 
-type ApiResourceEntry struct {
+type CrdReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ApiVer string `protobuf:"bytes,1,opt,name=api_ver,json=apiVer,proto3" json:"api_ver,omitempty"`
-	Gv     string `protobuf:"bytes,2,opt,name=gv,proto3" json:"gv,omitempty"`
-	// v1.APIResource
-	ApiResourceJson string `protobuf:"bytes,3,opt,name=api_resource_json,json=apiResourceJson,proto3" json:"api_resource_json,omitempty"`
-	Schema          string `protobuf:"bytes,4,opt,name=schema,proto3" json:"schema,omitempty"`
+	Crd string `protobuf:"bytes,1,opt,name=crd,proto3" json:"crd,omitempty"`
 }
 
-func (x *ApiResourceEntry) Reset() {
-	*x = ApiResourceEntry{}
+func (x *CrdReply) Reset() {
+	*x = CrdReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -493,13 +603,13 @@ func (x *ApiResourceEntry) Reset() {
 	}
 }
 
-func (x *ApiResourceEntry) String() string {
+func (x *CrdReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApiResourceEntry) ProtoMessage() {}
+func (*CrdReply) ProtoMessage() {}
 
-func (x *ApiResourceEntry) ProtoReflect() protoreflect.Message {
+func (x *CrdReply) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -511,66 +621,1108 @@ func (x *ApiResourceEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApiResourceEntry.ProtoReflect.Descriptor instead.
-func (*ApiResourceEntry) Descriptor() ([]byte, []int) {
+// Deprecated: Use CrdReply.ProtoReflect.Descriptor instead.
+func (*CrdReply) Descriptor() ([]byte, []int) {
 	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ApiResourceEntry) GetApiVer() string {
+func (x *CrdReply) GetCrd() string {
 	if x != nil {
-		return x.ApiVer
+		return x.Crd
 	}
 	return ""
 }
 
-func (x *ApiResourceEntry) GetGv() string {
-	if x != nil {
-		return x.Gv
-	}
-	return ""
-}
+type GetDescribeForReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-func (x *ApiResourceEntry) GetApiResourceJson() string {
-	if x != nil {
-		return x.ApiResourceJson
-	}
-	return ""
+	Describe string `protobuf:"bytes,1,opt,name=describe,proto3" json:"describe,omitempty"`
+}
+
+func (x *GetDescribeForReply) Reset() {
+	*x = GetDescribeForReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDescribeForReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDescribeForReply) ProtoMessage() {}
+
+func (x *GetDescribeForReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDescribeForReply.ProtoReflect.Descriptor instead.
+func (*GetDescribeForReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetDescribeForReply) GetDescribe() string {
+	if x != nil {
+		return x.Describe
+	}
+	return ""
+}
+
+// path is field 1 as the value of the StringValue the rpc used to take,
+// so a GET from an older gui still works
+type RawApiRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path        string        `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Method      string        `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Body        string        `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	ContentType string        `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Headers     []*HttpHeader `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty"`
+}
+
+func (x *RawApiRequest) Reset() {
+	*x = RawApiRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RawApiRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawApiRequest) ProtoMessage() {}
+
+func (x *RawApiRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawApiRequest.ProtoReflect.Descriptor instead.
+func (*RawApiRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RawApiRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *RawApiRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *RawApiRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *RawApiRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *RawApiRequest) GetHeaders() []*HttpHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+type HttpHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name   string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Values []string `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *HttpHeader) Reset() {
+	*x = HttpHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HttpHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HttpHeader) ProtoMessage() {}
+
+func (x *HttpHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HttpHeader.ProtoReflect.Descriptor instead.
+func (*HttpHeader) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HttpHeader) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HttpHeader) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type RawRequestReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the response body
+	Response   string        `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	StatusCode int32         `protobuf:"varint,3,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Status     string        `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Headers    []*HttpHeader `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty"`
+}
+
+func (x *RawRequestReply) Reset() {
+	*x = RawRequestReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RawRequestReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawRequestReply) ProtoMessage() {}
+
+func (x *RawRequestReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawRequestReply.ProtoReflect.Descriptor instead.
+func (*RawRequestReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RawRequestReply) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *RawRequestReply) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *RawRequestReply) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RawRequestReply) GetHeaders() []*HttpHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+type PodLogRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodRawJson string `protobuf:"bytes,1,opt,name=pod_raw_json,json=podRawJson,proto3" json:"pod_raw_json,omitempty"`
+	Container  string `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
+	Follow     bool   `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	// 0 for all lines
+	TailLines int64 `protobuf:"varint,4,opt,name=tail_lines,json=tailLines,proto3" json:"tail_lines,omitempty"`
+	// 0 for no limit, since_time (RFC3339) is used if set instead
+	SinceSeconds int64  `protobuf:"varint,5,opt,name=since_seconds,json=sinceSeconds,proto3" json:"since_seconds,omitempty"`
+	SinceTime    string `protobuf:"bytes,6,opt,name=since_time,json=sinceTime,proto3" json:"since_time,omitempty"`
+	Timestamps   bool   `protobuf:"varint,7,opt,name=timestamps,proto3" json:"timestamps,omitempty"`
+	Previous     bool   `protobuf:"varint,8,opt,name=previous,proto3" json:"previous,omitempty"`
+}
+
+func (x *PodLogRequest) Reset() {
+	*x = PodLogRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodLogRequest) ProtoMessage() {}
+
+func (x *PodLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodLogRequest.ProtoReflect.Descriptor instead.
+func (*PodLogRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PodLogRequest) GetPodRawJson() string {
+	if x != nil {
+		return x.PodRawJson
+	}
+	return ""
+}
+
+func (x *PodLogRequest) GetContainer() string {
+	if x != nil {
+		return x.Container
+	}
+	return ""
+}
+
+func (x *PodLogRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *PodLogRequest) GetTailLines() int64 {
+	if x != nil {
+		return x.TailLines
+	}
+	return 0
+}
+
+func (x *PodLogRequest) GetSinceSeconds() int64 {
+	if x != nil {
+		return x.SinceSeconds
+	}
+	return 0
+}
+
+func (x *PodLogRequest) GetSinceTime() string {
+	if x != nil {
+		return x.SinceTime
+	}
+	return ""
+}
+
+func (x *PodLogRequest) GetTimestamps() bool {
+	if x != nil {
+		return x.Timestamps
+	}
+	return false
+}
+
+func (x *PodLogRequest) GetPrevious() bool {
+	if x != nil {
+		return x.Previous
+	}
+	return false
+}
+
+type AllNamespacesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespaces []string `protobuf:"bytes,1,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+}
+
+func (x *AllNamespacesReply) Reset() {
+	*x = AllNamespacesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllNamespacesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllNamespacesReply) ProtoMessage() {}
+
+func (x *AllNamespacesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllNamespacesReply.ProtoReflect.Descriptor instead.
+func (*AllNamespacesReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AllNamespacesReply) GetNamespaces() []string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type GvrReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// *unstructured.UnstructuredList json, its metadata has the continue token
+	UnstructuredListJson string `protobuf:"bytes,1,opt,name=unstructured_list_json,json=unstructuredListJson,proto3" json:"unstructured_list_json,omitempty"`
+}
+
+func (x *GvrReply) Reset() {
+	*x = GvrReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GvrReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GvrReply) ProtoMessage() {}
+
+func (x *GvrReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GvrReply.ProtoReflect.Descriptor instead.
+func (*GvrReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GvrReply) GetUnstructuredListJson() string {
+	if x != nil {
+		return x.UnstructuredListJson
+	}
+	return ""
+}
+
+type FetchGvrRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	G  string `protobuf:"bytes,1,opt,name=g,proto3" json:"g,omitempty"`
+	V  string `protobuf:"bytes,2,opt,name=v,proto3" json:"v,omitempty"`
+	R  string `protobuf:"bytes,3,opt,name=r,proto3" json:"r,omitempty"`
+	Ns string `protobuf:"bytes,4,opt,name=ns,proto3" json:"ns,omitempty"`
+	// v1.ListOptions
+	ResourceVersion string `protobuf:"bytes,5,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	LabelSelector   string `protobuf:"bytes,6,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"`
+	FieldSelector   string `protobuf:"bytes,7,opt,name=field_selector,json=fieldSelector,proto3" json:"field_selector,omitempty"`
+	Limit           int64  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	// continue token from the previous page (list only)
+	Continue string `protobuf:"bytes,9,opt,name=continue,proto3" json:"continue,omitempty"`
+	// StreamGVRInstances only, the encoding of the items: "cbor" (default) or "json"
+	Encoding string `protobuf:"bytes,10,opt,name=encoding,proto3" json:"encoding,omitempty"`
+}
+
+func (x *FetchGvrRequest) Reset() {
+	*x = FetchGvrRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FetchGvrRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchGvrRequest) ProtoMessage() {}
+
+func (x *FetchGvrRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchGvrRequest.ProtoReflect.Descriptor instead.
+func (*FetchGvrRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FetchGvrRequest) GetG() string {
+	if x != nil {
+		return x.G
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetV() string {
+	if x != nil {
+		return x.V
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetR() string {
+	if x != nil {
+		return x.R
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetNs() string {
+	if x != nil {
+		return x.Ns
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetResourceVersion() string {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetLabelSelector() string {
+	if x != nil {
+		return x.LabelSelector
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetFieldSelector() string {
+	if x != nil {
+		return x.FieldSelector
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FetchGvrRequest) GetContinue() string {
+	if x != nil {
+		return x.Continue
+	}
+	return ""
+}
+
+func (x *FetchGvrRequest) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+type GvrItems struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the list without its items, in the first message only
+	List  []byte   `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Items [][]byte `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *GvrItems) Reset() {
+	*x = GvrItems{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GvrItems) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GvrItems) ProtoMessage() {}
+
+func (x *GvrItems) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GvrItems.ProtoReflect.Descriptor instead.
+func (*GvrItems) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GvrItems) GetList() []byte {
+	if x != nil {
+		return x.List
+	}
+	return nil
+}
+
+func (x *GvrItems) GetItems() [][]byte {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type WatchEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ADDED, MODIFIED, DELETED, BOOKMARK or ERROR
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// *unstructured.Unstructured json, or v1.Status json for ERROR
+	ObjectJson string `protobuf:"bytes,2,opt,name=object_json,json=objectJson,proto3" json:"object_json,omitempty"`
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WatchEvent) GetObjectJson() string {
+	if x != nil {
+		return x.ObjectJson
+	}
+	return ""
+}
+
+// v1.APIResourceList json string
+type ApiResourceList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ApiResourceListJson string `protobuf:"bytes,1,opt,name=api_resource_list_json,json=apiResourceListJson,proto3" json:"api_resource_list_json,omitempty"`
+}
+
+func (x *ApiResourceList) Reset() {
+	*x = ApiResourceList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiResourceList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiResourceList) ProtoMessage() {}
+
+func (x *ApiResourceList) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiResourceList.ProtoReflect.Descriptor instead.
+func (*ApiResourceList) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ApiResourceList) GetApiResourceListJson() string {
+	if x != nil {
+		return x.ApiResourceListJson
+	}
+	return ""
+}
+
+type ApiResourceEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ApiVer string `protobuf:"bytes,1,opt,name=api_ver,json=apiVer,proto3" json:"api_ver,omitempty"`
+	Gv     string `protobuf:"bytes,2,opt,name=gv,proto3" json:"gv,omitempty"`
+	// v1.APIResource
+	ApiResourceJson string `protobuf:"bytes,3,opt,name=api_resource_json,json=apiResourceJson,proto3" json:"api_resource_json,omitempty"`
+	Schema          string `protobuf:"bytes,4,opt,name=schema,proto3" json:"schema,omitempty"`
+}
+
+func (x *ApiResourceEntry) Reset() {
+	*x = ApiResourceEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiResourceEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiResourceEntry) ProtoMessage() {}
+
+func (x *ApiResourceEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiResourceEntry.ProtoReflect.Descriptor instead.
+func (*ApiResourceEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ApiResourceEntry) GetApiVer() string {
+	if x != nil {
+		return x.ApiVer
+	}
+	return ""
+}
+
+func (x *ApiResourceEntry) GetGv() string {
+	if x != nil {
+		return x.Gv
+	}
+	return ""
+}
+
+func (x *ApiResourceEntry) GetApiResourceJson() string {
+	if x != nil {
+		return x.ApiResourceJson
+	}
+	return ""
 }
 
 func (x *ApiResourceEntry) GetSchema() string {
 	if x != nil {
-		return x.Schema
+		return x.Schema
+	}
+	return ""
+}
+
+type ApiResourceInfoReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cached              bool              `protobuf:"varint,1,opt,name=cached,proto3" json:"cached,omitempty"`
+	ApiResourceListJson []string          `protobuf:"bytes,2,rep,name=api_resource_list_json,json=apiResourceListJson,proto3" json:"api_resource_list_json,omitempty"`
+	ResMap              map[string]string `protobuf:"bytes,3,rep,name=res_map,json=resMap,proto3" json:"res_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ApiResourceInfoReply) Reset() {
+	*x = ApiResourceInfoReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiResourceInfoReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiResourceInfoReply) ProtoMessage() {}
+
+func (x *ApiResourceInfoReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiResourceInfoReply.ProtoReflect.Descriptor instead.
+func (*ApiResourceInfoReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ApiResourceInfoReply) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *ApiResourceInfoReply) GetApiResourceListJson() []string {
+	if x != nil {
+		return x.ApiResourceListJson
+	}
+	return nil
+}
+
+func (x *ApiResourceInfoReply) GetResMap() map[string]string {
+	if x != nil {
+		return x.ResMap
+	}
+	return nil
+}
+
+type ClusterInfoReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Host string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Id   string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *ClusterInfoReply) Reset() {
+	*x = ClusterInfoReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClusterInfoReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClusterInfoReply) ProtoMessage() {}
+
+func (x *ClusterInfoReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClusterInfoReply.ProtoReflect.Descriptor instead.
+func (*ClusterInfoReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ClusterInfoReply) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *ClusterInfoReply) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeployResourceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Spec      *ResourceSpec `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	Cr        string        `protobuf:"bytes,3,opt,name=cr,proto3" json:"cr,omitempty"`
+	Order     int32         `protobuf:"varint,4,opt,name=order,proto3" json:"order,omitempty"`
+	Action    int32         `protobuf:"varint,5,opt,name=action,proto3" json:"action,omitempty"`
+	DefaultNs string        `protobuf:"bytes,6,opt,name=default_ns,json=defaultNs,proto3" json:"default_ns,omitempty"`
+	InstName  string        `protobuf:"bytes,7,opt,name=inst_name,json=instName,proto3" json:"inst_name,omitempty"`
+	Label     string        `protobuf:"bytes,8,opt,name=label,proto3" json:"label,omitempty"`
+	TargetNs  string        `protobuf:"bytes,9,opt,name=target_ns,json=targetNs,proto3" json:"target_ns,omitempty"`
+	DryRun    bool          `protobuf:"varint,10,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Force     bool          `protobuf:"varint,11,opt,name=force,proto3" json:"force,omitempty"`
+	Inventory string        `protobuf:"bytes,12,opt,name=inventory,proto3" json:"inventory,omitempty"`
+}
+
+func (x *DeployResourceRequest) Reset() {
+	*x = DeployResourceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeployResourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeployResourceRequest) ProtoMessage() {}
+
+func (x *DeployResourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeployResourceRequest.ProtoReflect.Descriptor instead.
+func (*DeployResourceRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeployResourceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeployResourceRequest) GetSpec() *ResourceSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *DeployResourceRequest) GetCr() string {
+	if x != nil {
+		return x.Cr
+	}
+	return ""
+}
+
+func (x *DeployResourceRequest) GetOrder() int32 {
+	if x != nil {
+		return x.Order
+	}
+	return 0
+}
+
+func (x *DeployResourceRequest) GetAction() int32 {
+	if x != nil {
+		return x.Action
+	}
+	return 0
+}
+
+func (x *DeployResourceRequest) GetDefaultNs() string {
+	if x != nil {
+		return x.DefaultNs
+	}
+	return ""
+}
+
+func (x *DeployResourceRequest) GetInstName() string {
+	if x != nil {
+		return x.InstName
+	}
+	return ""
+}
+
+func (x *DeployResourceRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *DeployResourceRequest) GetTargetNs() string {
+	if x != nil {
+		return x.TargetNs
 	}
 	return ""
 }
 
-type ApiResourceInfoReply struct {
+func (x *DeployResourceRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *DeployResourceRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *DeployResourceRequest) GetInventory() string {
+	if x != nil {
+		return x.Inventory
+	}
+	return ""
+}
+
+type ResourceSpec struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Cached              bool              `protobuf:"varint,1,opt,name=cached,proto3" json:"cached,omitempty"`
-	ApiResourceListJson []string          `protobuf:"bytes,2,rep,name=api_resource_list_json,json=apiResourceListJson,proto3" json:"api_resource_list_json,omitempty"`
-	ResMap              map[string]string `protobuf:"bytes,3,rep,name=res_map,json=resMap,proto3" json:"res_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ApiVer string `protobuf:"bytes,1,opt,name=api_ver,json=apiVer,proto3" json:"api_ver,omitempty"`
+	Schema string `protobuf:"bytes,2,opt,name=schema,proto3" json:"schema,omitempty"`
+	Loaded bool   `protobuf:"varint,3,opt,name=loaded,proto3" json:"loaded,omitempty"`
 }
 
-func (x *ApiResourceInfoReply) Reset() {
-	*x = ApiResourceInfoReply{}
+func (x *ResourceSpec) Reset() {
+	*x = ResourceSpec{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[9]
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ApiResourceInfoReply) String() string {
+func (x *ResourceSpec) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApiResourceInfoReply) ProtoMessage() {}
+func (*ResourceSpec) ProtoMessage() {}
 
-func (x *ApiResourceInfoReply) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[9]
+func (x *ResourceSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -581,58 +1733,59 @@ func (x *ApiResourceInfoReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApiResourceInfoReply.ProtoReflect.Descriptor instead.
-func (*ApiResourceInfoReply) Descriptor() ([]byte, []int) {
-	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ResourceSpec.ProtoReflect.Descriptor instead.
+func (*ResourceSpec) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *ApiResourceInfoReply) GetCached() bool {
+func (x *ResourceSpec) GetApiVer() string {
 	if x != nil {
-		return x.Cached
+		return x.ApiVer
 	}
-	return false
+	return ""
 }
 
-func (x *ApiResourceInfoReply) GetApiResourceListJson() []string {
+func (x *ResourceSpec) GetSchema() string {
 	if x != nil {
-		return x.ApiResourceListJson
+		return x.Schema
 	}
-	return nil
+	return ""
 }
 
-func (x *ApiResourceInfoReply) GetResMap() map[string]string {
+func (x *ResourceSpec) GetLoaded() bool {
 	if x != nil {
-		return x.ResMap
+		return x.Loaded
 	}
-	return nil
+	return false
 }
 
-type ClusterInfoReply struct {
+type DeployResourceReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Host string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
-	Id   string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	ReplyJson string `protobuf:"bytes,4,opt,name=reply_json,json=replyJson,proto3" json:"reply_json,omitempty"`
 }
 
-func (x *ClusterInfoReply) Reset() {
-	*x = ClusterInfoReply{}
+func (x *DeployResourceReply) Reset() {
+	*x = DeployResourceReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[10]
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ClusterInfoReply) String() string {
+func (x *DeployResourceReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClusterInfoReply) ProtoMessage() {}
+func (*DeployResourceReply) ProtoMessage() {}
 
-func (x *ClusterInfoReply) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[10]
+func (x *DeployResourceReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -643,58 +1796,58 @@ func (x *ClusterInfoReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClusterInfoReply.ProtoReflect.Descriptor instead.
-func (*ClusterInfoReply) Descriptor() ([]byte, []int) {
-	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use DeployResourceReply.ProtoReflect.Descriptor instead.
+func (*DeployResourceReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *ClusterInfoReply) GetHost() string {
+func (x *DeployResourceReply) GetName() string {
 	if x != nil {
-		return x.Host
+		return x.Name
 	}
 	return ""
 }
 
-func (x *ClusterInfoReply) GetId() string {
+func (x *DeployResourceReply) GetNamespace() string {
 	if x != nil {
-		return x.Id
+		return x.Namespace
 	}
 	return ""
 }
 
-type DeployResourceRequest struct {
+func (x *DeployResourceReply) GetReplyJson() string {
+	if x != nil {
+		return x.ReplyJson
+	}
+	return ""
+}
+
+type WaitForCrdRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Spec      *ResourceSpec `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
-	Cr        string        `protobuf:"bytes,3,opt,name=cr,proto3" json:"cr,omitempty"`
-	Order     int32         `protobuf:"varint,4,opt,name=order,proto3" json:"order,omitempty"`
-	Action    int32         `protobuf:"varint,5,opt,name=action,proto3" json:"action,omitempty"`
-	DefaultNs string        `protobuf:"bytes,6,opt,name=default_ns,json=defaultNs,proto3" json:"default_ns,omitempty"`
-	InstName  string        `protobuf:"bytes,7,opt,name=inst_name,json=instName,proto3" json:"inst_name,omitempty"`
-	Label     string        `protobuf:"bytes,8,opt,name=label,proto3" json:"label,omitempty"`
-	TargetNs  string        `protobuf:"bytes,9,opt,name=target_ns,json=targetNs,proto3" json:"target_ns,omitempty"`
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	TimeoutMs int64  `protobuf:"varint,2,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
 }
 
-func (x *DeployResourceRequest) Reset() {
-	*x = DeployResourceRequest{}
+func (x *WaitForCrdRequest) Reset() {
+	*x = WaitForCrdRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[11]
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DeployResourceRequest) String() string {
+func (x *WaitForCrdRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeployResourceRequest) ProtoMessage() {}
+func (*WaitForCrdRequest) ProtoMessage() {}
 
-func (x *DeployResourceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[11]
+func (x *WaitForCrdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -705,101 +1858,231 @@ func (x *DeployResourceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeployResourceRequest.ProtoReflect.Descriptor instead.
-func (*DeployResourceRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use WaitForCrdRequest.ProtoReflect.Descriptor instead.
+func (*WaitForCrdRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *DeployResourceRequest) GetId() string {
+func (x *WaitForCrdRequest) GetName() string {
 	if x != nil {
-		return x.Id
+		return x.Name
 	}
 	return ""
 }
 
-func (x *DeployResourceRequest) GetSpec() *ResourceSpec {
+func (x *WaitForCrdRequest) GetTimeoutMs() int64 {
 	if x != nil {
-		return x.Spec
+		return x.TimeoutMs
 	}
-	return nil
+	return 0
 }
 
-func (x *DeployResourceRequest) GetCr() string {
+type WaitForReadyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Resource  *DeployResourceRequest `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	TimeoutMs int64                  `protobuf:"varint,2,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+}
+
+func (x *WaitForReadyRequest) Reset() {
+	*x = WaitForReadyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WaitForReadyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitForReadyRequest) ProtoMessage() {}
+
+func (x *WaitForReadyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitForReadyRequest.ProtoReflect.Descriptor instead.
+func (*WaitForReadyRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WaitForReadyRequest) GetResource() *DeployResourceRequest {
 	if x != nil {
-		return x.Cr
+		return x.Resource
 	}
-	return ""
+	return nil
 }
 
-func (x *DeployResourceRequest) GetOrder() int32 {
+func (x *WaitForReadyRequest) GetTimeoutMs() int64 {
 	if x != nil {
-		return x.Order
+		return x.TimeoutMs
 	}
 	return 0
 }
 
-func (x *DeployResourceRequest) GetAction() int32 {
+type RestoreResourceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Resource *DeployResourceRequest `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	// empty to delete the resource
+	PriorJson string `protobuf:"bytes,2,opt,name=prior_json,json=priorJson,proto3" json:"prior_json,omitempty"`
+}
+
+func (x *RestoreResourceRequest) Reset() {
+	*x = RestoreResourceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RestoreResourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResourceRequest) ProtoMessage() {}
+
+func (x *RestoreResourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResourceRequest.ProtoReflect.Descriptor instead.
+func (*RestoreResourceRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RestoreResourceRequest) GetResource() *DeployResourceRequest {
 	if x != nil {
-		return x.Action
+		return x.Resource
 	}
-	return 0
+	return nil
 }
 
-func (x *DeployResourceRequest) GetDefaultNs() string {
+func (x *RestoreResourceRequest) GetPriorJson() string {
 	if x != nil {
-		return x.DefaultNs
+		return x.PriorJson
 	}
 	return ""
 }
 
-func (x *DeployResourceRequest) GetInstName() string {
+type HealthReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State   string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Checked string `protobuf:"bytes,3,opt,name=checked,proto3" json:"checked,omitempty"`
+}
+
+func (x *HealthReply) Reset() {
+	*x = HealthReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HealthReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthReply) ProtoMessage() {}
+
+func (x *HealthReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthReply.ProtoReflect.Descriptor instead.
+func (*HealthReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *HealthReply) GetState() string {
 	if x != nil {
-		return x.InstName
+		return x.State
 	}
 	return ""
 }
 
-func (x *DeployResourceRequest) GetLabel() string {
+func (x *HealthReply) GetMessage() string {
 	if x != nil {
-		return x.Label
+		return x.Message
 	}
 	return ""
 }
 
-func (x *DeployResourceRequest) GetTargetNs() string {
+func (x *HealthReply) GetChecked() string {
 	if x != nil {
-		return x.TargetNs
+		return x.Checked
 	}
 	return ""
 }
 
-type ResourceSpec struct {
+type PreviewResourceReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ApiVer string `protobuf:"bytes,1,opt,name=api_ver,json=apiVer,proto3" json:"api_ver,omitempty"`
-	Schema string `protobuf:"bytes,2,opt,name=schema,proto3" json:"schema,omitempty"`
-	Loaded bool   `protobuf:"varint,3,opt,name=loaded,proto3" json:"loaded,omitempty"`
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// empty if the resource doesn't exist
+	LiveJson string `protobuf:"bytes,3,opt,name=live_json,json=liveJson,proto3" json:"live_json,omitempty"`
+	// empty for a delete or if the dry run failed
+	DryRunJson string `protobuf:"bytes,4,opt,name=dry_run_json,json=dryRunJson,proto3" json:"dry_run_json,omitempty"`
+	// why the dry run failed
+	DryRunStatus *ApiStatus `protobuf:"bytes,5,opt,name=dry_run_status,json=dryRunStatus,proto3" json:"dry_run_status,omitempty"`
 }
 
-func (x *ResourceSpec) Reset() {
-	*x = ResourceSpec{}
+func (x *PreviewResourceReply) Reset() {
+	*x = PreviewResourceReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[12]
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[30]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ResourceSpec) String() string {
+func (x *PreviewResourceReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResourceSpec) ProtoMessage() {}
+func (*PreviewResourceReply) ProtoMessage() {}
 
-func (x *ResourceSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[12]
+func (x *PreviewResourceReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[30]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -810,60 +2093,73 @@ func (x *ResourceSpec) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResourceSpec.ProtoReflect.Descriptor instead.
-func (*ResourceSpec) Descriptor() ([]byte, []int) {
-	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use PreviewResourceReply.ProtoReflect.Descriptor instead.
+func (*PreviewResourceReply) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PreviewResourceReply) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PreviewResourceReply) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
 }
 
-func (x *ResourceSpec) GetApiVer() string {
+func (x *PreviewResourceReply) GetLiveJson() string {
 	if x != nil {
-		return x.ApiVer
+		return x.LiveJson
 	}
 	return ""
 }
 
-func (x *ResourceSpec) GetSchema() string {
+func (x *PreviewResourceReply) GetDryRunJson() string {
 	if x != nil {
-		return x.Schema
+		return x.DryRunJson
 	}
 	return ""
 }
 
-func (x *ResourceSpec) GetLoaded() bool {
+func (x *PreviewResourceReply) GetDryRunStatus() *ApiStatus {
 	if x != nil {
-		return x.Loaded
+		return x.DryRunStatus
 	}
-	return false
+	return nil
 }
 
-type DeployResourceReply struct {
+// ApiStatus is the detail of a failed rpc caused by a k8s api error
+type ApiStatus struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
-	Err       string `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
-	ReplyJson string `protobuf:"bytes,4,opt,name=reply_json,json=replyJson,proto3" json:"reply_json,omitempty"`
+	// metav1.Status json, with its reason, causes and field errors
+	StatusJson string `protobuf:"bytes,1,opt,name=status_json,json=statusJson,proto3" json:"status_json,omitempty"`
 }
 
-func (x *DeployResourceReply) Reset() {
-	*x = DeployResourceReply{}
+func (x *ApiStatus) Reset() {
+	*x = ApiStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[13]
+		mi := &file_pkg_k8sservice_protocol_proto_msgTypes[31]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DeployResourceReply) String() string {
+func (x *ApiStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeployResourceReply) ProtoMessage() {}
+func (*ApiStatus) ProtoMessage() {}
 
-func (x *DeployResourceReply) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[13]
+func (x *ApiStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_k8sservice_protocol_proto_msgTypes[31]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -874,35 +2170,14 @@ func (x *DeployResourceReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeployResourceReply.ProtoReflect.Descriptor instead.
-func (*DeployResourceReply) Descriptor() ([]byte, []int) {
-	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *DeployResourceReply) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *DeployResourceReply) GetNamespace() string {
-	if x != nil {
-		return x.Namespace
-	}
-	return ""
-}
-
-func (x *DeployResourceReply) GetErr() string {
-	if x != nil {
-		return x.Err
-	}
-	return ""
+// Deprecated: Use ApiStatus.ProtoReflect.Descriptor instead.
+func (*ApiStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_k8sservice_protocol_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *DeployResourceReply) GetReplyJson() string {
+func (x *ApiStatus) GetStatusJson() string {
 	if x != nil {
-		return x.ReplyJson
+		return x.StatusJson
 	}
 	return ""
 }
@@ -915,145 +2190,322 @@ var file_pkg_k8sservice_protocol_proto_rawDesc = []byte{
 	0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
 	0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f,
 	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x77, 0x72,
-	0x61, 0x70, 0x70, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x32, 0x0a, 0x08,
-	0x43, 0x72, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x72, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x72, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
-	0x22, 0x47, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x46,
-	0x6f, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x73, 0x63, 0x72,
-	0x69, 0x62, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x73, 0x63, 0x72,
-	0x69, 0x62, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x43, 0x0a, 0x0f, 0x52, 0x61, 0x77,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08,
-	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
-	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x4f,
-	0x0a, 0x0d, 0x50, 0x6f, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x20, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x5f, 0x72, 0x61, 0x77, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6f, 0x64, 0x52, 0x61, 0x77, 0x4a, 0x73, 0x6f,
-	0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x22,
-	0x4a, 0x0a, 0x12, 0x41, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73,
-	0x70, 0x61, 0x63, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x56, 0x0a, 0x08, 0x47,
-	0x76, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x34, 0x0a, 0x16, 0x75, 0x6e, 0x73, 0x74, 0x72,
-	0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x64, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6a, 0x73, 0x6f,
-	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x75, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63,
-	0x74, 0x75, 0x72, 0x65, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x14, 0x0a,
-	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x22, 0x4b, 0x0a, 0x0f, 0x46, 0x65, 0x74, 0x63, 0x68, 0x47, 0x76, 0x72, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x01, 0x67, 0x12, 0x0c, 0x0a, 0x01, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x01, 0x76, 0x12, 0x0c, 0x0a, 0x01, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x72,
-	0x12, 0x0e, 0x0a, 0x02, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x6e, 0x73,
-	0x22, 0x46, 0x0a, 0x0f, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c,
-	0x69, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x16, 0x61, 0x70, 0x69, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x13, 0x61, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
-	0x4c, 0x69, 0x73, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x22, 0x7f, 0x0a, 0x10, 0x41, 0x70, 0x69, 0x52,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x17, 0x0a, 0x07,
-	0x61, 0x70, 0x69, 0x5f, 0x76, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
-	0x70, 0x69, 0x56, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x67, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x67, 0x76, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x70, 0x69, 0x5f, 0x72, 0x65, 0x73,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0f, 0x61, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4a, 0x73, 0x6f,
-	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0xda, 0x01, 0x0a, 0x14, 0x41, 0x70,
-	0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x16, 0x61, 0x70,
-	0x69, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f,
-	0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x61, 0x70, 0x69, 0x52,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x12,
-	0x3a, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x21, 0x2e, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e,
-	0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x52, 0x65, 0x73, 0x4d, 0x61, 0x70, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x52, 0x06, 0x72, 0x65, 0x73, 0x4d, 0x61, 0x70, 0x1a, 0x39, 0x0a, 0x0b, 0x52,
-	0x65, 0x73, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
-	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x36, 0x0a, 0x10, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65,
-	0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f,
-	0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xf7,
-	0x01, 0x0a, 0x15, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x21, 0x0a, 0x04, 0x73, 0x70, 0x65, 0x63,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x04, 0x73, 0x70, 0x65, 0x63, 0x12, 0x0e, 0x0a, 0x02, 0x63,
-	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x63, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6f,
-	0x72, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65,
-	0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x65, 0x66,
-	0x61, 0x75, 0x6c, 0x74, 0x5f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64,
-	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x4e, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x73, 0x74,
-	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x73,
-	0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x08,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x74,
-	0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x73, 0x22, 0x57, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f,
-	0x76, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x70, 0x69, 0x56, 0x65,
-	0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x61,
-	0x64, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x6f, 0x61, 0x64, 0x65,
-	0x64, 0x22, 0x78, 0x0a, 0x13, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x72,
-	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x12, 0x1d, 0x0a, 0x0a,
-	0x72, 0x65, 0x70, 0x6c, 0x79, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x4a, 0x73, 0x6f, 0x6e, 0x32, 0xd8, 0x05, 0x0a, 0x0e,
-	0x47, 0x72, 0x70, 0x63, 0x4b, 0x38, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3f,
-	0x0a, 0x07, 0x49, 0x73, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x1a, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x00, 0x12,
-	0x40, 0x0a, 0x0e, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x12, 0x16, 0x2e, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x44, 0x65, 0x70, 0x6c,
-	0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
-	0x00, 0x12, 0x3d, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x49,
-	0x6e, 0x66, 0x6f, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x43, 0x6c,
-	0x75, 0x73, 0x74, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
-	0x12, 0x4b, 0x0a, 0x14, 0x46, 0x65, 0x74, 0x63, 0x68, 0x41, 0x6c, 0x6c, 0x41, 0x70, 0x69, 0x52,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x1a, 0x15, 0x2e, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x32, 0x0a,
-	0x11, 0x46, 0x65, 0x74, 0x63, 0x68, 0x47, 0x56, 0x52, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
-	0x65, 0x73, 0x12, 0x10, 0x2e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x47, 0x76, 0x72, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x47, 0x76, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
-	0x00, 0x12, 0x43, 0x0a, 0x12, 0x46, 0x65, 0x74, 0x63, 0x68, 0x41, 0x6c, 0x6c, 0x4e, 0x61, 0x6d,
-	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
-	0x13, 0x2e, 0x41, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64,
-	0x4c, 0x6f, 0x67, 0x12, 0x0e, 0x2e, 0x50, 0x6f, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75,
+	0x61, 0x70, 0x70, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8d, 0x01, 0x0a,
+	0x09, 0x45, 0x78, 0x65, 0x63, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x20, 0x0a, 0x0c, 0x70, 0x6f,
+	0x64, 0x5f, 0x72, 0x61, 0x77, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x70, 0x6f, 0x64, 0x52, 0x61, 0x77, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09,
+	0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x03, 0x74, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x22, 0x3c, 0x0a, 0x0c,
+	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0xa0, 0x01, 0x0a, 0x0b, 0x45,
+	0x78, 0x65, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x45, 0x78, 0x65, 0x63,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x16,
+	0x0a, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52,
+	0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x12, 0x27, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x53, 0x69, 0x7a, 0x65, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x21, 0x0a, 0x0b, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0a, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x74, 0x64,
+	0x69, 0x6e, 0x42, 0x09, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x56, 0x0a,
+	0x0c, 0x45, 0x78, 0x65, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73,
+	0x74, 0x64, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72, 0x12, 0x16, 0x0a,
+	0x06, 0x65, 0x78, 0x69, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x65,
+	0x78, 0x69, 0x74, 0x65, 0x64, 0x22, 0x6c, 0x0a, 0x10, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x22, 0x60, 0x0a, 0x12, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61,
+	0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x46,
+	0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x48, 0x00, 0x52, 0x05, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x42, 0x09, 0x0a, 0x07, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x29, 0x0a, 0x13, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0x58, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x63, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x66, 0x61,
+	0x75, 0x6c, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x22, 0x22, 0x0a, 0x08, 0x43, 0x72,
+	0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x72, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x72, 0x64, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x22, 0x37,
+	0x0a, 0x13, 0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x46, 0x6f, 0x72,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x22, 0x99, 0x01, 0x0a, 0x0d, 0x52, 0x61, 0x77, 0x41,
+	0x70, 0x69, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74,
+	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16, 0x0a,
+	0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x25, 0x0a, 0x07,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x48, 0x74, 0x74, 0x70, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x73, 0x22, 0x38, 0x0a, 0x0a, 0x48, 0x74, 0x74, 0x70, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x93, 0x01,
+	0x0a, 0x0f, 0x52, 0x61, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a,
+	0x0b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x25, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x48, 0x74, 0x74, 0x70, 0x48, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x4a, 0x04, 0x08,
+	0x02, 0x10, 0x03, 0x22, 0x86, 0x02, 0x0a, 0x0d, 0x50, 0x6f, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x5f, 0x72, 0x61, 0x77,
+	0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6f, 0x64,
+	0x52, 0x61, 0x77, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x1d, 0x0a,
+	0x0a, 0x74, 0x61, 0x69, 0x6c, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x74, 0x61, 0x69, 0x6c, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x22, 0x3a, 0x0a, 0x12,
+	0x41, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x73, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x22, 0x46, 0x0a, 0x08, 0x47, 0x76, 0x72, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x34, 0x0a, 0x16, 0x75, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
+	0x75, 0x72, 0x65, 0x64, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x75, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72,
+	0x65, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03,
+	0x22, 0x92, 0x02, 0x0a, 0x0f, 0x46, 0x65, 0x74, 0x63, 0x68, 0x47, 0x76, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x01, 0x67, 0x12, 0x0c, 0x0a, 0x01, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x76,
+	0x12, 0x0c, 0x0a, 0x01, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x01, 0x72, 0x12, 0x0e,
+	0x0a, 0x02, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x6e, 0x73, 0x12, 0x29,
+	0x0a, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x12, 0x25, 0x0a, 0x0e, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x53,
+	0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x63, 0x6f, 0x6e, 0x74, 0x69, 0x6e, 0x75, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x34, 0x0a, 0x08, 0x47, 0x76, 0x72, 0x49, 0x74, 0x65, 0x6d,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x41, 0x0a, 0x0a, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x22, 0x46,
+	0x0a, 0x0f, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69, 0x73,
+	0x74, 0x12, 0x33, 0x0a, 0x16, 0x61, 0x70, 0x69, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x13, 0x61, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69,
+	0x73, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x22, 0x7f, 0x0a, 0x10, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x70,
+	0x69, 0x5f, 0x76, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x70, 0x69,
+	0x56, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x67, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x67, 0x76, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x70, 0x69, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
+	0x61, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4a, 0x73, 0x6f, 0x6e, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0xda, 0x01, 0x0a, 0x14, 0x41, 0x70, 0x69, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x16, 0x61, 0x70, 0x69, 0x5f,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6a, 0x73,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x61, 0x70, 0x69, 0x52, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x3a, 0x0a,
+	0x07, 0x72, 0x65, 0x73, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21,
+	0x2e, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x2e, 0x52, 0x65, 0x73, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x06, 0x72, 0x65, 0x73, 0x4d, 0x61, 0x70, 0x1a, 0x39, 0x0a, 0x0b, 0x52, 0x65, 0x73,
+	0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x36, 0x0a, 0x10, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xc4, 0x02, 0x0a,
+	0x15, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x21, 0x0a, 0x04, 0x73, 0x70, 0x65, 0x63, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53,
+	0x70, 0x65, 0x63, 0x52, 0x04, 0x73, 0x70, 0x65, 0x63, 0x12, 0x0e, 0x0a, 0x02, 0x63, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x63, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x72, 0x64,
+	0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x12,
+	0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x5f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x4e, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x5f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x4e, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75,
+	0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05,
+	0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f,
+	0x72, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x72, 0x79, 0x22, 0x57, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53,
+	0x70, 0x65, 0x63, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f, 0x76, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x70, 0x69, 0x56, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x22, 0x6c, 0x0a, 0x13,
+	0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x5f, 0x6a,
+	0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x70, 0x6c, 0x79,
+	0x4a, 0x73, 0x6f, 0x6e, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0x46, 0x0a, 0x11, 0x57, 0x61,
+	0x69, 0x74, 0x46, 0x6f, 0x72, 0x43, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x4d, 0x73, 0x22, 0x68, 0x0a, 0x13, 0x57, 0x61, 0x69, 0x74, 0x46, 0x6f, 0x72, 0x52, 0x65, 0x61,
+	0x64, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x44, 0x65,
+	0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x22, 0x6b, 0x0a, 0x16,
+	0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x44, 0x65, 0x70, 0x6c, 0x6f,
+	0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72,
+	0x69, 0x6f, 0x72, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x4a, 0x73, 0x6f, 0x6e, 0x22, 0x57, 0x0a, 0x0b, 0x48, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x65, 0x64, 0x22, 0xb9, 0x01, 0x0a, 0x14, 0x50, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x6c, 0x69, 0x76, 0x65, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6c, 0x69, 0x76, 0x65, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0c, 0x64, 0x72,
+	0x79, 0x5f, 0x72, 0x75, 0x6e, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x4a, 0x73, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x0e,
+	0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x41, 0x70, 0x69, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x0c, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x2c,
+	0x0a, 0x09, 0x41, 0x70, 0x69, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x4a, 0x73, 0x6f, 0x6e, 0x32, 0xc1, 0x0a, 0x0a,
+	0x0e, 0x47, 0x72, 0x70, 0x63, 0x4b, 0x38, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x3f, 0x0a, 0x07, 0x49, 0x73, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x1a, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x00,
+	0x12, 0x40, 0x0a, 0x0e, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x12, 0x16, 0x2e, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x44, 0x65, 0x70,
+	0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x42, 0x0a, 0x0f, 0x50, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x16, 0x2e, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x50, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4d, 0x0a, 0x15, 0x57, 0x61, 0x69, 0x74, 0x46, 0x6f,
+	0x72, 0x43, 0x72, 0x64, 0x45, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x12,
+	0x12, 0x2e, 0x57, 0x61, 0x69, 0x74, 0x46, 0x6f, 0x72, 0x43, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75,
-	0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x48, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x75, 0x73,
-	0x74, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
+	0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x0c, 0x57, 0x61, 0x69, 0x74, 0x46, 0x6f, 0x72,
+	0x52, 0x65, 0x61, 0x64, 0x79, 0x12, 0x14, 0x2e, 0x57, 0x61, 0x69, 0x74, 0x46, 0x6f, 0x72, 0x52,
+	0x65, 0x61, 0x64, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x48, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x49, 0x0a,
+	0x0f, 0x47, 0x65, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x12, 0x16, 0x2e, 0x44, 0x65, 0x70, 0x6c, 0x6f, 0x79, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x17, 0x2e, 0x52, 0x65,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x3d,
+	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f,
+	0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x43, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4b, 0x0a,
+	0x14, 0x46, 0x65, 0x74, 0x63, 0x68, 0x41, 0x6c, 0x6c, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x1a, 0x15, 0x2e, 0x41, 0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x32, 0x0a, 0x11, 0x46, 0x65,
+	0x74, 0x63, 0x68, 0x47, 0x56, 0x52, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x12,
+	0x10, 0x2e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x47, 0x76, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x09, 0x2e, 0x47, 0x76, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x35,
+	0x0a, 0x12, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x47, 0x56, 0x52, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x73, 0x12, 0x10, 0x2e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x47, 0x76, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x47, 0x76, 0x72, 0x49, 0x74, 0x65, 0x6d,
+	0x73, 0x22, 0x00, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x11, 0x57, 0x61, 0x74, 0x63, 0x68, 0x47, 0x56,
+	0x52, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x10, 0x2e, 0x46, 0x65, 0x74,
+	0x63, 0x68, 0x47, 0x76, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x43, 0x0a,
+	0x12, 0x46, 0x65, 0x74, 0x63, 0x68, 0x41, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x41, 0x6c,
+	0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x3d, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x4c, 0x6f, 0x67, 0x12,
+	0x0e, 0x2e, 0x50, 0x6f, 0x64, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
 	0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x00, 0x12,
-	0x2b, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x43, 0x52, 0x44, 0x46, 0x6f, 0x72, 0x12, 0x11, 0x2e, 0x41,
-	0x70, 0x69, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x1a,
-	0x09, 0x2e, 0x43, 0x72, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x0e,
-	0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x46, 0x6f, 0x72, 0x12, 0x1c,
-	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x14, 0x2e, 0x47,
-	0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x46, 0x6f, 0x72, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x0c, 0x44, 0x6f, 0x52, 0x61, 0x77, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x1a, 0x10, 0x2e, 0x52, 0x61, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x0d, 0x5a, 0x0b, 0x2f, 0x6b, 0x38, 0x73, 0x73, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x66, 0x2e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x00, 0x30,
+	0x01, 0x12, 0x48, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1c, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74,
+	0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x09, 0x47,
+	0x65, 0x74, 0x43, 0x52, 0x44, 0x46, 0x6f, 0x72, 0x12, 0x11, 0x2e, 0x41, 0x70, 0x69, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x1a, 0x09, 0x2e, 0x43, 0x72,
+	0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x44,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x46, 0x6f, 0x72, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72,
+	0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x14, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x46, 0x6f, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x32, 0x0a, 0x0c, 0x44, 0x6f, 0x52, 0x61, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x2e, 0x52, 0x61, 0x77, 0x41, 0x70, 0x69, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x10, 0x2e, 0x52, 0x61, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x07, 0x45, 0x78, 0x65, 0x63, 0x50, 0x6f, 0x64, 0x12,
+	0x0c, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e,
+	0x45, 0x78, 0x65, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01,
+	0x30, 0x01, 0x12, 0x3e, 0x0a, 0x0b, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72,
+	0x64, 0x12, 0x13, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01,
+	0x30, 0x01, 0x12, 0x3c, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x12, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x42, 0x0d, 0x5a, 0x0b, 0x2f, 0x6b, 0x38, 0x73, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1068,57 +2520,103 @@ func file_pkg_k8sservice_protocol_proto_rawDescGZIP() []byte {
 	return file_pkg_k8sservice_protocol_proto_rawDescData
 }
 
-var file_pkg_k8sservice_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_pkg_k8sservice_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_pkg_k8sservice_protocol_proto_goTypes = []interface{}{
-	(*CrdReply)(nil),               // 0: CrdReply
-	(*GetDescribeForReply)(nil),    // 1: GetDescribeForReply
-	(*RawRequestReply)(nil),        // 2: RawRequestReply
-	(*PodLogRequest)(nil),          // 3: PodLogRequest
-	(*AllNamespacesReply)(nil),     // 4: AllNamespacesReply
-	(*GvrReply)(nil),               // 5: GvrReply
-	(*FetchGvrRequest)(nil),        // 6: FetchGvrRequest
-	(*ApiResourceList)(nil),        // 7: ApiResourceList
-	(*ApiResourceEntry)(nil),       // 8: ApiResourceEntry
-	(*ApiResourceInfoReply)(nil),   // 9: ApiResourceInfoReply
-	(*ClusterInfoReply)(nil),       // 10: ClusterInfoReply
-	(*DeployResourceRequest)(nil),  // 11: DeployResourceRequest
-	(*ResourceSpec)(nil),           // 12: ResourceSpec
-	(*DeployResourceReply)(nil),    // 13: DeployResourceReply
-	nil,                            // 14: ApiResourceInfoReply.ResMapEntry
-	(*emptypb.Empty)(nil),          // 15: google.protobuf.Empty
-	(*wrapperspb.BoolValue)(nil),   // 16: google.protobuf.BoolValue
-	(*wrapperspb.StringValue)(nil), // 17: google.protobuf.StringValue
+	(*ExecStart)(nil),              // 0: ExecStart
+	(*TerminalSize)(nil),           // 1: TerminalSize
+	(*ExecRequest)(nil),            // 2: ExecRequest
+	(*ExecResponse)(nil),           // 3: ExecResponse
+	(*PortForwardStart)(nil),       // 4: PortForwardStart
+	(*PortForwardRequest)(nil),     // 5: PortForwardRequest
+	(*PortForwardResponse)(nil),    // 6: PortForwardResponse
+	(*ListClustersReply)(nil),      // 7: ListClustersReply
+	(*CrdReply)(nil),               // 8: CrdReply
+	(*GetDescribeForReply)(nil),    // 9: GetDescribeForReply
+	(*RawApiRequest)(nil),          // 10: RawApiRequest
+	(*HttpHeader)(nil),             // 11: HttpHeader
+	(*RawRequestReply)(nil),        // 12: RawRequestReply
+	(*PodLogRequest)(nil),          // 13: PodLogRequest
+	(*AllNamespacesReply)(nil),     // 14: AllNamespacesReply
+	(*GvrReply)(nil),               // 15: GvrReply
+	(*FetchGvrRequest)(nil),        // 16: FetchGvrRequest
+	(*GvrItems)(nil),               // 17: GvrItems
+	(*WatchEvent)(nil),             // 18: WatchEvent
+	(*ApiResourceList)(nil),        // 19: ApiResourceList
+	(*ApiResourceEntry)(nil),       // 20: ApiResourceEntry
+	(*ApiResourceInfoReply)(nil),   // 21: ApiResourceInfoReply
+	(*ClusterInfoReply)(nil),       // 22: ClusterInfoReply
+	(*DeployResourceRequest)(nil),  // 23: DeployResourceRequest
+	(*ResourceSpec)(nil),           // 24: ResourceSpec
+	(*DeployResourceReply)(nil),    // 25: DeployResourceReply
+	(*WaitForCrdRequest)(nil),      // 26: WaitForCrdRequest
+	(*WaitForReadyRequest)(nil),    // 27: WaitForReadyRequest
+	(*RestoreResourceRequest)(nil), // 28: RestoreResourceRequest
+	(*HealthReply)(nil),            // 29: HealthReply
+	(*PreviewResourceReply)(nil),   // 30: PreviewResourceReply
+	(*ApiStatus)(nil),              // 31: ApiStatus
+	nil,                            // 32: ApiResourceInfoReply.ResMapEntry
+	(*emptypb.Empty)(nil),          // 33: google.protobuf.Empty
+	(*wrapperspb.BoolValue)(nil),   // 34: google.protobuf.BoolValue
+	(*wrapperspb.StringValue)(nil), // 35: google.protobuf.StringValue
 }
 var file_pkg_k8sservice_protocol_proto_depIdxs = []int32{
-	14, // 0: ApiResourceInfoReply.res_map:type_name -> ApiResourceInfoReply.ResMapEntry
-	12, // 1: DeployResourceRequest.spec:type_name -> ResourceSpec
-	15, // 2: GrpcK8sService.IsValid:input_type -> google.protobuf.Empty
-	11, // 3: GrpcK8sService.DeployResource:input_type -> DeployResourceRequest
-	15, // 4: GrpcK8sService.GetClusterInfo:input_type -> google.protobuf.Empty
-	16, // 5: GrpcK8sService.FetchAllApiResources:input_type -> google.protobuf.BoolValue
-	6,  // 6: GrpcK8sService.FetchGVRInstances:input_type -> FetchGvrRequest
-	15, // 7: GrpcK8sService.FetchAllNamespaces:input_type -> google.protobuf.Empty
-	3,  // 8: GrpcK8sService.GetPodLog:input_type -> PodLogRequest
-	15, // 9: GrpcK8sService.GetClusterName:input_type -> google.protobuf.Empty
-	8,  // 10: GrpcK8sService.GetCRDFor:input_type -> ApiResourceEntry
-	17, // 11: GrpcK8sService.GetDescribeFor:input_type -> google.protobuf.StringValue
-	17, // 12: GrpcK8sService.DoRawRequest:input_type -> google.protobuf.StringValue
-	16, // 13: GrpcK8sService.IsValid:output_type -> google.protobuf.BoolValue
-	13, // 14: GrpcK8sService.DeployResource:output_type -> DeployResourceReply
-	10, // 15: GrpcK8sService.GetClusterInfo:output_type -> ClusterInfoReply
-	9,  // 16: GrpcK8sService.FetchAllApiResources:output_type -> ApiResourceInfoReply
-	5,  // 17: GrpcK8sService.FetchGVRInstances:output_type -> GvrReply
-	4,  // 18: GrpcK8sService.FetchAllNamespaces:output_type -> AllNamespacesReply
-	17, // 19: GrpcK8sService.GetPodLog:output_type -> google.protobuf.StringValue
-	17, // 20: GrpcK8sService.GetClusterName:output_type -> google.protobuf.StringValue
-	0,  // 21: GrpcK8sService.GetCRDFor:output_type -> CrdReply
-	1,  // 22: GrpcK8sService.GetDescribeFor:output_type -> GetDescribeForReply
-	2,  // 23: GrpcK8sService.DoRawRequest:output_type -> RawRequestReply
-	13, // [13:24] is the sub-list for method output_type
-	2,  // [2:13] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	0,  // 0: ExecRequest.start:type_name -> ExecStart
+	1,  // 1: ExecRequest.resize:type_name -> TerminalSize
+	4,  // 2: PortForwardRequest.start:type_name -> PortForwardStart
+	11, // 3: RawApiRequest.headers:type_name -> HttpHeader
+	11, // 4: RawRequestReply.headers:type_name -> HttpHeader
+	32, // 5: ApiResourceInfoReply.res_map:type_name -> ApiResourceInfoReply.ResMapEntry
+	24, // 6: DeployResourceRequest.spec:type_name -> ResourceSpec
+	23, // 7: WaitForReadyRequest.resource:type_name -> DeployResourceRequest
+	23, // 8: RestoreResourceRequest.resource:type_name -> DeployResourceRequest
+	31, // 9: PreviewResourceReply.dry_run_status:type_name -> ApiStatus
+	33, // 10: GrpcK8sService.IsValid:input_type -> google.protobuf.Empty
+	23, // 11: GrpcK8sService.DeployResource:input_type -> DeployResourceRequest
+	23, // 12: GrpcK8sService.PreviewResource:input_type -> DeployResourceRequest
+	26, // 13: GrpcK8sService.WaitForCrdEstablished:input_type -> WaitForCrdRequest
+	27, // 14: GrpcK8sService.WaitForReady:input_type -> WaitForReadyRequest
+	23, // 15: GrpcK8sService.GetLiveResource:input_type -> DeployResourceRequest
+	28, // 16: GrpcK8sService.RestoreResource:input_type -> RestoreResourceRequest
+	33, // 17: GrpcK8sService.GetClusterInfo:input_type -> google.protobuf.Empty
+	34, // 18: GrpcK8sService.FetchAllApiResources:input_type -> google.protobuf.BoolValue
+	16, // 19: GrpcK8sService.FetchGVRInstances:input_type -> FetchGvrRequest
+	16, // 20: GrpcK8sService.StreamGVRInstances:input_type -> FetchGvrRequest
+	16, // 21: GrpcK8sService.WatchGVRInstances:input_type -> FetchGvrRequest
+	33, // 22: GrpcK8sService.FetchAllNamespaces:input_type -> google.protobuf.Empty
+	13, // 23: GrpcK8sService.GetPodLog:input_type -> PodLogRequest
+	33, // 24: GrpcK8sService.GetClusterName:input_type -> google.protobuf.Empty
+	20, // 25: GrpcK8sService.GetCRDFor:input_type -> ApiResourceEntry
+	35, // 26: GrpcK8sService.GetDescribeFor:input_type -> google.protobuf.StringValue
+	10, // 27: GrpcK8sService.DoRawRequest:input_type -> RawApiRequest
+	2,  // 28: GrpcK8sService.ExecPod:input_type -> ExecRequest
+	5,  // 29: GrpcK8sService.PortForward:input_type -> PortForwardRequest
+	33, // 30: GrpcK8sService.ListClusters:input_type -> google.protobuf.Empty
+	34, // 31: GrpcK8sService.IsValid:output_type -> google.protobuf.BoolValue
+	25, // 32: GrpcK8sService.DeployResource:output_type -> DeployResourceReply
+	30, // 33: GrpcK8sService.PreviewResource:output_type -> PreviewResourceReply
+	35, // 34: GrpcK8sService.WaitForCrdEstablished:output_type -> google.protobuf.StringValue
+	29, // 35: GrpcK8sService.WaitForReady:output_type -> HealthReply
+	35, // 36: GrpcK8sService.GetLiveResource:output_type -> google.protobuf.StringValue
+	33, // 37: GrpcK8sService.RestoreResource:output_type -> google.protobuf.Empty
+	22, // 38: GrpcK8sService.GetClusterInfo:output_type -> ClusterInfoReply
+	21, // 39: GrpcK8sService.FetchAllApiResources:output_type -> ApiResourceInfoReply
+	15, // 40: GrpcK8sService.FetchGVRInstances:output_type -> GvrReply
+	17, // 41: GrpcK8sService.StreamGVRInstances:output_type -> GvrItems
+	18, // 42: GrpcK8sService.WatchGVRInstances:output_type -> WatchEvent
+	14, // 43: GrpcK8sService.FetchAllNamespaces:output_type -> AllNamespacesReply
+	35, // 44: GrpcK8sService.GetPodLog:output_type -> google.protobuf.StringValue
+	35, // 45: GrpcK8sService.GetClusterName:output_type -> google.protobuf.StringValue
+	8,  // 46: GrpcK8sService.GetCRDFor:output_type -> CrdReply
+	9,  // 47: GrpcK8sService.GetDescribeFor:output_type -> GetDescribeForReply
+	12, // 48: GrpcK8sService.DoRawRequest:output_type -> RawRequestReply
+	3,  // 49: GrpcK8sService.ExecPod:output_type -> ExecResponse
+	6,  // 50: GrpcK8sService.PortForward:output_type -> PortForwardResponse
+	7,  // 51: GrpcK8sService.ListClusters:output_type -> ListClustersReply
+	31, // [31:52] is the sub-list for method output_type
+	10, // [10:31] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_pkg_k8sservice_protocol_proto_init() }
@@ -1128,7 +2626,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_pkg_k8sservice_protocol_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CrdReply); i {
+			switch v := v.(*ExecStart); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1140,7 +2638,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetDescribeForReply); i {
+			switch v := v.(*TerminalSize); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1152,7 +2650,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RawRequestReply); i {
+			switch v := v.(*ExecRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1164,7 +2662,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PodLogRequest); i {
+			switch v := v.(*ExecResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1176,7 +2674,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AllNamespacesReply); i {
+			switch v := v.(*PortForwardStart); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1188,7 +2686,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GvrReply); i {
+			switch v := v.(*PortForwardRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1200,7 +2698,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FetchGvrRequest); i {
+			switch v := v.(*PortForwardResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1212,7 +2710,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ApiResourceList); i {
+			switch v := v.(*ListClustersReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1224,7 +2722,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ApiResourceEntry); i {
+			switch v := v.(*CrdReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1236,7 +2734,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ApiResourceInfoReply); i {
+			switch v := v.(*GetDescribeForReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1248,7 +2746,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClusterInfoReply); i {
+			switch v := v.(*RawApiRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1260,7 +2758,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeployResourceRequest); i {
+			switch v := v.(*HttpHeader); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1272,7 +2770,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ResourceSpec); i {
+			switch v := v.(*RawRequestReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1284,6 +2782,150 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			}
 		}
 		file_pkg_k8sservice_protocol_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodLogRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AllNamespacesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GvrReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FetchGvrRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GvrItems); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiResourceList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiResourceEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiResourceInfoReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClusterInfoReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeployResourceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResourceSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DeployResourceReply); i {
 			case 0:
 				return &v.state
@@ -1295,6 +2937,88 @@ func file_pkg_k8sservice_protocol_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WaitForCrdRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WaitForReadyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreResourceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreviewResourceReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_k8sservice_protocol_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pkg_k8sservice_protocol_proto_msgTypes[2].OneofWrappers = []interface{}{
+		(*ExecRequest_Start)(nil),
+		(*ExecRequest_Stdin)(nil),
+		(*ExecRequest_Resize)(nil),
+		(*ExecRequest_CloseStdin)(nil),
+	}
+	file_pkg_k8sservice_protocol_proto_msgTypes[5].OneofWrappers = []interface{}{
+		(*PortForwardRequest_Start)(nil),
+		(*PortForwardRequest_Data)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1302,7 +3026,7 @@ func file_pkg_k8sservice_protocol_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_k8sservice_protocol_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,35 +21,69 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GrpcK8SService_IsValid_FullMethodName              = "/GrpcK8sService/IsValid"
-	GrpcK8SService_DeployResource_FullMethodName       = "/GrpcK8sService/DeployResource"
-	GrpcK8SService_GetClusterInfo_FullMethodName       = "/GrpcK8sService/GetClusterInfo"
-	GrpcK8SService_FetchAllApiResources_FullMethodName = "/GrpcK8sService/FetchAllApiResources"
-	GrpcK8SService_FetchGVRInstances_FullMethodName    = "/GrpcK8sService/FetchGVRInstances"
-	GrpcK8SService_FetchAllNamespaces_FullMethodName   = "/GrpcK8sService/FetchAllNamespaces"
-	GrpcK8SService_GetPodLog_FullMethodName            = "/GrpcK8sService/GetPodLog"
-	GrpcK8SService_GetClusterName_FullMethodName       = "/GrpcK8sService/GetClusterName"
-	GrpcK8SService_GetCRDFor_FullMethodName            = "/GrpcK8sService/GetCRDFor"
-	GrpcK8SService_GetDescribeFor_FullMethodName       = "/GrpcK8sService/GetDescribeFor"
-	GrpcK8SService_DoRawRequest_FullMethodName         = "/GrpcK8sService/DoRawRequest"
+	GrpcK8SService_IsValid_FullMethodName               = "/GrpcK8sService/IsValid"
+	GrpcK8SService_DeployResource_FullMethodName        = "/GrpcK8sService/DeployResource"
+	GrpcK8SService_PreviewResource_FullMethodName       = "/GrpcK8sService/PreviewResource"
+	GrpcK8SService_WaitForCrdEstablished_FullMethodName = "/GrpcK8sService/WaitForCrdEstablished"
+	GrpcK8SService_WaitForReady_FullMethodName          = "/GrpcK8sService/WaitForReady"
+	GrpcK8SService_GetLiveResource_FullMethodName       = "/GrpcK8sService/GetLiveResource"
+	GrpcK8SService_RestoreResource_FullMethodName       = "/GrpcK8sService/RestoreResource"
+	GrpcK8SService_GetClusterInfo_FullMethodName        = "/GrpcK8sService/GetClusterInfo"
+	GrpcK8SService_FetchAllApiResources_FullMethodName  = "/GrpcK8sService/FetchAllApiResources"
+	GrpcK8SService_FetchGVRInstances_FullMethodName     = "/GrpcK8sService/FetchGVRInstances"
+	GrpcK8SService_StreamGVRInstances_FullMethodName    = "/GrpcK8sService/StreamGVRInstances"
+	GrpcK8SService_WatchGVRInstances_FullMethodName     = "/GrpcK8sService/WatchGVRInstances"
+	GrpcK8SService_FetchAllNamespaces_FullMethodName    = "/GrpcK8sService/FetchAllNamespaces"
+	GrpcK8SService_GetPodLog_FullMethodName             = "/GrpcK8sService/GetPodLog"
+	GrpcK8SService_GetClusterName_FullMethodName        = "/GrpcK8sService/GetClusterName"
+	GrpcK8SService_GetCRDFor_FullMethodName             = "/GrpcK8sService/GetCRDFor"
+	GrpcK8SService_GetDescribeFor_FullMethodName        = "/GrpcK8sService/GetDescribeFor"
+	GrpcK8SService_DoRawRequest_FullMethodName          = "/GrpcK8sService/DoRawRequest"
+	GrpcK8SService_ExecPod_FullMethodName               = "/GrpcK8sService/ExecPod"
+	GrpcK8SService_PortForward_FullMethodName           = "/GrpcK8sService/PortForward"
+	GrpcK8SService_ListClusters_FullMethodName          = "/GrpcK8sService/ListClusters"
 )
 
 // GrpcK8SServiceClient is the client API for GrpcK8SService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// An rpc targets the cluster given in its "x-target-cluster" metadata,
+// or the agent's default cluster if absent. See ListClusters.
+//
+// A failed rpc returns a google.rpc.Status, an error of the k8s api has
+// its metav1.Status in an ApiStatus detail.
 type GrpcK8SServiceClient interface {
 	IsValid(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*wrapperspb.BoolValue, error)
 	// DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, error)
 	DeployResource(ctx context.Context, in *DeployResourceRequest, opts ...grpc.CallOption) (*DeployResourceReply, error)
+	// PreviewResource(res *common.ResourceInstanceAction, targetNs string) (*ResourcePreview, error)
+	PreviewResource(ctx context.Context, in *DeployResourceRequest, opts ...grpc.CallOption) (*PreviewResourceReply, error)
+	// WaitForCrdEstablished(name string, timeout time.Duration, progress func(string)) error
+	// The states of the crd seen while waiting are streamed.
+	WaitForCrdEstablished(ctx context.Context, in *WaitForCrdRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[wrapperspb.StringValue], error)
+	// WaitForReady(res *common.ResourceInstanceAction, targetNs string, timeout time.Duration, progress func(ResourceHealth)) (ResourceHealth, error)
+	// The health is streamed as it changes, the last one is the result.
+	WaitForReady(ctx context.Context, in *WaitForReadyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HealthReply], error)
+	// GetLiveResource(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, error)
+	// The value is empty if the resource doesn't exist.
+	GetLiveResource(ctx context.Context, in *DeployResourceRequest, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
+	// RestoreResource(res *common.ResourceInstanceAction, targetNs string, prior *unstructured.Unstructured) error
+	RestoreResource(ctx context.Context, in *RestoreResourceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// GetClusterInfo() *common.ClusterInfo
 	GetClusterInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ClusterInfoReply, error)
 	// FetchAllApiResources(force bool) *common.ApiResourceInfo
 	FetchAllApiResources(ctx context.Context, in *wrapperspb.BoolValue, opts ...grpc.CallOption) (*ApiResourceInfoReply, error)
-	// FetchGVRInstances(g string, v string, r string, ns string) (*unstructured.UnstructuredList, error)
+	// FetchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error)
 	FetchGVRInstances(ctx context.Context, in *FetchGvrRequest, opts ...grpc.CallOption) (*GvrReply, error)
+	// Same as FetchGVRInstances, the items come a batch at a time, each encoded on its own
+	StreamGVRInstances(ctx context.Context, in *FetchGvrRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GvrItems], error)
+	// WatchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (watch.Interface, error)
+	WatchGVRInstances(ctx context.Context, in *FetchGvrRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
 	// FetchAllNamespaces() ([]string, error)
 	FetchAllNamespaces(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*AllNamespacesReply, error)
-	// GetPodLog(podRaw *unstructured.Unstructured, container string) (io.ReadCloser, error)
+	// GetPodLog(ctx context.Context, podRaw *unstructured.Unstructured, container string, opts PodLogOptions) (io.ReadCloser, error)
+	// With follow the stream lasts until the caller cancels it.
 	GetPodLog(ctx context.Context, in *PodLogRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[wrapperspb.StringValue], error)
 	// GetClusterName() string
 	GetClusterName(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*wrapperspb.StringValue, error)
@@ -57,8 +91,18 @@ type GrpcK8SServiceClient interface {
 	GetCRDFor(ctx context.Context, in *ApiResourceEntry, opts ...grpc.CallOption) (*CrdReply, error)
 	// GetDescribeFor(item *unstructured.Unstructured) (string, error)
 	GetDescribeFor(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*GetDescribeForReply, error)
-	// DoRawRequest(s string) (string, error)
-	DoRawRequest(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*RawRequestReply, error)
+	// DoRawRequest(req *RawRequest) (*RawResponse, error)
+	// A response with an error status is a reply, not an rpc error.
+	DoRawRequest(ctx context.Context, in *RawApiRequest, opts ...grpc.CallOption) (*RawRequestReply, error)
+	// ExecPod(ctx context.Context, podRaw *unstructured.Unstructured, opts ExecOptions) error
+	// The first request starts the session, the last response tells how it ended.
+	ExecPod(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error)
+	// DialPortForward(ctx context.Context, target PortForwardTarget) (io.ReadWriteCloser, error)
+	// Tunnels one connection of a port forward. The first request names the target,
+	// the first response acknowledges the connection or tells why it failed.
+	PortForward(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PortForwardRequest, PortForwardResponse], error)
+	// ListClusters() ([]string, string, error)
+	ListClusters(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListClustersReply, error)
 }
 
 type grpcK8SServiceClient struct {
@@ -89,6 +133,74 @@ func (c *grpcK8SServiceClient) DeployResource(ctx context.Context, in *DeployRes
 	return out, nil
 }
 
+func (c *grpcK8SServiceClient) PreviewResource(ctx context.Context, in *DeployResourceRequest, opts ...grpc.CallOption) (*PreviewResourceReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PreviewResourceReply)
+	err := c.cc.Invoke(ctx, GrpcK8SService_PreviewResource_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcK8SServiceClient) WaitForCrdEstablished(ctx context.Context, in *WaitForCrdRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[wrapperspb.StringValue], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[0], GrpcK8SService_WaitForCrdEstablished_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WaitForCrdRequest, wrapperspb.StringValue]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_WaitForCrdEstablishedClient = grpc.ServerStreamingClient[wrapperspb.StringValue]
+
+func (c *grpcK8SServiceClient) WaitForReady(ctx context.Context, in *WaitForReadyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HealthReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[1], GrpcK8SService_WaitForReady_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WaitForReadyRequest, HealthReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_WaitForReadyClient = grpc.ServerStreamingClient[HealthReply]
+
+func (c *grpcK8SServiceClient) GetLiveResource(ctx context.Context, in *DeployResourceRequest, opts ...grpc.CallOption) (*wrapperspb.StringValue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(wrapperspb.StringValue)
+	err := c.cc.Invoke(ctx, GrpcK8SService_GetLiveResource_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcK8SServiceClient) RestoreResource(ctx context.Context, in *RestoreResourceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, GrpcK8SService_RestoreResource_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *grpcK8SServiceClient) GetClusterInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ClusterInfoReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ClusterInfoReply)
@@ -119,6 +231,44 @@ func (c *grpcK8SServiceClient) FetchGVRInstances(ctx context.Context, in *FetchG
 	return out, nil
 }
 
+func (c *grpcK8SServiceClient) StreamGVRInstances(ctx context.Context, in *FetchGvrRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GvrItems], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[2], GrpcK8SService_StreamGVRInstances_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchGvrRequest, GvrItems]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_StreamGVRInstancesClient = grpc.ServerStreamingClient[GvrItems]
+
+func (c *grpcK8SServiceClient) WatchGVRInstances(ctx context.Context, in *FetchGvrRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[3], GrpcK8SService_WatchGVRInstances_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchGvrRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_WatchGVRInstancesClient = grpc.ServerStreamingClient[WatchEvent]
+
 func (c *grpcK8SServiceClient) FetchAllNamespaces(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*AllNamespacesReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AllNamespacesReply)
@@ -131,7 +281,7 @@ func (c *grpcK8SServiceClient) FetchAllNamespaces(ctx context.Context, in *empty
 
 func (c *grpcK8SServiceClient) GetPodLog(ctx context.Context, in *PodLogRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[wrapperspb.StringValue], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[0], GrpcK8SService_GetPodLog_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[4], GrpcK8SService_GetPodLog_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +328,7 @@ func (c *grpcK8SServiceClient) GetDescribeFor(ctx context.Context, in *wrappersp
 	return out, nil
 }
 
-func (c *grpcK8SServiceClient) DoRawRequest(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (*RawRequestReply, error) {
+func (c *grpcK8SServiceClient) DoRawRequest(ctx context.Context, in *RawApiRequest, opts ...grpc.CallOption) (*RawRequestReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RawRequestReply)
 	err := c.cc.Invoke(ctx, GrpcK8SService_DoRawRequest_FullMethodName, in, out, cOpts...)
@@ -188,22 +338,82 @@ func (c *grpcK8SServiceClient) DoRawRequest(ctx context.Context, in *wrapperspb.
 	return out, nil
 }
 
+func (c *grpcK8SServiceClient) ExecPod(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecRequest, ExecResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[5], GrpcK8SService_ExecPod_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecRequest, ExecResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_ExecPodClient = grpc.BidiStreamingClient[ExecRequest, ExecResponse]
+
+func (c *grpcK8SServiceClient) PortForward(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PortForwardRequest, PortForwardResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GrpcK8SService_ServiceDesc.Streams[6], GrpcK8SService_PortForward_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PortForwardRequest, PortForwardResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_PortForwardClient = grpc.BidiStreamingClient[PortForwardRequest, PortForwardResponse]
+
+func (c *grpcK8SServiceClient) ListClusters(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListClustersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListClustersReply)
+	err := c.cc.Invoke(ctx, GrpcK8SService_ListClusters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GrpcK8SServiceServer is the server API for GrpcK8SService service.
 // All implementations must embed UnimplementedGrpcK8SServiceServer
 // for forward compatibility.
+//
+// An rpc targets the cluster given in its "x-target-cluster" metadata,
+// or the agent's default cluster if absent. See ListClusters.
+//
+// A failed rpc returns a google.rpc.Status, an error of the k8s api has
+// its metav1.Status in an ApiStatus detail.
 type GrpcK8SServiceServer interface {
 	IsValid(context.Context, *emptypb.Empty) (*wrapperspb.BoolValue, error)
 	// DeployResource(res *common.ResourceInstanceAction, targetNs string) (types.NamespacedName, error)
 	DeployResource(context.Context, *DeployResourceRequest) (*DeployResourceReply, error)
+	// PreviewResource(res *common.ResourceInstanceAction, targetNs string) (*ResourcePreview, error)
+	PreviewResource(context.Context, *DeployResourceRequest) (*PreviewResourceReply, error)
+	// WaitForCrdEstablished(name string, timeout time.Duration, progress func(string)) error
+	// The states of the crd seen while waiting are streamed.
+	WaitForCrdEstablished(*WaitForCrdRequest, grpc.ServerStreamingServer[wrapperspb.StringValue]) error
+	// WaitForReady(res *common.ResourceInstanceAction, targetNs string, timeout time.Duration, progress func(ResourceHealth)) (ResourceHealth, error)
+	// The health is streamed as it changes, the last one is the result.
+	WaitForReady(*WaitForReadyRequest, grpc.ServerStreamingServer[HealthReply]) error
+	// GetLiveResource(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, error)
+	// The value is empty if the resource doesn't exist.
+	GetLiveResource(context.Context, *DeployResourceRequest) (*wrapperspb.StringValue, error)
+	// RestoreResource(res *common.ResourceInstanceAction, targetNs string, prior *unstructured.Unstructured) error
+	RestoreResource(context.Context, *RestoreResourceRequest) (*emptypb.Empty, error)
 	// GetClusterInfo() *common.ClusterInfo
 	GetClusterInfo(context.Context, *emptypb.Empty) (*ClusterInfoReply, error)
 	// FetchAllApiResources(force bool) *common.ApiResourceInfo
 	FetchAllApiResources(context.Context, *wrapperspb.BoolValue) (*ApiResourceInfoReply, error)
-	// FetchGVRInstances(g string, v string, r string, ns string) (*unstructured.UnstructuredList, error)
+	// FetchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error)
 	FetchGVRInstances(context.Context, *FetchGvrRequest) (*GvrReply, error)
+	// Same as FetchGVRInstances, the items come a batch at a time, each encoded on its own
+	StreamGVRInstances(*FetchGvrRequest, grpc.ServerStreamingServer[GvrItems]) error
+	// WatchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (watch.Interface, error)
+	WatchGVRInstances(*FetchGvrRequest, grpc.ServerStreamingServer[WatchEvent]) error
 	// FetchAllNamespaces() ([]string, error)
 	FetchAllNamespaces(context.Context, *emptypb.Empty) (*AllNamespacesReply, error)
-	// GetPodLog(podRaw *unstructured.Unstructured, container string) (io.ReadCloser, error)
+	// GetPodLog(ctx context.Context, podRaw *unstructured.Unstructured, container string, opts PodLogOptions) (io.ReadCloser, error)
+	// With follow the stream lasts until the caller cancels it.
 	GetPodLog(*PodLogRequest, grpc.ServerStreamingServer[wrapperspb.StringValue]) error
 	// GetClusterName() string
 	GetClusterName(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error)
@@ -211,8 +421,18 @@ type GrpcK8SServiceServer interface {
 	GetCRDFor(context.Context, *ApiResourceEntry) (*CrdReply, error)
 	// GetDescribeFor(item *unstructured.Unstructured) (string, error)
 	GetDescribeFor(context.Context, *wrapperspb.StringValue) (*GetDescribeForReply, error)
-	// DoRawRequest(s string) (string, error)
-	DoRawRequest(context.Context, *wrapperspb.StringValue) (*RawRequestReply, error)
+	// DoRawRequest(req *RawRequest) (*RawResponse, error)
+	// A response with an error status is a reply, not an rpc error.
+	DoRawRequest(context.Context, *RawApiRequest) (*RawRequestReply, error)
+	// ExecPod(ctx context.Context, podRaw *unstructured.Unstructured, opts ExecOptions) error
+	// The first request starts the session, the last response tells how it ended.
+	ExecPod(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error
+	// DialPortForward(ctx context.Context, target PortForwardTarget) (io.ReadWriteCloser, error)
+	// Tunnels one connection of a port forward. The first request names the target,
+	// the first response acknowledges the connection or tells why it failed.
+	PortForward(grpc.BidiStreamingServer[PortForwardRequest, PortForwardResponse]) error
+	// ListClusters() ([]string, string, error)
+	ListClusters(context.Context, *emptypb.Empty) (*ListClustersReply, error)
 	mustEmbedUnimplementedGrpcK8SServiceServer()
 }
 
@@ -229,6 +449,21 @@ func (UnimplementedGrpcK8SServiceServer) IsValid(context.Context, *emptypb.Empty
 func (UnimplementedGrpcK8SServiceServer) DeployResource(context.Context, *DeployResourceRequest) (*DeployResourceReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeployResource not implemented")
 }
+func (UnimplementedGrpcK8SServiceServer) PreviewResource(context.Context, *DeployResourceRequest) (*PreviewResourceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewResource not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) WaitForCrdEstablished(*WaitForCrdRequest, grpc.ServerStreamingServer[wrapperspb.StringValue]) error {
+	return status.Errorf(codes.Unimplemented, "method WaitForCrdEstablished not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) WaitForReady(*WaitForReadyRequest, grpc.ServerStreamingServer[HealthReply]) error {
+	return status.Errorf(codes.Unimplemented, "method WaitForReady not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) GetLiveResource(context.Context, *DeployResourceRequest) (*wrapperspb.StringValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLiveResource not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) RestoreResource(context.Context, *RestoreResourceRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreResource not implemented")
+}
 func (UnimplementedGrpcK8SServiceServer) GetClusterInfo(context.Context, *emptypb.Empty) (*ClusterInfoReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetClusterInfo not implemented")
 }
@@ -238,6 +473,12 @@ func (UnimplementedGrpcK8SServiceServer) FetchAllApiResources(context.Context, *
 func (UnimplementedGrpcK8SServiceServer) FetchGVRInstances(context.Context, *FetchGvrRequest) (*GvrReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchGVRInstances not implemented")
 }
+func (UnimplementedGrpcK8SServiceServer) StreamGVRInstances(*FetchGvrRequest, grpc.ServerStreamingServer[GvrItems]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamGVRInstances not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) WatchGVRInstances(*FetchGvrRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchGVRInstances not implemented")
+}
 func (UnimplementedGrpcK8SServiceServer) FetchAllNamespaces(context.Context, *emptypb.Empty) (*AllNamespacesReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchAllNamespaces not implemented")
 }
@@ -253,9 +494,18 @@ func (UnimplementedGrpcK8SServiceServer) GetCRDFor(context.Context, *ApiResource
 func (UnimplementedGrpcK8SServiceServer) GetDescribeFor(context.Context, *wrapperspb.StringValue) (*GetDescribeForReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetDescribeFor not implemented")
 }
-func (UnimplementedGrpcK8SServiceServer) DoRawRequest(context.Context, *wrapperspb.StringValue) (*RawRequestReply, error) {
+func (UnimplementedGrpcK8SServiceServer) DoRawRequest(context.Context, *RawApiRequest) (*RawRequestReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DoRawRequest not implemented")
 }
+func (UnimplementedGrpcK8SServiceServer) ExecPod(grpc.BidiStreamingServer[ExecRequest, ExecResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExecPod not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) PortForward(grpc.BidiStreamingServer[PortForwardRequest, PortForwardResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PortForward not implemented")
+}
+func (UnimplementedGrpcK8SServiceServer) ListClusters(context.Context, *emptypb.Empty) (*ListClustersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClusters not implemented")
+}
 func (UnimplementedGrpcK8SServiceServer) mustEmbedUnimplementedGrpcK8SServiceServer() {}
 func (UnimplementedGrpcK8SServiceServer) testEmbeddedByValue()                        {}
 
@@ -313,6 +563,82 @@ func _GrpcK8SService_DeployResource_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrpcK8SService_PreviewResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeployResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcK8SServiceServer).PreviewResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrpcK8SService_PreviewResource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcK8SServiceServer).PreviewResource(ctx, req.(*DeployResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcK8SService_WaitForCrdEstablished_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WaitForCrdRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcK8SServiceServer).WaitForCrdEstablished(m, &grpc.GenericServerStream[WaitForCrdRequest, wrapperspb.StringValue]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_WaitForCrdEstablishedServer = grpc.ServerStreamingServer[wrapperspb.StringValue]
+
+func _GrpcK8SService_WaitForReady_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WaitForReadyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcK8SServiceServer).WaitForReady(m, &grpc.GenericServerStream[WaitForReadyRequest, HealthReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_WaitForReadyServer = grpc.ServerStreamingServer[HealthReply]
+
+func _GrpcK8SService_GetLiveResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeployResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcK8SServiceServer).GetLiveResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrpcK8SService_GetLiveResource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcK8SServiceServer).GetLiveResource(ctx, req.(*DeployResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcK8SService_RestoreResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcK8SServiceServer).RestoreResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrpcK8SService_RestoreResource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcK8SServiceServer).RestoreResource(ctx, req.(*RestoreResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GrpcK8SService_GetClusterInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -367,6 +693,28 @@ func _GrpcK8SService_FetchGVRInstances_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GrpcK8SService_StreamGVRInstances_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchGvrRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcK8SServiceServer).StreamGVRInstances(m, &grpc.GenericServerStream[FetchGvrRequest, GvrItems]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_StreamGVRInstancesServer = grpc.ServerStreamingServer[GvrItems]
+
+func _GrpcK8SService_WatchGVRInstances_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchGvrRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrpcK8SServiceServer).WatchGVRInstances(m, &grpc.GenericServerStream[FetchGvrRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_WatchGVRInstancesServer = grpc.ServerStreamingServer[WatchEvent]
+
 func _GrpcK8SService_FetchAllNamespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -451,7 +799,7 @@ func _GrpcK8SService_GetDescribeFor_Handler(srv interface{}, ctx context.Context
 }
 
 func _GrpcK8SService_DoRawRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(wrapperspb.StringValue)
+	in := new(RawApiRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -463,7 +811,39 @@ func _GrpcK8SService_DoRawRequest_Handler(srv interface{}, ctx context.Context,
 		FullMethod: GrpcK8SService_DoRawRequest_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GrpcK8SServiceServer).DoRawRequest(ctx, req.(*wrapperspb.StringValue))
+		return srv.(GrpcK8SServiceServer).DoRawRequest(ctx, req.(*RawApiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GrpcK8SService_ExecPod_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GrpcK8SServiceServer).ExecPod(&grpc.GenericServerStream[ExecRequest, ExecResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_ExecPodServer = grpc.BidiStreamingServer[ExecRequest, ExecResponse]
+
+func _GrpcK8SService_PortForward_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GrpcK8SServiceServer).PortForward(&grpc.GenericServerStream[PortForwardRequest, PortForwardResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GrpcK8SService_PortForwardServer = grpc.BidiStreamingServer[PortForwardRequest, PortForwardResponse]
+
+func _GrpcK8SService_ListClusters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GrpcK8SServiceServer).ListClusters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GrpcK8SService_ListClusters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GrpcK8SServiceServer).ListClusters(ctx, req.(*emptypb.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -483,6 +863,18 @@ var GrpcK8SService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeployResource",
 			Handler:    _GrpcK8SService_DeployResource_Handler,
 		},
+		{
+			MethodName: "PreviewResource",
+			Handler:    _GrpcK8SService_PreviewResource_Handler,
+		},
+		{
+			MethodName: "GetLiveResource",
+			Handler:    _GrpcK8SService_GetLiveResource_Handler,
+		},
+		{
+			MethodName: "RestoreResource",
+			Handler:    _GrpcK8SService_RestoreResource_Handler,
+		},
 		{
 			MethodName: "GetClusterInfo",
 			Handler:    _GrpcK8SService_GetClusterInfo_Handler,
@@ -515,13 +907,49 @@ var GrpcK8SService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DoRawRequest",
 			Handler:    _GrpcK8SService_DoRawRequest_Handler,
 		},
+		{
+			MethodName: "ListClusters",
+			Handler:    _GrpcK8SService_ListClusters_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WaitForCrdEstablished",
+			Handler:       _GrpcK8SService_WaitForCrdEstablished_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WaitForReady",
+			Handler:       _GrpcK8SService_WaitForReady_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamGVRInstances",
+			Handler:       _GrpcK8SService_StreamGVRInstances_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchGVRInstances",
+			Handler:       _GrpcK8SService_WatchGVRInstances_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "GetPodLog",
 			Handler:       _GrpcK8SService_GetPodLog_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExecPod",
+			Handler:       _GrpcK8SService_ExecPod_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "PortForward",
+			Handler:       _GrpcK8SService_PortForward_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "pkg/k8sservice/protocol.proto",
 }
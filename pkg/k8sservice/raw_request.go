@@ -0,0 +1,174 @@
+package k8sservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// PatchContentTypes are the content types of the PATCH bodies, by the names
+// kubectl patch --type uses
+var PatchContentTypes = map[string]string{
+	"json":      string(types.JSONPatchType),
+	"merge":     string(types.MergePatchType),
+	"strategic": string(types.StrategicMergePatchType),
+	"apply":     string(types.ApplyYAMLPatchType),
+}
+
+// RawRequest is an http request to the api server, like kubectl get --raw
+// but with any method
+type RawRequest struct {
+	// GET if empty
+	Method string
+	// the path and query, e.g. /api/v1/namespaces/default/pods?limit=10
+	Path string
+	Body string
+	// the content type of Body, json if empty. A PATCH can use the
+	// names of PatchContentTypes
+	ContentType string
+	Headers     http.Header
+}
+
+// RawResponse is what the api server answers, an error status included
+type RawResponse struct {
+	StatusCode int
+	// e.g. "404 Not Found"
+	Status  string
+	Headers http.Header
+	Body    string
+}
+
+func (r *RawResponse) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// StatusError is the error the api server answered, nil if the request
+// succeeded. It is a StatusError if the server answered a Status.
+func (r *RawResponse) StatusError() error {
+	if r.IsSuccess() {
+		return nil
+	}
+	status := metav1.Status{}
+	if err := json.Unmarshal([]byte(r.Body), &status); err == nil && status.Kind == "Status" {
+		if status.Code == 0 {
+			status.Code = int32(r.StatusCode)
+		}
+		return &apierrors.StatusError{ErrStatus: status}
+	}
+	return fmt.Errorf("%s: %s", r.Status, strings.TrimSpace(r.Body))
+}
+
+// contentType is the content type the request body is sent with
+func (r *RawRequest) contentType() string {
+	if r.Headers.Get("Content-Type") != "" {
+		return r.Headers.Get("Content-Type")
+	}
+	if contentType, ok := PatchContentTypes[r.ContentType]; ok {
+		return contentType
+	}
+	if r.ContentType != "" {
+		return r.ContentType
+	}
+	if strings.EqualFold(r.Method, http.MethodPatch) {
+		return string(types.MergePatchType)
+	}
+	return "application/json"
+}
+
+// DoRawRequest sends req to the api server with the client's credentials.
+// A response with an error status is not an error.
+func (k *K8sClient) DoRawRequest(req *RawRequest) (*RawResponse, error) {
+	if k.config == nil {
+		return nil, fmt.Errorf("cluster not connected")
+	}
+	httpClient, err := rest.HTTPClientFor(k.config)
+	if err != nil {
+		return nil, fmt.Errorf("error in getting access to K8S: %w", err)
+	}
+	base, _, err := rest.DefaultServerUrlFor(k.config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server url: %w", err)
+	}
+	target, err := url.Parse(strings.TrimSpace(req.Path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", req.Path, err)
+	}
+	if !strings.HasPrefix(target.Path, "/") || target.Host != "" {
+		return nil, fmt.Errorf("invalid path %q, it should be absolute, e.g. /api/v1/namespaces", req.Path)
+	}
+	// the server may be behind a proxy path
+	base.Path = strings.TrimSuffix(base.Path, "/") + target.Path
+	base.RawQuery = target.RawQuery
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequest(method, base.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.Headers {
+		for _, value := range values {
+			httpReq.Header.Add(name, value)
+		}
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json, */*")
+	}
+	if req.Body != "" {
+		httpReq.Header.Set("Content-Type", req.contentType())
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %w", err)
+	}
+	return &RawResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    resp.Header,
+		Body:       string(data),
+	}, nil
+}
+
+func headersToProto(headers http.Header) []*HttpHeader {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	result := make([]*HttpHeader, 0, len(names))
+	for _, name := range names {
+		result = append(result, &HttpHeader{Name: name, Values: headers[name]})
+	}
+	return result
+}
+
+func headersFromProto(headers []*HttpHeader) http.Header {
+	result := make(http.Header)
+	for _, header := range headers {
+		for _, value := range header.Values {
+			result.Add(header.Name, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package k8sservice
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+// newTestApiServer answers with what it got
+func newTestApiServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		w.Header().Set("X-Trace", r.Header.Get("X-Trace"))
+		if r.URL.Path == "/api/v1/namespaces/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(r.URL.RequestURI() + " " + string(body)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDoRawRequest(t *testing.T) {
+	srv := newTestApiServer(t)
+	client := &K8sClient{config: &rest.Config{Host: srv.URL}}
+	remote := startTestAgent(t, &server{
+		client:      client,
+		userClients: make(map[string]*K8sClient),
+	})
+
+	cases := []struct {
+		name        string
+		req         RawRequest
+		status      int
+		contentType string
+		body        string
+	}{
+		{"get", RawRequest{Path: "/api/v1/pods?limit=1"}, 200, "", "/api/v1/pods?limit=1 "},
+		{"post", RawRequest{Method: "post", Path: "/api/v1/namespaces", Body: "{}"}, 200, "application/json", "/api/v1/namespaces {}"},
+		{"patch", RawRequest{Method: "PATCH", Path: "/api/v1/namespaces/a", Body: "{}"}, 200, "application/merge-patch+json", "/api/v1/namespaces/a {}"},
+		{"strategic", RawRequest{Method: "PATCH", Path: "/api/v1/namespaces/a", Body: "{}", ContentType: "strategic"}, 200,
+			"application/strategic-merge-patch+json", "/api/v1/namespaces/a {}"},
+		{"header", RawRequest{Method: "PUT", Path: "/api/v1/namespaces/a", Body: "a: b",
+			Headers: http.Header{"Content-Type": {"application/yaml"}, "X-Trace": {"t1"}}}, 200, "application/yaml", "/api/v1/namespaces/a a: b"},
+		{"not found", RawRequest{Method: "DELETE", Path: "/api/v1/namespaces/missing"}, 404, "", "/api/v1/namespaces/missing "},
+	}
+	for _, service := range []K8sService{&LocalK8sService{localClient: client}, remote} {
+		for _, c := range cases {
+			resp, err := service.DoRawRequest(&c.req)
+			if err != nil {
+				t.Fatalf("%s: failed request: %v", c.name, err)
+			}
+			if resp.StatusCode != c.status || resp.Body != c.body || resp.IsSuccess() != (c.status == 200) {
+				t.Errorf("%s: wrong response %+v", c.name, resp)
+			}
+			if resp.Headers.Get("X-Content-Type") != c.contentType {
+				t.Errorf("%s: wrong content type %q", c.name, resp.Headers.Get("X-Content-Type"))
+			}
+			if c.req.Headers != nil && resp.Headers.Get("X-Trace") != "t1" {
+				t.Errorf("%s: header not sent", c.name)
+			}
+		}
+	}
+
+	if _, err := client.DoRawRequest(&RawRequest{Path: "api/v1"}); err == nil {
+		t.Errorf("relative path accepted")
+	}
+}
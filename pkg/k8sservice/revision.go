@@ -0,0 +1,271 @@
+package k8sservice
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// the revisions kept per deployment, the oldest are dropped
+const MAX_REVISIONS = 10
+
+type RevisionOutcome string
+
+const (
+	RevisionDeployed   RevisionOutcome = "Deployed"
+	RevisionFailed     RevisionOutcome = "Failed"
+	RevisionRolledBack RevisionOutcome = "RolledBack"
+	// the live state of a drifted resource was adopted as deployed
+	RevisionAdopted RevisionOutcome = "Adopted"
+)
+
+// Applied tells whether the revision became what the deployment has
+// deployed
+func (o RevisionOutcome) Applied() bool {
+	return o == RevisionDeployed || o == RevisionAdopted
+}
+
+// DeployRevision is one deploy of a deployment: the resources it deployed,
+// where to and how it ended
+type DeployRevision struct {
+	Number int    `yaml:"number"`
+	Time   string `yaml:"time"`
+	// the resources by instance id, with the crs as deployed
+	Instances map[string]*common.ResourceInstanceAction `yaml:"instances,omitempty"`
+	// the target namespaces by instance id
+	Namespaces map[string]string `yaml:"namespaces,omitempty"`
+	Outcome    RevisionOutcome   `yaml:"outcome"`
+	Message    string            `yaml:"message,omitempty"`
+	// the revision it redeployed, 0 if none
+	From int `yaml:"from,omitempty"`
+}
+
+func (r *DeployRevision) ids() []string {
+	ids := make([]string, 0, len(r.Instances))
+	for id := range r.Instances {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Yaml is the crs of the revision as one yaml document stream
+func (r *DeployRevision) Yaml() string {
+	docs := make([]string, 0, len(r.Instances))
+	for _, id := range r.ids() {
+		cr := strings.TrimSpace(r.Instances[id].Instance.GetCR())
+		docs = append(docs, fmt.Sprintf("# %s namespace: %s\n%s\n", id, r.Namespaces[id], cr))
+	}
+	return strings.Join(docs, "---\n")
+}
+
+// SetNamespaces records the namespaces the resources were deployed to
+func (r *DeployRevision) SetNamespaces(finalNs map[string]types.NamespacedName) {
+	for id, ns := range finalNs {
+		if _, ok := r.Instances[id]; ok {
+			r.Namespaces[id] = ns.Namespace
+		}
+	}
+}
+
+// DiffRevisions compares the crs of two revisions
+func DiffRevisions(from *DeployRevision, to *DeployRevision) []common.DiffLine {
+	return common.DiffLines(from.Yaml(), to.Yaml())
+}
+
+func copyInstanceAction(action *common.ResourceInstanceAction) *common.ResourceInstanceAction {
+	// the instance is the one in the repository, it changes as the user
+	// edits it
+	inst := *action.Instance
+	return &common.ResourceInstanceAction{
+		Instance:  &inst,
+		Action:    common.Create,
+		DefaultNs: action.DefaultNs,
+	}
+}
+
+// NewRevision makes the revision of deploying plan of resNode, the
+// resources the user skipped stay as they are deployed. It is numbered
+// by AddRevision when the deploy ends.
+func (d *DeployedResources) NewRevision(resNode common.INode, plan *DeployPlan) (*DeployRevision, error) {
+	all, err := NewDeployDetail(resNode).ParseResources()
+	if err != nil {
+		return nil, err
+	}
+	revision := &DeployRevision{
+		Time:       time.Now().Format(time.RFC3339),
+		Instances:  make(map[string]*common.ResourceInstanceAction),
+		Namespaces: make(map[string]string),
+	}
+	current, deployed := d.resIds[resNode.GetId()]
+	keepCurrent := func(id string) {
+		if deployed {
+			if inst, ok := current.AllInstances[id]; ok && inst.GetAction() != common.Delete {
+				revision.Instances[id] = copyInstanceAction(inst)
+				if cr, ok := current.OriginalCrs[id]; ok {
+					revision.Namespaces[id] = cr.FinalNs
+				}
+			}
+		}
+	}
+	skipped := make(map[string]bool)
+	for _, item := range plan.Items {
+		if item.Skip {
+			skipped[item.Id] = true
+		}
+	}
+	for id, inst := range all {
+		if skipped[id] {
+			keepCurrent(id)
+		} else {
+			revision.Instances[id] = copyInstanceAction(inst)
+			if deployed {
+				if cr, ok := current.OriginalCrs[id]; ok {
+					revision.Namespaces[id] = cr.FinalNs
+				}
+			}
+		}
+	}
+	for id := range skipped {
+		if _, ok := all[id]; !ok {
+			// a skipped delete
+			keepCurrent(id)
+		}
+	}
+	return revision, nil
+}
+
+// AddRevision numbers revision and records it on the deployment resId.
+// A deployed or adopted revision becomes what the deployment has deployed.
+func (d *DeployedResources) AddRevision(resId string, revision *DeployRevision) {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return
+	}
+	dd.addRevision(revision)
+	d.persister.Update()
+}
+
+func (dd *DeployDetail) addRevision(revision *DeployRevision) {
+	revision.Number = 1
+	if len(dd.Revisions) > 0 {
+		revision.Number = dd.Revisions[len(dd.Revisions)-1].Number + 1
+	}
+	dd.Revisions = append(dd.Revisions, revision)
+	if len(dd.Revisions) > MAX_REVISIONS {
+		dd.Revisions = slices.Delete(dd.Revisions, 0, len(dd.Revisions)-MAX_REVISIONS)
+	}
+	if revision.Outcome.Applied() {
+		dd.AllInstances = make(map[string]*common.ResourceInstanceAction, len(revision.Instances))
+		dd.OriginalCrs = make(map[string]*common.CrInstance, len(revision.Instances))
+		for id, inst := range revision.Instances {
+			dd.AllInstances[id] = copyInstanceAction(inst)
+			cr := common.NewCrInstance(inst.Instance.GetCR())
+			cr.FinalNs = revision.Namespaces[id]
+			dd.OriginalCrs[id] = cr
+		}
+	}
+}
+
+// Failed records that a deploy of resId failed without a rollback. A new
+// deployment is removed, an existing one is put back to prior, its
+// Snapshot before the deploy, with the failed revision added.
+func (d *DeployedResources) Failed(resId string, prior *DeployDetail, revision *DeployRevision) {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return
+	}
+	if prior == nil {
+		d.Remove(resId)
+		return
+	}
+	dd.AllInstances = prior.AllInstances
+	dd.OriginalCrs = prior.OriginalCrs
+	dd.Status = prior.Status
+	revision.Outcome = RevisionFailed
+	d.AddRevision(resId, revision)
+}
+
+// GetRevision returns the revision number of the deployment resId
+func (d *DeployedResources) GetRevision(resId string, number int) (*DeployRevision, error) {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return nil, fmt.Errorf("%s is not deployed", resId)
+	}
+	for _, revision := range dd.Revisions {
+		if revision.Number == number {
+			return revision, nil
+		}
+	}
+	return nil, fmt.Errorf("no revision %d of %s", number, dd.Name)
+}
+
+// PlanRedeploy works out the plan of deploying the revision number of
+// resId again and the new revision it makes
+func (d *DeployedResources) PlanRedeploy(resId string, number int) (*DeployPlan, *DeployRevision, error) {
+	old, err := d.GetRevision(resId, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	dd := d.resIds[resId]
+	revision := &DeployRevision{
+		Time:       time.Now().Format(time.RFC3339),
+		Instances:  make(map[string]*common.ResourceInstanceAction, len(old.Instances)),
+		Namespaces: make(map[string]string, len(old.Namespaces)),
+		From:       number,
+	}
+	actions := make(map[string]*common.ResourceInstanceAction)
+	for id, inst := range old.Instances {
+		revision.Instances[id] = copyInstanceAction(inst)
+		revision.Namespaces[id] = old.Namespaces[id]
+		current, ok := dd.OriginalCrs[id]
+		if _, deployed := dd.AllInstances[id]; !ok || !deployed {
+			actions[id] = copyInstanceAction(inst)
+		} else if !current.Same(common.NewCrInstance(inst.Instance.GetCR())) {
+			actions[id] = copyInstanceAction(inst)
+			actions[id].SetAction(common.Update)
+		}
+	}
+	for id, inst := range dd.AllInstances {
+		if _, ok := old.Instances[id]; !ok && inst.GetAction() != common.Delete {
+			actions[id] = copyInstanceAction(inst)
+			actions[id].SetAction(common.Delete)
+		}
+	}
+	order, err := DeployOrder(actions, revision.Instances)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan := &DeployPlan{
+		ResId:   resId,
+		ResName: dd.Name,
+		desired: make(map[string]bool, len(revision.Instances)),
+	}
+	for id := range revision.Instances {
+		plan.desired[id] = true
+	}
+	for _, id := range order {
+		actions[id].Inventory = resId
+		item := newDeployPlanItem(id, actions[id])
+		switch item.Action.GetAction() {
+		case common.Create:
+			item.Reason = fmt.Sprintf("in revision %d", number)
+		case common.Update:
+			item.Reason = fmt.Sprintf("changed since revision %d", number)
+		case common.Delete:
+			item.Reason = fmt.Sprintf("not in revision %d", number)
+		}
+		if ns, ok := revision.Namespaces[id]; ok && ns != "" {
+			item.Namespace = ns
+		} else if cr, ok := dd.OriginalCrs[id]; ok && cr.FinalNs != "" {
+			item.Namespace = cr.FinalNs
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	return plan, revision, nil
+}
@@ -0,0 +1,101 @@
+package k8sservice
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// deployRevision records a deploy of col like the deploy task does
+func deployRevision(t *testing.T, deployed *DeployedResources, col *common.Collection, outcome RevisionOutcome) *DeployRevision {
+	plan, err := deployed.PlanDeploy(col)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	revision, err := deployed.NewRevision(col, plan)
+	if err != nil {
+		t.Fatalf("failed to make the revision: %v", err)
+	}
+	prior := deployed.Snapshot(col.GetId())
+	if _, err := deployed.LockAndAdd(col); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	finalNs := make(map[string]types.NamespacedName)
+	for _, item := range plan.Approved() {
+		finalNs[item.Id] = types.NamespacedName{Name: item.Name, Namespace: "apps"}
+	}
+	if outcome == RevisionFailed {
+		deployed.Failed(col.GetId(), prior, revision)
+		return revision
+	}
+	deployed.Deployed(col.GetId(), finalNs)
+	revision.Outcome = outcome
+	revision.SetNamespaces(finalNs)
+	deployed.AddRevision(col.GetId(), revision)
+	return revision
+}
+
+func TestRevisions(t *testing.T) {
+	deployed := &DeployedResources{
+		resIds:    make(map[string]*DeployDetail),
+		persister: &DummyPersister{},
+	}
+	first := deployRevision(t, deployed, newTestCollection("apps", map[string]string{"web": "web", "db": "db"}), RevisionDeployed)
+	second := deployRevision(t, deployed, newTestCollection("apps", map[string]string{"web": "web2", "queue": "queue"}), RevisionDeployed)
+	failed := deployRevision(t, deployed, newTestCollection("apps", map[string]string{"web": "web3"}), RevisionFailed)
+
+	detail := deployed.resIds["apps"]
+	if first.Number != 1 || second.Number != 2 || failed.Number != 3 || failed.Outcome != RevisionFailed || len(detail.Revisions) != 3 {
+		t.Fatalf("wrong revisions %v %v %v", first, second, failed)
+	}
+	if first.Namespaces["db"] != "apps" || len(second.Instances) != 2 {
+		t.Errorf("wrong revision content %v %v", first.Namespaces, second.Instances)
+	}
+	// the failed deploy leaves the deployment as the second revision
+	ids := []string{}
+	for id := range detail.AllInstances {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"queue", "web"}) || !strings.Contains(detail.OriginalCrs["web"].Cr, "app: web2") {
+		t.Errorf("wrong deployment after a failed deploy %v", ids)
+	}
+
+	changed := []string{}
+	for _, line := range DiffRevisions(first, second) {
+		if line.Op != common.DiffSame {
+			changed = append(changed, strings.TrimSpace(line.String()))
+		}
+	}
+	if !slices.Contains(changed, "-     app: db") || !slices.Contains(changed, "+     app: web2") {
+		t.Errorf("wrong diff %v", changed)
+	}
+
+	plan, revision, err := deployed.PlanRedeploy("apps", 1)
+	if err != nil {
+		t.Fatalf("failed to plan the redeploy: %v", err)
+	}
+	actions := []string{}
+	for _, item := range plan.Items {
+		actions = append(actions, item.Id+":"+item.ActionName()+":"+item.Namespace)
+	}
+	if !slices.Equal(actions, []string{"db:create:apps", "web:update:apps", "queue:delete:apps"}) {
+		t.Errorf("wrong redeploy plan %v", actions)
+	}
+	if revision.From != 1 || len(revision.Instances) != 2 {
+		t.Errorf("wrong redeploy revision %+v", revision)
+	}
+	if _, _, err := deployed.PlanRedeploy("apps", 9); err == nil {
+		t.Errorf("expected no revision 9")
+	}
+
+	for i := 0; i < MAX_REVISIONS; i++ {
+		deployRevision(t, deployed, newTestCollection("apps", map[string]string{"web": "web"}), RevisionDeployed)
+	}
+	if len(detail.Revisions) != MAX_REVISIONS || detail.Revisions[0].Number != 4 {
+		t.Errorf("wrong revisions kept %d from %d", len(detail.Revisions), detail.Revisions[0].Number)
+	}
+}
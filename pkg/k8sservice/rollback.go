@@ -0,0 +1,237 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// GetLiveResource returns the live object of res, nil if it doesn't exist
+func (k *K8sClient) GetLiveResource(res *common.ResourceInstanceAction, targetNs string) (*unstructured.Unstructured, error) {
+	if !k.IsValid() {
+		return nil, fmt.Errorf("cluster not connected")
+	}
+	obj, dr, _, err := k.resourceTarget(res, targetNs)
+	if err != nil {
+		return nil, err
+	}
+	live, err := dr.Get(context.TODO(), obj.GetName(), v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return live, err
+}
+
+// RestoreResource puts res back to prior, the object it was before a
+// deploy. A nil prior means it didn't exist and it is deleted.
+func (k *K8sClient) RestoreResource(res *common.ResourceInstanceAction, targetNs string, prior *unstructured.Unstructured) error {
+	if !k.IsValid() {
+		return fmt.Errorf("cluster not connected")
+	}
+	obj, dr, _, err := k.resourceTarget(res, targetNs)
+	if err != nil {
+		return err
+	}
+	if prior == nil {
+		logger.Info("ROLLBACK delete resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
+		if err := dr.Delete(context.TODO(), obj.GetName(), v1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	restored := prior.DeepCopy()
+	for _, field := range []string{"uid", "resourceVersion", "creationTimestamp", "deletionTimestamp",
+		"deletionGracePeriodSeconds", "generation", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(restored.Object, "metadata", field)
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		live, err := dr.Get(context.TODO(), obj.GetName(), v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			logger.Info("ROLLBACK recreate resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
+			_, err = dr.Create(context.TODO(), restored, v1.CreateOptions{FieldManager: common.APP_NAME})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		logger.Info("ROLLBACK restore resource", zap.String("name", obj.GetName()), zap.String("ns", obj.GetNamespace()))
+		restored.SetResourceVersion(live.GetResourceVersion())
+		_, err = dr.Update(context.TODO(), restored, v1.UpdateOptions{FieldManager: common.APP_NAME})
+		return err
+	})
+}
+
+// RollbackEntry is what a rollback did to one resource
+type RollbackEntry struct {
+	Id        string `yaml:"id"`
+	Kind      string `yaml:"kind,omitempty"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+	// deleted, restored or recreated
+	Undo  string `yaml:"undo"`
+	Error string `yaml:"error,omitempty"`
+
+	// the action of the deploy, kept if the resource is left deployed
+	action *common.ResourceInstanceAction
+}
+
+// RollbackReport tells why a deploy was rolled back and how each resource
+// it changed was put back
+type RollbackReport struct {
+	Time    string          `yaml:"time"`
+	Cause   string          `yaml:"cause"`
+	Entries []RollbackEntry `yaml:"entries,omitempty"`
+}
+
+// Failures returns the entries that could not be undone
+func (r *RollbackReport) Failures() []RollbackEntry {
+	failures := make([]RollbackEntry, 0)
+	for _, entry := range r.Entries {
+		if entry.Error != "" {
+			failures = append(failures, entry)
+		}
+	}
+	return failures
+}
+
+func (r *RollbackReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "rolled back %d resources after: %s", len(r.Entries), r.Cause)
+	for _, entry := range r.Entries {
+		fmt.Fprintf(&builder, "\n  %s %s/%s", entry.Undo, entry.Kind, entry.Name)
+		if entry.Error != "" {
+			fmt.Fprintf(&builder, " failed: %s", entry.Error)
+		}
+	}
+	return builder.String()
+}
+
+// appliedChange is a resource a deploy changed and what it was before
+type appliedChange struct {
+	item     *DeployPlanItem
+	targetNs string
+	// nil if the resource didn't exist
+	prior *unstructured.Unstructured
+}
+
+// DeployTransaction deploys the items of a plan recording the state of each
+// resource before it is changed, so that they can all be put back if the
+// deploy fails.
+type DeployTransaction struct {
+	client  K8sService
+	applied []*appliedChange
+}
+
+func NewDeployTransaction(client K8sService) *DeployTransaction {
+	return &DeployTransaction{
+		client: client,
+	}
+}
+
+// Deploy deploys item after recording the live state of its resource
+func (t *DeployTransaction) Deploy(item *DeployPlanItem, targetNs string) (types.NamespacedName, *unstructured.Unstructured, error) {
+	prior, err := t.client.GetLiveResource(item.Action, targetNs)
+	if err != nil {
+		return types.NamespacedName{}, nil, fmt.Errorf("failed to record the state of %s: %w", item.Name, err)
+	}
+	ns, reply, err := t.client.DeployResource(item.Action, targetNs)
+	if err != nil {
+		return ns, reply, err
+	}
+	t.applied = append(t.applied, &appliedChange{item: item, targetNs: targetNs, prior: prior})
+	return ns, reply, nil
+}
+
+// Rollback undoes the deployed items in the reverse order: the created
+// resources are deleted, the updated ones restored and the deleted ones
+// recreated. It goes on if one fails, the report tells which.
+func (t *DeployTransaction) Rollback(cause error) *RollbackReport {
+	report := &RollbackReport{
+		Time:  time.Now().Format(time.RFC3339),
+		Cause: cause.Error(),
+	}
+	for _, change := range slices.Backward(t.applied) {
+		entry := RollbackEntry{
+			Id:        change.item.Id,
+			Kind:      change.item.Kind,
+			Name:      change.item.Name,
+			Namespace: change.item.Namespace,
+			action:    change.item.Action,
+		}
+		switch {
+		case change.prior == nil:
+			entry.Undo = "deleted"
+		case change.item.Action.GetAction() == common.Delete:
+			entry.Undo = "recreated"
+		default:
+			entry.Undo = "restored"
+		}
+		if err := t.client.RestoreResource(change.item.Action, change.targetNs, change.prior); err != nil {
+			logger.Error("Failed to roll back resource", zap.String("res", change.item.Id), zap.Error(err))
+			entry.Error = err.Error()
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	t.applied = nil
+	return report
+}
+
+// Snapshot copies what is recorded of the deployment resId so that it can
+// be put back by RolledBack, nil if it is not deployed
+func (d *DeployedResources) Snapshot(resId string) *DeployDetail {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return nil
+	}
+	snapshot := *dd
+	snapshot.AllInstances = make(map[string]*common.ResourceInstanceAction, len(dd.AllInstances))
+	for id, inst := range dd.AllInstances {
+		copied := *inst
+		snapshot.AllInstances[id] = &copied
+	}
+	snapshot.OriginalCrs = make(map[string]*common.CrInstance, len(dd.OriginalCrs))
+	for id, cr := range dd.OriginalCrs {
+		copied := *cr
+		snapshot.OriginalCrs[id] = &copied
+	}
+	return &snapshot
+}
+
+// RolledBack records the report of the rolled back deploy of resId. The
+// deployment goes back to prior, its Snapshot before the deploy, or is
+// left RolledBack with nothing deployed if it is new. The resources that
+// failed to roll back stay in it so that they can be undeployed.
+func (d *DeployedResources) RolledBack(resId string, prior *DeployDetail, report *RollbackReport) {
+	dd, ok := d.resIds[resId]
+	if !ok {
+		return
+	}
+	if prior != nil {
+		dd.AllInstances = prior.AllInstances
+		dd.OriginalCrs = prior.OriginalCrs
+		dd.Status = prior.Status
+	} else {
+		dd.AllInstances = make(map[string]*common.ResourceInstanceAction)
+		dd.Status = common.StateRolledBack
+	}
+	for _, entry := range report.Failures() {
+		if _, ok := dd.AllInstances[entry.Id]; !ok && entry.action != nil && entry.Undo == "deleted" {
+			left := *entry.action
+			left.SetAction(common.Create)
+			dd.AllInstances[entry.Id] = &left
+		}
+	}
+	dd.Rollback = report
+	d.persister.Update()
+}
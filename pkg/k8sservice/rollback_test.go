@@ -0,0 +1,157 @@
+package k8sservice
+
+import (
+	"slices"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// applyPatches answers the apply patches like a server, the object is
+// created or replaced. Those of the pod named failing are rejected.
+func applyPatches(dynClient *dynamicfake.FakeDynamicClient, failing string) {
+	reject := func(action k8stesting.Action) (bool, runtime.Object, error) {
+		var name string
+		switch a := action.(type) {
+		case k8stesting.PatchAction:
+			name = a.GetName()
+		case k8stesting.CreateAction:
+			name = a.GetObject().(*unstructured.Unstructured).GetName()
+		}
+		if name == failing {
+			return true, nil, apierrors.NewBadRequest("rejected " + name)
+		}
+		return false, nil, nil
+	}
+	dynClient.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		obj.SetNamespace(patch.GetNamespace())
+		tracker := dynClient.Tracker()
+		if _, err := tracker.Get(podsGvr, patch.GetNamespace(), patch.GetName()); apierrors.IsNotFound(err) {
+			return true, obj, tracker.Create(podsGvr, obj, patch.GetNamespace())
+		}
+		return true, obj, tracker.Update(podsGvr, obj, patch.GetNamespace())
+	})
+	dynClient.PrependReactor("patch", "pods", reject)
+	dynClient.PrependReactor("create", "pods", reject)
+}
+
+func newTestPlanItems(actions ...*common.ResourceInstanceAction) []*DeployPlanItem {
+	items := []*DeployPlanItem{}
+	for _, action := range actions {
+		items = append(items, newDeployPlanItem(action.Instance.GetId(), action))
+	}
+	return items
+}
+
+func podApp(t *testing.T, dynClient *dynamicfake.FakeDynamicClient, name string) string {
+	pod, err := dynClient.Resource(podsGvr).Namespace("default").Get(t.Context(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "absent"
+	}
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", name, err)
+	}
+	return pod.GetLabels()["app"]
+}
+
+func TestDeployTransaction(t *testing.T) {
+	for _, remote := range []bool{false, true} {
+		client, dynClient := newTestDeployClient(newTestVersionedPod("db", 1, "db"), newTestVersionedPod("old", 1, "old"))
+		applyPatches(dynClient, "bad")
+		var service K8sService = &LocalK8sService{localClient: client}
+		if remote {
+			service = startTestAgent(t, &server{
+				client:      client,
+				userClients: make(map[string]*K8sClient),
+			})
+		}
+
+		tx := NewDeployTransaction(service)
+		items := newTestPlanItems(
+			newTestPodAction("web", "web", common.Create),
+			newTestPodAction("db", "db2", common.Update),
+			newTestPodAction("old", "old", common.Delete),
+			newTestPodAction("bad", "bad", common.Create))
+		var failure error
+		for _, item := range items {
+			if _, _, err := tx.Deploy(item, ""); err != nil {
+				failure = err
+				break
+			}
+		}
+		if failure == nil {
+			t.Fatalf("expected the deploy of bad to fail")
+		}
+		if podApp(t, dynClient, "web") != "web" || podApp(t, dynClient, "db") != "db2" || podApp(t, dynClient, "old") != "absent" {
+			t.Fatalf("the deploy was not applied")
+		}
+
+		report := tx.Rollback(failure)
+		undone := []string{}
+		for _, entry := range report.Entries {
+			undone = append(undone, entry.Undo+" "+entry.Name)
+		}
+		if !slices.Equal(undone, []string{"recreated old", "restored db", "deleted web"}) || len(report.Failures()) != 0 {
+			t.Errorf("remote %v: wrong report %v", remote, report)
+		}
+		if app := podApp(t, dynClient, "web"); app != "absent" {
+			t.Errorf("remote %v: web not deleted: %s", remote, app)
+		}
+		if app := podApp(t, dynClient, "db"); app != "db" {
+			t.Errorf("remote %v: db not restored: %s", remote, app)
+		}
+		if app := podApp(t, dynClient, "old"); app != "old" {
+			t.Errorf("remote %v: old not recreated: %s", remote, app)
+		}
+	}
+}
+
+func TestRolledBack(t *testing.T) {
+	deployed := &DeployedResources{
+		resIds:    make(map[string]*DeployDetail),
+		persister: &DummyPersister{},
+	}
+	first := newTestCollection("apps", map[string]string{"web": "web", "db": "db"})
+	if _, err := deployed.LockAndAdd(first); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	// web failed to be deleted, it is left deployed
+	report := &RollbackReport{Cause: "failed", Entries: []RollbackEntry{
+		{Id: "web", Name: "web", Undo: "deleted", Error: "forbidden", action: newTestPodAction("web", "web", common.Create)},
+		{Id: "db", Name: "db", Undo: "deleted"},
+	}}
+	deployed.RolledBack("apps", nil, report)
+	detail := deployed.resIds["apps"]
+	if detail.Status != common.StateRolledBack || detail.Rollback != report || len(detail.AllInstances) != 1 || detail.AllInstances["web"] == nil {
+		t.Fatalf("wrong rolled back deployment %v %v", detail.Status, detail.AllInstances)
+	}
+
+	deployed.Deployed("apps", nil)
+	if detail.Rollback != nil {
+		t.Errorf("the report is kept after a deploy")
+	}
+	prior := deployed.Snapshot("apps")
+	second := newTestCollection("apps", map[string]string{"web": "web2", "queue": "queue"})
+	if _, err := deployed.LockAndAdd(second); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	deployed.RolledBack("apps", prior, &RollbackReport{Cause: "failed"})
+	ids := []string{}
+	for id := range detail.AllInstances {
+		ids = append(ids, id)
+	}
+	if detail.Status != common.StateDeployed || !slices.Equal(ids, []string{"web"}) || detail.AllInstances["web"].Action != common.Create {
+		t.Errorf("deployment not restored %v %v", detail.Status, detail.AllInstances)
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"sync"
+	"time"
 
 	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/options"
 	"go.uber.org/zap"
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 	"gopkg.in/yaml.v3"
@@ -18,26 +23,58 @@ import (
 
 type server struct {
 	GrpcK8SServiceServer
+	// serves the rpcs without a target cluster
 	client *K8sClient
+	// the clusters the rpcs can target, nil if only client is served
+	clusters *ClusterPool
+
+	lock        sync.Mutex
+	userClients map[string]*K8sClient
+}
+
+// clusterClient returns the agent's own client of the cluster the rpc targets
+func (s *server) clusterClient(ctx context.Context) (*K8sClient, string) {
+	if target := clusterFromContext(ctx); target != nil {
+		return target.client, target.name
+	}
+	return s.client, ""
 }
 
-// Any methods should never return non-nil error unless it is fatal to the grpc server.
-// becuase if you return the error the grpc server will just quit.
-// If there is any app error please wrap it in the reply.
+// clientFor returns the client to serve the caller with. Authenticated callers
+// get a client impersonating their k8s identity, so their own RBAC applies.
+func (s *server) clientFor(ctx context.Context) *K8sClient {
+	base, cluster := s.clusterClient(ctx)
+	id := IdentityFromContext(ctx)
+	if id == nil {
+		return base
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := cluster + "/" + id.Key()
+	if c, ok := s.userClients[key]; ok {
+		return c
+	}
+	c := base.Impersonate(id.User, id.Groups)
+	s.userClients[key] = c
+	return c
+}
+
+// The methods fail with toGrpcError(err), so the caller can tell the
+// k8s api errors apart. See fromGrpcError.
 func (s *server) IsValid(ctx context.Context, req *emptypb.Empty) (*wrapperspb.BoolValue, error) {
+	// whether the agent could set up its own client, not the user's
+	client, _ := s.clusterClient(ctx)
 	result := wrapperspb.BoolValue{
-		Value: s.client.IsValid(),
+		Value: client.IsValid(),
 	}
 	return &result, nil
 }
 
-func (s *server) DeployResource(_ context.Context, resReq *DeployResourceRequest) (*DeployResourceReply, error) {
+func (s *server) DeployResource(ctx context.Context, resReq *DeployResourceRequest) (*DeployResourceReply, error) {
 	res := NewResourceInstanceAction(resReq)
-	nsn, r, err := s.client.DeployResource(res, resReq.TargetNs)
+	nsn, r, err := s.clientFor(ctx).DeployResource(res, resReq.TargetNs)
 	if err != nil {
-		return &DeployResourceReply{
-			Err: err.Error(),
-		}, nil
+		return nil, toGrpcError(err)
 	}
 
 	rJson := ""
@@ -58,8 +95,92 @@ func (s *server) DeployResource(_ context.Context, resReq *DeployResourceRequest
 	return reply, nil
 }
 
-func (s *server) GetClusterInfo(context.Context, *emptypb.Empty) (*ClusterInfoReply, error) {
-	clusterInfo := s.client.GetClusterInfo()
+func (s *server) PreviewResource(ctx context.Context, resReq *DeployResourceRequest) (*PreviewResourceReply, error) {
+	preview, err := s.clientFor(ctx).PreviewResource(NewResourceInstanceAction(resReq), resReq.TargetNs)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	reply := &PreviewResourceReply{
+		Name:         preview.Name.Name,
+		Namespace:    preview.Name.Namespace,
+		DryRunStatus: apiStatusOf(preview.DryRunErr),
+	}
+	if reply.LiveJson, err = marshalObject(preview.Live); err != nil {
+		return nil, toGrpcError(err)
+	}
+	if reply.DryRunJson, err = marshalObject(preview.DryRun); err != nil {
+		return nil, toGrpcError(err)
+	}
+	return reply, nil
+}
+
+func (s *server) GetLiveResource(ctx context.Context, resReq *DeployResourceRequest) (*wrapperspb.StringValue, error) {
+	live, err := s.clientFor(ctx).GetLiveResource(NewResourceInstanceAction(resReq), resReq.TargetNs)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	liveJson, err := marshalObject(live)
+	if err != nil {
+		return nil, toGrpcError(err)
+	}
+	return wrapperspb.String(liveJson), nil
+}
+
+func (s *server) RestoreResource(ctx context.Context, req *RestoreResourceRequest) (*emptypb.Empty, error) {
+	prior, err := unmarshalObject(req.PriorJson)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid prior object: %v", err)
+	}
+	if err := s.clientFor(ctx).RestoreResource(NewResourceInstanceAction(req.Resource), req.Resource.GetTargetNs(), prior); err != nil {
+		return nil, toGrpcError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) WaitForCrdEstablished(req *WaitForCrdRequest, streamServer grpc.ServerStreamingServer[wrapperspb.StringValue]) error {
+	timeout := time.Duration(req.TimeoutMs) * time.Millisecond
+	client := s.clientFor(streamServer.Context())
+	err := client.WaitForCrdEstablished(req.Name, timeout, func(message string) {
+		if err := streamServer.Send(wrapperspb.String(message)); err != nil {
+			logger.Debug("failed to send crd progress", zap.Error(err))
+		}
+	})
+	if err != nil {
+		return toGrpcError(err)
+	}
+	// the api resources are served from the cluster's client, an
+	// impersonating client only drops its own copy
+	if base, _ := s.clusterClient(streamServer.Context()); base != client {
+		base.InvalidateDiscovery()
+	}
+	return nil
+}
+
+func (s *server) WaitForReady(req *WaitForReadyRequest, streamServer grpc.ServerStreamingServer[HealthReply]) error {
+	send := func(health ResourceHealth) error {
+		return streamServer.Send(&HealthReply{
+			State:   string(health.State),
+			Message: health.Message,
+			Checked: health.Checked,
+		})
+	}
+	timeout := time.Duration(req.TimeoutMs) * time.Millisecond
+	health, err := s.clientFor(streamServer.Context()).WaitForReady(NewResourceInstanceAction(req.Resource), req.Resource.TargetNs, timeout, func(health ResourceHealth) {
+		if err := send(health); err != nil {
+			logger.Debug("failed to send health", zap.Error(err))
+		}
+	})
+	if err != nil {
+		return toGrpcError(err)
+	}
+	return send(health)
+}
+
+func (s *server) GetClusterInfo(ctx context.Context, _ *emptypb.Empty) (*ClusterInfoReply, error) {
+	// the api server url and the id the agent keeps for the cluster,
+	// the same for every user
+	client, _ := s.clusterClient(ctx)
+	clusterInfo := client.GetClusterInfo()
 	reply := ClusterInfoReply{
 		Host: clusterInfo.Host,
 		Id:   clusterInfo.Id,
@@ -68,7 +189,11 @@ func (s *server) GetClusterInfo(context.Context, *emptypb.Empty) (*ClusterInfoRe
 }
 
 func (s *server) FetchAllApiResources(ctx context.Context, req *wrapperspb.BoolValue) (*ApiResourceInfoReply, error) {
-	allRes := s.client.FetchAllApiResources(req.Value)
+	// discovery is shared by all users of a cluster and served with the
+	// agent's identity, k8s lets any authenticated user read it (and the
+	// openapi schemas) through the system:discovery role anyway
+	client, _ := s.clusterClient(ctx)
+	allRes := client.FetchAllApiResources(req.Value)
 
 	if allRes == nil {
 		return &ApiResourceInfoReply{}, nil
@@ -101,19 +226,15 @@ func (s *server) FetchAllApiResources(ctx context.Context, req *wrapperspb.BoolV
 }
 
 func (s *server) FetchGVRInstances(ctx context.Context, req *FetchGvrRequest) (*GvrReply, error) {
-	result, err := s.client.FetchGVRInstances(req.G, req.V, req.R, req.Ns)
+	result, err := s.clientFor(ctx).FetchGVRInstances(req.G, req.V, req.R, req.Ns, listOptionsFor(req))
 	if err != nil {
-		return &GvrReply{
-			Error: err.Error(),
-		}, nil
+		return nil, toGrpcError(err)
 	}
 
 	rjson, err := json.Marshal(result)
 
 	if err != nil {
-		return &GvrReply{
-			Error: err.Error(),
-		}, nil
+		return nil, toGrpcError(err)
 	}
 
 	return &GvrReply{
@@ -121,12 +242,57 @@ func (s *server) FetchGVRInstances(ctx context.Context, req *FetchGvrRequest) (*
 	}, nil
 }
 
-func (s *server) FetchAllNamespaces(context.Context, *emptypb.Empty) (*AllNamespacesReply, error) {
-	allNs, err := s.client.FetchAllNamespaces()
+func listOptionsFor(req *FetchGvrRequest) v1.ListOptions {
+	return v1.ListOptions{
+		ResourceVersion: req.ResourceVersion,
+		LabelSelector:   req.LabelSelector,
+		FieldSelector:   req.FieldSelector,
+		Limit:           req.Limit,
+		Continue:        req.Continue,
+	}
+}
+
+func (s *server) WatchGVRInstances(req *FetchGvrRequest, streamServer grpc.ServerStreamingServer[WatchEvent]) error {
+	ctx := streamServer.Context()
+	w, err := s.clientFor(ctx).WatchGVRInstances(req.G, req.V, req.R, req.Ns, listOptionsFor(req))
 	if err != nil {
-		return &AllNamespacesReply{
-			Error: err.Error(),
-		}, nil
+		logger.Info("error starting watch", zap.Error(err))
+		return toGrpcError(err)
+	}
+	defer w.Stop()
+	// tells the caller the watch is started
+	if err := streamServer.SendHeader(nil); err != nil {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			objJson, err := json.Marshal(event.Object)
+			if err != nil {
+				logger.Warn("failed to marshal watch event", zap.Error(err))
+				continue
+			}
+			err = streamServer.Send(&WatchEvent{
+				Type:       string(event.Type),
+				ObjectJson: string(objJson),
+			})
+			if err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *server) FetchAllNamespaces(ctx context.Context, _ *emptypb.Empty) (*AllNamespacesReply, error) {
+	allNs, err := s.clientFor(ctx).FetchAllNamespaces()
+	if err != nil {
+		return nil, toGrpcError(err)
 	}
 	return &AllNamespacesReply{
 		Namespaces: allNs,
@@ -140,16 +306,37 @@ func (s *server) GetPodLog(req *PodLogRequest, streamServer grpc.ServerStreaming
 	err := json.Unmarshal([]byte(req.PodRawJson), podRaw)
 	if err != nil {
 		logger.Error("failed to unmarshal pod", zap.Error(err))
-		return nil
+		return status.Errorf(codes.InvalidArgument, "invalid pod: %v", err)
 	}
 
-	logReader, err := s.client.GetPodLog(podRaw, req.Container)
+	opts := PodLogOptions{
+		Follow:       req.Follow,
+		TailLines:    req.TailLines,
+		SinceSeconds: req.SinceSeconds,
+		Timestamps:   req.Timestamps,
+		Previous:     req.Previous,
+	}
+	if req.SinceTime != "" {
+		since, err := time.Parse(time.RFC3339, req.SinceTime)
+		if err != nil {
+			logger.Info("invalid since time", zap.String("since", req.SinceTime), zap.Error(err))
+			return status.Errorf(codes.InvalidArgument, "invalid since time %q", req.SinceTime)
+		}
+		opts.SinceTime = &since
+	}
+
+	ctx := streamServer.Context()
+	logReader, err := s.clientFor(ctx).GetPodLog(ctx, podRaw, req.Container, opts)
 
 	if err != nil {
 		logger.Info("error getting pod log", zap.Error(err))
-		return nil
+		return toGrpcError(err)
 	}
 	defer logReader.Close()
+	// tells the caller the log is opened
+	if err := streamServer.SendHeader(nil); err != nil {
+		return nil
+	}
 
 	for {
 		bts := make([]byte, 2048)
@@ -159,7 +346,10 @@ func (s *server) GetPodLog(req *PodLogRequest, streamServer grpc.ServerStreaming
 			msg := wrapperspb.StringValue{
 				Value: string(bts[:n]),
 			}
-			streamServer.Send(&msg)
+			if err := streamServer.Send(&msg); err != nil {
+				// the caller is gone, e.g. stopped following
+				return nil
+			}
 		} else if err != nil {
 			// here we just return nil
 			// grpc will take care and return correct EOF
@@ -168,9 +358,11 @@ func (s *server) GetPodLog(req *PodLogRequest, streamServer grpc.ServerStreaming
 	}
 }
 
-func (s *server) GetClusterName(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+func (s *server) GetClusterName(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.StringValue, error) {
+	// the name from the agent's kubeconfig
+	client, _ := s.clusterClient(ctx)
 	return &wrapperspb.StringValue{
-		Value: s.client.GetClusterName(),
+		Value: client.GetClusterName(),
 	}, nil
 }
 
@@ -184,17 +376,13 @@ func (s *server) GetCRDFor(ctx context.Context, req *ApiResourceEntry) (*CrdRepl
 	apiRes := &v1.APIResource{}
 	err := json.Unmarshal([]byte(req.ApiResourceJson), apiRes)
 	if err != nil {
-		return &CrdReply{
-			Error: err.Error(),
-		}, nil
+		return nil, status.Errorf(codes.InvalidArgument, "invalid api resource: %v", err)
 	}
 	entry.ApiRes = apiRes
 
-	crd, err := s.client.GetCRDFor(entry)
+	crd, err := s.clientFor(ctx).GetCRDFor(entry)
 	if err != nil {
-		return &CrdReply{
-			Error: err.Error(),
-		}, nil
+		return nil, toGrpcError(err)
 	}
 
 	return &CrdReply{
@@ -202,23 +390,19 @@ func (s *server) GetCRDFor(ctx context.Context, req *ApiResourceEntry) (*CrdRepl
 	}, nil
 }
 
-func (s *server) GetDescribeFor(_ context.Context, req *wrapperspb.StringValue) (*GetDescribeForReply, error) {
+func (s *server) GetDescribeFor(ctx context.Context, req *wrapperspb.StringValue) (*GetDescribeForReply, error) {
 
 	itemRaw := &unstructured.Unstructured{}
 
 	err := json.Unmarshal([]byte(req.Value), itemRaw)
 	if err != nil {
-		return &GetDescribeForReply{
-			Error: err.Error(),
-		}, nil
+		return nil, status.Errorf(codes.InvalidArgument, "invalid item: %v", err)
 	}
 
-	describe, err := s.client.GetDescribeFor(itemRaw)
+	describe, err := s.clientFor(ctx).GetDescribeFor(itemRaw)
 
 	if err != nil {
-		return &GetDescribeForReply{
-			Error: err.Error(),
-		}, nil
+		return nil, toGrpcError(err)
 	}
 
 	return &GetDescribeForReply{
@@ -227,15 +411,33 @@ func (s *server) GetDescribeFor(_ context.Context, req *wrapperspb.StringValue)
 
 }
 
-func (s *server) DoRawRequest(_ context.Context, req *wrapperspb.StringValue) (*RawRequestReply, error) {
-	resp, err := s.client.DoRawRequest(req.Value)
+func (s *server) DoRawRequest(ctx context.Context, req *RawApiRequest) (*RawRequestReply, error) {
+	resp, err := s.clientFor(ctx).DoRawRequest(&RawRequest{
+		Method:      req.Method,
+		Path:        req.Path,
+		Body:        req.Body,
+		ContentType: req.ContentType,
+		Headers:     headersFromProto(req.Headers),
+	})
 	if err != nil {
-		return &RawRequestReply{
-			Error: err.Error(),
-		}, nil
+		return nil, toGrpcError(err)
 	}
 	return &RawRequestReply{
-		Response: resp,
+		Response:   resp.Body,
+		StatusCode: int32(resp.StatusCode),
+		Status:     resp.Status,
+		Headers:    headersToProto(resp.Headers),
+	}, nil
+}
+
+func (s *server) ListClusters(ctx context.Context, _ *emptypb.Empty) (*ListClustersReply, error) {
+	if s.clusters == nil {
+		return nil, status.Error(codes.Unimplemented, "the agent serves a single cluster")
+	}
+	names, defaultName := s.clusters.Names()
+	return &ListClustersReply{
+		Clusters:       names,
+		DefaultCluster: defaultName,
 	}, nil
 }
 
@@ -243,6 +445,9 @@ func NewResourceInstanceAction(req *DeployResourceRequest) *common.ResourceInsta
 	action := common.ResourceInstanceAction{}
 	action.Action = common.ResourceAction(req.Action)
 	action.DefaultNs = req.DefaultNs
+	action.DryRun = req.DryRun
+	action.Force = req.Force
+	action.Inventory = req.Inventory
 	action.Instance = &common.ResourceInstance{
 		Id: req.Id,
 		Spec: &common.ResourceSpec{
@@ -285,10 +490,52 @@ func Run() {
 		grpc.UnaryInterceptor(logErr),
 	}
 
+	if options.Options.Tls.Enabled {
+		creds, err := NewServerCredentials(&options.Options.Tls)
+		if err != nil {
+			logger.Error("failed to setup tls", zap.Error(err))
+			return
+		}
+		opts = append(opts, grpc.Creds(creds))
+		logger.Info("agent tls enabled", zap.Bool("client-auth", options.Options.Tls.ClientAuth))
+	}
+
+	if options.Options.Auth.Enabled {
+		auth, err := NewAuthenticator(&options.Options.Auth)
+		if err != nil {
+			logger.Error("invalid agent auth config", zap.Error(err))
+			return
+		}
+		opts = append(opts, grpc.ChainUnaryInterceptor(auth.UnaryInterceptor),
+			grpc.ChainStreamInterceptor(auth.StreamInterceptor))
+		if !options.Options.Tls.Enabled {
+			logger.Warn("agent auth is enabled without tls, tokens are sent in plain text")
+		}
+		logger.Info("agent auth enabled", zap.Int("users", len(options.Options.Auth.Users)))
+	}
+
+	if options.Options.InformerCache {
+		getInternalClient().EnableInformerCache(options.Options.InformerIdleTimeout)
+		logger.Info("informer cache enabled", zap.Duration("idle-timeout", options.Options.InformerIdleTimeout))
+	}
+
+	// the target cluster is resolved after the caller is authenticated
+	clusters, err := newAgentClusterPool()
+	if err != nil {
+		logger.Error("failed to load the clusters to serve", zap.Error(err))
+		return
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(clusters.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(clusters.StreamInterceptor))
+	names, defaultName := clusters.Names()
+	logger.Info("serving clusters", zap.Strings("clusters", names), zap.String("default", defaultName))
+
 	s := grpc.NewServer(opts...)
 
 	RegisterGrpcK8SServiceServer(s, &server{
-		client: internalClient,
+		client:      getInternalClient(),
+		clusters:    clusters,
+		userClients: make(map[string]*K8sClient),
 	})
 
 	logger.Info("server listening", zap.String("port", "8080"))
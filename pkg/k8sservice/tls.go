@@ -0,0 +1,251 @@
+package k8sservice
+
+import (
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/options"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	AGENT_CA_CERT     = "ca.crt"
+	AGENT_CA_KEY      = "ca.key"
+	AGENT_SERVER_CERT = "server.crt"
+	AGENT_SERVER_KEY  = "server.key"
+	AGENT_CLIENT_CERT = "client.crt"
+	AGENT_CLIENT_KEY  = "client.key"
+
+	agentCertMonths = 24
+)
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid certificates in %s", caFile)
+	}
+	return pool, nil
+}
+
+// NewServerTlsConfig creates the tls config the agent uses to serve grpc.
+// If ClientAuth is set every client must present a cert signed by CaFile.
+func NewServerTlsConfig(opts *options.TlsOptions) (*tls.Config, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, fmt.Errorf("agent tls needs both a certificate and a key")
+	}
+	cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent key pair: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if opts.ClientAuth {
+		if opts.CaFile == "" {
+			return nil, fmt.Errorf("client auth needs a ca to verify client certificates")
+		}
+		pool, err := loadCertPool(opts.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// NewClientTlsConfig creates the tls config the gui uses to connect to an agent.
+// Without CaFile the system roots are used to verify the agent.
+func NewClientTlsConfig(opts *options.TlsOptions) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: opts.ServerName,
+	}
+	if opts.CaFile != "" {
+		pool, err := loadCertPool(opts.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func NewServerCredentials(opts *options.TlsOptions) (credentials.TransportCredentials, error) {
+	cfg, err := NewServerTlsConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+func NewClientCredentials(opts *options.TlsOptions) (credentials.TransportCredentials, error) {
+	cfg, err := NewClientTlsConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+func writeAgentPem(dir string, name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(filepath.Join(dir, name), data, perm)
+}
+
+// agentServerHosts are the names the bootstrapped server cert is issued for
+func agentServerHosts(hosts []string) []string {
+	serverHosts := append([]string{"localhost", "127.0.0.1", "::1"}, hosts...)
+	if hostname, err := os.Hostname(); err == nil {
+		serverHosts = append(serverHosts, hostname)
+	}
+	return serverHosts
+}
+
+// certCovers tells whether the cert in certFile is valid for all the hosts
+func certCovers(certFile string, hosts []string) bool {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return false
+	}
+	certs, err := common.ParseCerts(data)
+	if err != nil || len(certs) == 0 {
+		return false
+	}
+	for _, h := range hosts {
+		if h != "" && certs[0].VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// issueAgentCert issues a cert signed by the agent ca and writes it with its
+// key into dir
+func issueAgentCert(dir string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, cn string, names []string,
+	client bool, certName string, keyName string) error {
+	key, err := common.NewEcdsaKey()
+	if err != nil {
+		return err
+	}
+	der, err := common.NewSignedCert(cn, names, client, key, ca, caKey, agentCertMonths)
+	if err != nil {
+		return err
+	}
+	keyPem, err := common.EncodeEcdsaKeyPem(key)
+	if err != nil {
+		return err
+	}
+	if err := writeAgentPem(dir, certName, common.EncodeCertPem(der), 0644); err != nil {
+		return err
+	}
+	return writeAgentPem(dir, keyName, keyPem, 0600)
+}
+
+// loadAgentCa loads the bootstrapped agent ca and its key from dir
+func loadAgentCa(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(filepath.Join(dir, AGENT_CA_CERT))
+	if err != nil {
+		return nil, nil, err
+	}
+	certs, err := common.ParseCerts(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("no certificate in %s", AGENT_CA_CERT)
+	}
+	keyData, err := os.ReadFile(filepath.Join(dir, AGENT_CA_KEY))
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := common.DecodeEcdsaKeyPem(keyData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", AGENT_CA_KEY, err)
+	}
+	return certs[0], key, nil
+}
+
+// BootstrapAgentCerts makes sure there is a self-signed agent CA in dir, with a
+// server cert (for the given hosts) and a client cert signed by it.
+// Existing files are reused, except a server cert not valid for all the hosts
+// which is reissued by the existing CA. The returned options are for the agent
+// side, the gui needs ca.crt, client.crt and client.key copied over.
+func BootstrapAgentCerts(dir string, hosts []string) (*options.TlsOptions, error) {
+	result := &options.TlsOptions{
+		Enabled:    true,
+		CertFile:   filepath.Join(dir, AGENT_SERVER_CERT),
+		KeyFile:    filepath.Join(dir, AGENT_SERVER_KEY),
+		CaFile:     filepath.Join(dir, AGENT_CA_CERT),
+		ClientAuth: true,
+		Hosts:      hosts,
+	}
+	serverHosts := agentServerHosts(hosts)
+
+	if _, err := os.Stat(result.CaFile); err == nil {
+		if certCovers(result.CertFile, serverHosts) {
+			logger.Info("reusing agent certificates", zap.String("dir", dir))
+			return result, nil
+		}
+		ca, caKey, err := loadAgentCa(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the agent ca: %w", err)
+		}
+		if err := issueAgentCert(dir, ca, caKey, common.APP_NAME+"-agent", serverHosts, false, AGENT_SERVER_CERT, AGENT_SERVER_KEY); err != nil {
+			return nil, err
+		}
+		logger.Info("reissued agent server certificate", zap.String("dir", dir), zap.Strings("hosts", serverHosts))
+		return result, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	caKey, err := common.NewEcdsaKey()
+	if err != nil {
+		return nil, err
+	}
+	ca, caDer, err := common.NewCACert(common.APP_NAME+"-agent-ca", caKey, agentCertMonths)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := issueAgentCert(dir, ca, caKey, common.APP_NAME+"-agent", serverHosts, false, AGENT_SERVER_CERT, AGENT_SERVER_KEY); err != nil {
+		return nil, err
+	}
+	if err := issueAgentCert(dir, ca, caKey, common.APP_NAME+"-gui", nil, true, AGENT_CLIENT_CERT, AGENT_CLIENT_KEY); err != nil {
+		return nil, err
+	}
+
+	caKeyPem, err := common.EncodeEcdsaKeyPem(caKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeAgentPem(dir, AGENT_CA_KEY, caKeyPem, 0600); err != nil {
+		return nil, err
+	}
+	// ca.crt is written last as it marks the bootstrap as complete
+	if err := writeAgentPem(dir, AGENT_CA_CERT, common.EncodeCertPem(caDer), 0644); err != nil {
+		return nil, err
+	}
+
+	logger.Info("bootstrapped agent certificates", zap.String("dir", dir))
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package k8sservice
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/options"
+)
+
+func handshake(t *testing.T, serverCfg *tls.Config, clientCfg *tls.Config) (error, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer conn.Close()
+		srv := tls.Server(conn, serverCfg)
+		err = srv.Handshake()
+		if err == nil {
+			// wait for the client to close the connection
+			_, err = srv.Read(make([]byte, 1))
+			if err == io.EOF {
+				err = nil
+			}
+		}
+		errCh <- err
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	client := tls.Client(conn, clientCfg)
+	cErr := client.Handshake()
+	client.Close()
+	return <-errCh, cErr
+}
+
+func TestAgentTls(t *testing.T) {
+	dir := t.TempDir()
+
+	serverOpts, err := BootstrapAgentCerts(dir, []string{"agent.example.com"})
+	if err != nil {
+		t.Fatalf("failed to bootstrap certs: %v", err)
+	}
+	if !serverOpts.ClientAuth || !serverOpts.Enabled {
+		t.Fatalf("bootstrap should enable mutual tls: %v", serverOpts)
+	}
+
+	serverCfg, err := NewServerTlsConfig(serverOpts)
+	if err != nil {
+		t.Fatalf("failed to create server config: %v", err)
+	}
+
+	t.Run("mutual tls", func(t *testing.T) {
+		clientCfg, err := NewClientTlsConfig(&options.TlsOptions{
+			Enabled:    true,
+			CaFile:     filepath.Join(dir, AGENT_CA_CERT),
+			CertFile:   filepath.Join(dir, AGENT_CLIENT_CERT),
+			KeyFile:    filepath.Join(dir, AGENT_CLIENT_KEY),
+			ServerName: "agent.example.com",
+		})
+		if err != nil {
+			t.Fatalf("failed to create client config: %v", err)
+		}
+		sErr, cErr := handshake(t, serverCfg, clientCfg)
+		if sErr != nil || cErr != nil {
+			t.Fatalf("handshake failed, server: %v, client: %v", sErr, cErr)
+		}
+	})
+
+	t.Run("missing client cert", func(t *testing.T) {
+		clientCfg, err := NewClientTlsConfig(&options.TlsOptions{
+			Enabled:    true,
+			CaFile:     filepath.Join(dir, AGENT_CA_CERT),
+			ServerName: "localhost",
+		})
+		if err != nil {
+			t.Fatalf("failed to create client config: %v", err)
+		}
+		sErr, _ := handshake(t, serverCfg, clientCfg)
+		if sErr == nil {
+			t.Errorf("server should reject a client without cert")
+		}
+	})
+
+	t.Run("wrong server name", func(t *testing.T) {
+		clientCfg, err := NewClientTlsConfig(&options.TlsOptions{
+			Enabled:    true,
+			CaFile:     filepath.Join(dir, AGENT_CA_CERT),
+			CertFile:   filepath.Join(dir, AGENT_CLIENT_CERT),
+			KeyFile:    filepath.Join(dir, AGENT_CLIENT_KEY),
+			ServerName: "other.example.com",
+		})
+		if err != nil {
+			t.Fatalf("failed to create client config: %v", err)
+		}
+		_, cErr := handshake(t, serverCfg, clientCfg)
+		if cErr == nil {
+			t.Errorf("client should reject the agent cert for a different host")
+		}
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		again, err := BootstrapAgentCerts(dir, nil)
+		if err != nil {
+			t.Fatalf("failed to reuse certs: %v", err)
+		}
+		if again.CaFile != serverOpts.CaFile {
+			t.Errorf("expected the same ca file, got %s", again.CaFile)
+		}
+	})
+
+	t.Run("reissue for new hosts", func(t *testing.T) {
+		caBefore, _ := os.ReadFile(filepath.Join(dir, AGENT_CA_CERT))
+		clientBefore, _ := os.ReadFile(filepath.Join(dir, AGENT_CLIENT_CERT))
+		again, err := BootstrapAgentCerts(dir, []string{"agent.example.com", "10.0.0.7"})
+		if err != nil {
+			t.Fatalf("failed to reissue certs: %v", err)
+		}
+		caAfter, _ := os.ReadFile(filepath.Join(dir, AGENT_CA_CERT))
+		clientAfter, _ := os.ReadFile(filepath.Join(dir, AGENT_CLIENT_CERT))
+		if !bytes.Equal(caBefore, caAfter) || !bytes.Equal(clientBefore, clientAfter) {
+			t.Errorf("expected the ca and the client cert to be kept")
+		}
+		if !certCovers(again.CertFile, []string{"agent.example.com", "10.0.0.7", "localhost"}) {
+			t.Fatalf("server cert not reissued for the new hosts")
+		}
+		serverCfg, err := NewServerTlsConfig(again)
+		if err != nil {
+			t.Fatalf("failed to create server config: %v", err)
+		}
+		clientCfg, err := NewClientTlsConfig(&options.TlsOptions{
+			Enabled:    true,
+			CaFile:     filepath.Join(dir, AGENT_CA_CERT),
+			CertFile:   filepath.Join(dir, AGENT_CLIENT_CERT),
+			KeyFile:    filepath.Join(dir, AGENT_CLIENT_KEY),
+			ServerName: "10.0.0.7",
+		})
+		if err != nil {
+			t.Fatalf("failed to create client config: %v", err)
+		}
+		sErr, cErr := handshake(t, serverCfg, clientCfg)
+		if sErr != nil || cErr != nil {
+			t.Fatalf("handshake with the reissued cert failed, server: %v, client: %v", sErr, cErr)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package k8sservice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// the encodings of the items streamed by StreamGVRInstances
+const (
+	ItemEncodingCbor = "cbor"
+	ItemEncodingJson = "json"
+)
+
+// a GvrItems message ends after this many items, or once its items pass gvrBatchBytes
+const (
+	gvrBatchItems = 500
+	gvrBatchBytes = 1024 * 1024
+)
+
+// cborDecMode decodes into the types of an unstructured object
+var cborDecMode, _ = cbor.DecOptions{
+	DefaultMapType:   reflect.TypeOf(map[string]any(nil)),
+	IntDec:           cbor.IntDecConvertSignedOrFail,
+	MaxArrayElements: 1024 * 1024,
+	MaxMapPairs:      1024 * 1024,
+}.DecMode()
+
+// itemCodec encodes the content of an unstructured object on its own
+type itemCodec struct {
+	encode func(obj map[string]any) ([]byte, error)
+	decode func(data []byte) (map[string]any, error)
+}
+
+var itemCodecs = map[string]itemCodec{
+	ItemEncodingCbor: {
+		encode: func(obj map[string]any) ([]byte, error) {
+			return cbor.Marshal(obj)
+		},
+		decode: func(data []byte) (map[string]any, error) {
+			obj := make(map[string]any)
+			err := cborDecMode.Unmarshal(data, &obj)
+			return obj, err
+		},
+	},
+	ItemEncodingJson: {
+		encode: func(obj map[string]any) ([]byte, error) {
+			return json.Marshal(obj)
+		},
+		decode: func(data []byte) (map[string]any, error) {
+			// numbers are int64 or float64, as in any unstructured object
+			obj := make(map[string]any)
+			err := json.Unmarshal(data, &obj)
+			return obj, err
+		},
+	},
+}
+
+func itemCodecFor(encoding string) (itemCodec, error) {
+	if encoding == "" {
+		encoding = ItemEncodingCbor
+	}
+	codec, ok := itemCodecs[encoding]
+	if !ok {
+		return itemCodec{}, fmt.Errorf("unknown item encoding %q", encoding)
+	}
+	return codec, nil
+}
+
+func (s *server) StreamGVRInstances(req *FetchGvrRequest, streamServer grpc.ServerStreamingServer[GvrItems]) error {
+	codec, err := itemCodecFor(req.Encoding)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result, err := s.clientFor(streamServer.Context()).FetchGVRInstances(req.G, req.V, req.R, req.Ns, listOptionsFor(req))
+	if err != nil {
+		return toGrpcError(err)
+	}
+
+	list, err := codec.encode(result.Object)
+	if err != nil {
+		return toGrpcError(err)
+	}
+	batch := &GvrItems{List: list}
+	size := 0
+	for i := range result.Items {
+		item, err := codec.encode(result.Items[i].Object)
+		if err != nil {
+			return toGrpcError(err)
+		}
+		batch.Items = append(batch.Items, item)
+		size += len(item)
+		if len(batch.Items) >= gvrBatchItems || size >= gvrBatchBytes {
+			if err := streamServer.Send(batch); err != nil {
+				return err
+			}
+			batch = &GvrItems{}
+			size = 0
+		}
+	}
+	// the list goes out even if it has no items
+	if batch.List != nil || len(batch.Items) > 0 {
+		return streamServer.Send(batch)
+	}
+	return nil
+}
+
+// streamGVRInstances reads the list StreamGVRInstances sends a batch at a time
+func (r *RemoteK8sService) streamGVRInstances(request *FetchGvrRequest, callOpts ...grpc.CallOption) (*unstructured.UnstructuredList, error) {
+	codec, err := itemCodecFor(request.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcClient := NewGrpcK8SServiceClient(r.Conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := grpcClient.StreamGVRInstances(ctx, request, callOpts...)
+	if err != nil {
+		return nil, fromGrpcError(err)
+	}
+
+	result := &unstructured.UnstructuredList{Object: make(map[string]any)}
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fromGrpcError(err)
+		}
+		if batch.List != nil {
+			if result.Object, err = codec.decode(batch.List); err != nil {
+				return nil, fmt.Errorf("failed to decode list: %w", err)
+			}
+		}
+		for _, data := range batch.Items {
+			obj, err := codec.decode(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode item: %w", err)
+			}
+			result.Items = append(result.Items, unstructured.Unstructured{Object: obj})
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package k8sservice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestFullPod is a pod with the usual amount of spec and status
+func newTestFullPod(i int) map[string]any {
+	name := fmt.Sprintf("web-%d", i)
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":              name,
+			"namespace":         "default",
+			"uid":               fmt.Sprintf("0c1e3b4a-%012d", i),
+			"resourceVersion":   fmt.Sprintf("%d", 1000+i),
+			"creationTimestamp": "2024-05-01T10:00:00Z",
+			"labels":            map[string]any{"app": "web", "pod-template-hash": "5d8f9c7b6"},
+			"annotations":       map[string]any{"kubectl.kubernetes.io/restartedAt": "2024-05-01T09:59:00Z"},
+			"ownerReferences": []any{map[string]any{
+				"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web-5d8f9c7b6",
+				"uid": "9a7c2d11-0000-0000-0000-000000000001", "controller": true, "blockOwnerDeletion": true,
+			}},
+		},
+		"spec": map[string]any{
+			"containers": []any{map[string]any{
+				"name":  "web",
+				"image": "registry.example.com/web:1.2.3",
+				"ports": []any{map[string]any{"containerPort": int64(8080), "protocol": "TCP"}},
+				"env": []any{
+					map[string]any{"name": "LOG_LEVEL", "value": "info"},
+					map[string]any{"name": "POD_NAME", "valueFrom": map[string]any{"fieldRef": map[string]any{"fieldPath": "metadata.name"}}},
+				},
+				"resources": map[string]any{
+					"limits":   map[string]any{"cpu": "500m", "memory": "256Mi"},
+					"requests": map[string]any{"cpu": "100m", "memory": "128Mi"},
+				},
+				"readinessProbe": map[string]any{
+					"httpGet":             map[string]any{"path": "/healthz", "port": int64(8080)},
+					"initialDelaySeconds": int64(5),
+					"periodSeconds":       int64(10),
+				},
+			}},
+			"nodeName":                      fmt.Sprintf("node-%d", i%20),
+			"restartPolicy":                 "Always",
+			"terminationGracePeriodSeconds": int64(30),
+		},
+		"status": map[string]any{
+			"phase":  "Running",
+			"podIP":  fmt.Sprintf("10.1.%d.%d", i/250, i%250),
+			"hostIP": fmt.Sprintf("192.168.0.%d", i%20),
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "True", "lastTransitionTime": "2024-05-01T10:00:10Z"},
+				map[string]any{"type": "ContainersReady", "status": "True", "lastTransitionTime": "2024-05-01T10:00:10Z"},
+			},
+			"containerStatuses": []any{map[string]any{
+				"name": "web", "ready": true, "restartCount": int64(i % 3), "started": true,
+				"image":   "registry.example.com/web:1.2.3",
+				"imageID": "registry.example.com/web@sha256:3f1a2b",
+				"state":   map[string]any{"running": map[string]any{"startedAt": "2024-05-01T10:00:05Z"}},
+			}},
+			"startTime": "2024-05-01T10:00:00Z",
+		},
+	}
+}
+
+func newTestPodList(n int) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "PodList",
+		"metadata":   map[string]any{"resourceVersion": "12345", "continue": "next-page"},
+	}}
+	for i := range n {
+		list.Items = append(list.Items, unstructured.Unstructured{Object: newTestFullPod(i)})
+	}
+	return list
+}
+
+// newTestListAgent is an agent whose pod list is always list
+func newTestListAgent(t testing.TB, list *unstructured.UnstructuredList) *RemoteK8sService {
+	remote, dynClient := newTestAgent(t)
+	dynClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, list, nil
+	})
+	return remote
+}
+
+func TestStreamGVRInstances(t *testing.T) {
+	list := newTestPodList(1200)
+	remote := newTestListAgent(t, list)
+
+	request := newFetchGvrRequest("", "v1", "pods", "default", metav1.ListOptions{})
+	for _, encoding := range []string{ItemEncodingCbor, ItemEncodingJson} {
+		for _, compressor := range []string{"", gzip.Name, ZstdName} {
+			request.Encoding = encoding
+			callOpts := []grpc.CallOption{}
+			if compressor != "" {
+				callOpts = append(callOpts, grpc.UseCompressor(compressor))
+			}
+			result, err := remote.streamGVRInstances(request, callOpts...)
+			if err != nil {
+				t.Fatalf("%s %s: failed to stream: %v", encoding, compressor, err)
+			}
+			if result.GetResourceVersion() != "12345" || result.GetContinue() != "next-page" || result.GetKind() != "PodList" {
+				t.Errorf("%s %s: wrong list %v", encoding, compressor, result.Object)
+			}
+			if len(result.Items) != len(list.Items) {
+				t.Fatalf("%s %s: expected %d items, got %d", encoding, compressor, len(list.Items), len(result.Items))
+			}
+			for i := range list.Items {
+				if !reflect.DeepEqual(list.Items[i].Object, result.Items[i].Object) {
+					t.Fatalf("%s %s: item %d differs: %v", encoding, compressor, i, result.Items[i].Object)
+				}
+			}
+		}
+	}
+
+	request.Encoding = "xml"
+	if _, err := remote.streamGVRInstances(request); err == nil {
+		t.Errorf("unknown encoding accepted")
+	}
+
+	// a stream failing to open keeps its status
+	request.Encoding = ItemEncodingCbor
+	if _, err := remote.streamGVRInstances(request, grpc.UseCompressor("nosuch")); status.Code(err) != codes.Internal {
+		t.Errorf("expected the status of the failed stream, got %v", err)
+	}
+
+	// the service streams by default
+	result, err := remote.FetchGVRInstances("", "v1", "pods", "default", metav1.ListOptions{})
+	if err != nil || len(result.Items) != len(list.Items) {
+		t.Errorf("failed to fetch: %v", err)
+	}
+}
+
+// BenchmarkFetchGVRInstances compares the json list, which needs a larger
+// message size than the grpc default, with the streamed items.
+func TestZstdBrokenMessage(t *testing.T) {
+	compressor := &zstdCompressor{}
+	reader, err := compressor.Decompress(strings.NewReader("not zstd"))
+	if err != nil {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Fatalf("expected a broken message")
+	}
+	if _, err := reader.Read(make([]byte, 1)); err == nil || err == io.EOF {
+		t.Errorf("the error is not kept, got %v", err)
+	}
+
+	// the decoder is reused for the next message
+	var buf bytes.Buffer
+	writer, _ := compressor.Compress(&buf)
+	writer.Write([]byte("hello"))
+	writer.Close()
+	reader, err = compressor.Decompress(&buf)
+	if err != nil {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+	if data, err := io.ReadAll(reader); err != nil || string(data) != "hello" {
+		t.Errorf("wrong message %q %v", data, err)
+	}
+}
+
+func BenchmarkFetchGVRInstances(b *testing.B) {
+	list := newTestPodList(10000)
+	remote := newTestListAgent(b, list)
+	request := newFetchGvrRequest("", "v1", "pods", "default", metav1.ListOptions{})
+
+	cases := []struct {
+		name     string
+		encoding string
+		opts     []grpc.CallOption
+	}{
+		{"json-list", "", []grpc.CallOption{grpc.MaxCallRecvMsgSize(256 * 1024 * 1024)}},
+		{"json-list-gzip", "", []grpc.CallOption{grpc.MaxCallRecvMsgSize(256 * 1024 * 1024), grpc.UseCompressor(gzip.Name)}},
+		{"json-stream", ItemEncodingJson, nil},
+		{"cbor-stream", ItemEncodingCbor, nil},
+		{"cbor-stream-gzip", ItemEncodingCbor, []grpc.CallOption{grpc.UseCompressor(gzip.Name)}},
+		{"cbor-stream-zstd", ItemEncodingCbor, []grpc.CallOption{grpc.UseCompressor(ZstdName)}},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			request.Encoding = c.encoding
+			for b.Loop() {
+				var result *unstructured.UnstructuredList
+				var err error
+				if c.encoding == "" {
+					result, err = remote.fetchGVRInstancesJson(request, c.opts...)
+				} else {
+					result, err = remote.streamGVRInstances(request, c.opts...)
+				}
+				if err != nil || len(result.Items) != len(list.Items) {
+					b.Fatalf("failed to fetch: %v", err)
+				}
+			}
+		})
+	}
+}
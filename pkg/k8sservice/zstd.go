@@ -0,0 +1,90 @@
+package k8sservice
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// ZstdName is the name the zstd compressor is registered with in grpc
+const ZstdName = "zstd"
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+}
+
+// zstdCompressor is a grpc compressor, the encoders and decoders are reused
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+type zstdReader struct {
+	decoder *zstd.Decoder
+	pool    *sync.Pool
+	// the error the decoder was returned to the pool with
+	err error
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.decoder == nil {
+		return 0, r.err
+	}
+	n, err := r.decoder.Read(p)
+	if err != nil {
+		// the message is read or broken, grpc reads until EOF or an error.
+		// Reset releases the message reader before it goes back to the pool
+		r.decoder.Reset(nil)
+		r.pool.Put(r.decoder)
+		r.decoder = nil
+		r.err = err
+	}
+	return n, err
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	encoder, ok := c.encoders.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+		encoder, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		encoder.Reset(w)
+	}
+	return &zstdWriter{Encoder: encoder, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	decoder, ok := c.decoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		// synchronous decoding, no goroutines are left behind
+		decoder, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else if err := decoder.Reset(r); err != nil {
+		c.decoders.Put(decoder)
+		return nil, err
+	}
+	return &zstdReader{decoder: decoder, pool: &c.decoders}, nil
+}
+
+func (c *zstdCompressor) Name() string {
+	return ZstdName
+}
@@ -1,13 +1,76 @@
 package options
 
+import "time"
+
+// TlsOptions configures TLS on the grpc connection between the gui and the agent.
+// In agent mode CertFile/KeyFile are the server certificate and CaFile is used to
+// verify client certificates. In gui mode CertFile/KeyFile are the (optional) client
+// certificate and CaFile is used to verify the agent.
+type TlsOptions struct {
+	Enabled    bool     `json:"enabled"`
+	CertFile   string   `json:"cert,omitempty"`
+	KeyFile    string   `json:"key,omitempty"`
+	CaFile     string   `json:"ca,omitempty"`
+	ClientAuth bool     `json:"client_auth,omitempty"`
+	ServerName string   `json:"server_name,omitempty"`
+	Hosts      []string `json:"hosts,omitempty"`
+}
+
+// AgentUser maps a caller of the agent, identified by a bearer token or by the
+// common name of its client certificate, to the k8s user (and groups) the agent
+// impersonates when serving its requests. User defaults to Name.
+type AgentUser struct {
+	Name   string   `json:"name"`
+	Token  string   `json:"token,omitempty"`
+	CertCN string   `json:"cert_cn,omitempty"`
+	User   string   `json:"impersonate_user,omitempty"`
+	Groups []string `json:"impersonate_groups,omitempty"`
+}
+
+// AgentAuth is the agent side authentication. When enabled, calls without a
+// known identity are rejected.
+type AgentAuth struct {
+	Enabled bool        `json:"enabled"`
+	Users   []AgentUser `json:"users,omitempty"`
+}
+
 type AppOptions struct {
 	Mode          string
 	Kubeconfig    string
 	UseCompressor bool
+	// the grpc compressor if UseCompressor, gzip or zstd
+	Compressor string
+	Tls        TlsOptions
+	Auth       AgentAuth
+	// kubeconfig context to use, empty for the current context
+	Context string
+	// agent only, the kubeconfig contexts served, empty for all
+	Clusters []string
+	// bearer token the gui sends to the agent
+	Token string
+	// agent only, serve lists and watches from informers, stopped after
+	// InformerIdleTimeout without use
+	InformerCache       bool
+	InformerIdleTimeout time.Duration
+	// gui only, how long a deploy waits for a crd it created to be established
+	// before deploying the rest
+	CrdTimeout time.Duration
+	// gui only, how long a deploy waits for the deployed resources to be
+	// ready when asked to
+	ReadyTimeout time.Duration
+	// gui only, how often the deployments are checked for drift from the
+	// cluster, 0 to only check on demand
+	DriftInterval time.Duration
 }
 
 var Options = AppOptions{
 	Mode:          "gui",
 	Kubeconfig:    "",
 	UseCompressor: true,
+	Compressor:    "gzip",
+
+	InformerIdleTimeout: 10 * time.Minute,
+	CrdTimeout:          2 * time.Minute,
+	ReadyTimeout:        5 * time.Minute,
+	DriftInterval:       5 * time.Minute,
 }
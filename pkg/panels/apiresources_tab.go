@@ -132,6 +132,19 @@ func (a *ApiResourcesTab) GetWidget() layout.Widget {
 	return a.widget
 }
 
+// ClusterChanged implements ClusterListener.
+func (a *ApiResourcesTab) ClusterChanged() {
+	a.current = nil
+	a.detailPage.SetText("")
+	a.schemaEditor.SetText(ptr.To(""), nil)
+	if newRes := a.client.FetchAllApiResources(true); newRes != nil {
+		a.populateTableContents(newRes, true)
+		a.inAppLogger.Info("Reloaded api-resources", zap.Int("total groups", len(a.allApis)))
+	} else {
+		a.allApis = make([]*ApiResourceGroup, 0)
+	}
+}
+
 func (a *ApiResourcesTab) processClick(item ApiResourceItem, gtx layout.Context) {
 	if item.GetClickable().Clicked(gtx) {
 		a.current = item
@@ -19,6 +19,12 @@ type PanelTab interface {
 	GetWidget() layout.Widget
 }
 
+// A ClusterListener is a tab that shows contents of the cluster,
+// it reloads them when the k8s service is switched to another cluster
+type ClusterListener interface {
+	ClusterChanged()
+}
+
 type AppPanel struct {
 	widget  layout.Widget
 	tabList layout.List
@@ -31,6 +37,15 @@ func (p *AppPanel) GetWidget() layout.Widget {
 	return p.widget
 }
 
+// ClusterChanged notifies the tabs that the k8s service is switched to another cluster
+func (p *AppPanel) ClusterChanged() {
+	for _, tab := range p.allTabs {
+		if listener, ok := tab.(ClusterListener); ok {
+			listener.ClusterChanged()
+		}
+	}
+}
+
 // each time it is called a new panel is created
 // so far it should be only called once.
 func GetAppPanel(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8sService, resMgr common.ResourceManager) *AppPanel {
@@ -46,12 +61,14 @@ func GetAppPanel(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8sServi
 	common.SetContextData(common.CONTEXT_APP_INIT_STATE, float32(0.8), nil)
 	utilTab := NewToolsTab(k8sClient)
 	common.SetContextData(common.CONTEXT_APP_INIT_STATE, float32(0.85), nil)
+	forwardTab := NewPortForwardTab(k8sClient)
 
 	panel.allTabs = append(panel.allTabs,
 		depTab,
 		logTab,
 		apiTab,
 		inKTab,
+		forwardTab,
 		utilTab)
 
 	panel.widget = func(gtx layout.Context) layout.Dimensions {
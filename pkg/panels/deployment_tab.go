@@ -1,11 +1,16 @@
 package panels
 
 import (
+	"fmt"
 	"image"
+	"sync/atomic"
+	"time"
 
 	"gaohoward.tools/k8s/resutil/pkg/common"
 	"gaohoward.tools/k8s/resutil/pkg/graphics"
 	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gaohoward.tools/k8s/resutil/pkg/logs"
+	"gaohoward.tools/k8s/resutil/pkg/options"
 	"gioui.org/font"
 	"gioui.org/layout"
 	"gioui.org/text"
@@ -13,6 +18,8 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type DeploymentTab struct {
@@ -20,6 +27,21 @@ type DeploymentTab struct {
 	undeployBtn        widget.Clickable
 	undeployBtnTooltip component.Tooltip
 	undeployBtnTipArea component.TipArea
+	revisionsBtn       widget.Clickable
+	revisionsTooltip   component.Tooltip
+	revisionsTipArea   component.TipArea
+	// the revisions of a deployment, shown instead of the deployments
+	revisions    *RevisionsView
+	driftBtn     widget.Clickable
+	driftTooltip component.Tooltip
+	driftTipArea component.TipArea
+	// the drift of a deployment, shown instead of the deployments
+	drift *DriftView
+	// a drift check is running
+	checkingDrift atomic.Bool
+	reloadBtn     widget.Clickable
+	reloadTooltip component.Tooltip
+	reloadTipArea component.TipArea
 
 	buttons  []layout.FlexChild
 	widget   layout.Widget
@@ -29,7 +51,7 @@ type DeploymentTab struct {
 	resMgr   common.ResourceManager
 }
 
-var headingText = []string{"", "Type", "Name", "Namespace", "State", "Creation"}
+var headingText = []string{"", "Type", "Name", "Namespace", "State", "Creation", "Health", "Drift"}
 
 func (d *DeploymentTab) Load() {
 	persister := d.deployed.GetPersister()
@@ -44,6 +66,20 @@ func (d *DeploymentTab) Load() {
 	}
 }
 
+// ClusterChanged implements ClusterListener.
+func (d *DeploymentTab) ClusterChanged() {
+	d.Reload()
+}
+
+// Reload reads the deployments from the persister again, e.g. to see
+// those others stored in the cluster
+func (d *DeploymentTab) Reload() {
+	d.revisions = nil
+	d.drift = nil
+	d.deployed.Reset()
+	d.Load()
+}
+
 // GetClickable implements PanelTab.
 func (d *DeploymentTab) GetClickable() *widget.Clickable {
 	return &d.tabButton
@@ -73,6 +109,9 @@ func NewDeploymentTab(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8s
 		client:             k8sClient,
 		resMgr:             resManager,
 		undeployBtnTooltip: component.DesktopTooltip(th, "Undeploy"),
+		revisionsTooltip:   component.DesktopTooltip(th, "Revisions"),
+		driftTooltip:       component.DesktopTooltip(th, "Check drift"),
+		reloadTooltip:      component.DesktopTooltip(th, "Reload deployments"),
 	}
 
 	clearBtn := component.TipIconButtonStyle{
@@ -95,7 +134,14 @@ func NewDeploymentTab(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8s
 					len := len(dr.GetSelectedDeployments())
 					task.Step = 0.9 / float32(len)
 					for _, selected := range dr.GetSelectedDeployments() {
-						for _, inst := range selected.AllInstances {
+						order, err := k8sservice.UndeployOrder(selected.AllInstances)
+						if err != nil {
+							anyFailure = err
+							task.Update("Failed to undeploy " + selected.Name + " err: " + err.Error())
+							continue
+						}
+						for _, id := range order {
+							inst := selected.AllInstances[id]
 							inst.SetAction(common.Delete)
 							targetNs := selected.OriginalCrs[inst.Instance.GetId()].FinalNs
 							if _, _, err := tab.client.DeployResource(inst, targetNs); err != nil {
@@ -127,11 +173,89 @@ func NewDeploymentTab(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8s
 		return dims
 	})
 
-	tab.buttons = append(tab.buttons, rigid1)
+	revisionsBtn := component.TipIconButtonStyle{
+		Tooltip:         tab.revisionsTooltip,
+		IconButtonStyle: material.IconButton(th, &tab.revisionsBtn, graphics.RestoreIcon, "Revisions"),
+		State:           &tab.revisionsTipArea,
+	}
+	revisionsBtn.Size = 16
+	revisionsBtn.IconButtonStyle.Inset = layout.Inset{Top: 1, Bottom: 1, Left: 1, Right: 1}
+
+	rigid2 := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		selected := dr.GetSelectedDeployments()
+		if tab.revisionsBtn.Clicked(gtx) && len(selected) == 1 {
+			tab.revisions = NewRevisionsView(selected[0])
+		}
+		if len(selected) != 1 || tab.revisions != nil {
+			gtx = gtx.Disabled()
+		}
+		return layout.Inset{Top: 4, Bottom: 0, Left: 0, Right: 4}.Layout(gtx, revisionsBtn.Layout)
+	})
+
+	driftBtn := component.TipIconButtonStyle{
+		Tooltip:         tab.driftTooltip,
+		IconButtonStyle: material.IconButton(th, &tab.driftBtn, graphics.RefreshIcon, "Check drift"),
+		State:           &tab.driftTipArea,
+	}
+	driftBtn.Size = 16
+	driftBtn.IconButtonStyle.Inset = layout.Inset{Top: 1, Bottom: 1, Left: 1, Right: 1}
+
+	rigid3 := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		if tab.driftBtn.Clicked(gtx) {
+			// the selected ones, or all if none selected
+			details := dr.GetSelectedDeployments()
+			if len(details) == 0 {
+				details = dr.All()
+			}
+			go tab.CheckDrift(details)
+		}
+		if dr.Size() == 0 || tab.checkingDrift.Load() {
+			gtx = gtx.Disabled()
+		}
+		return layout.Inset{Top: 4, Bottom: 0, Left: 0, Right: 4}.Layout(gtx, driftBtn.Layout)
+	})
+
+	reloadBtn := component.TipIconButtonStyle{
+		Tooltip:         tab.reloadTooltip,
+		IconButtonStyle: material.IconButton(th, &tab.reloadBtn, graphics.ReloadIcon, "Reload deployments"),
+		State:           &tab.reloadTipArea,
+	}
+	reloadBtn.Size = 16
+	reloadBtn.IconButtonStyle.Inset = layout.Inset{Top: 1, Bottom: 1, Left: 1, Right: 1}
+
+	rigid4 := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		if tab.reloadBtn.Clicked(gtx) {
+			tab.Reload()
+		}
+		// a deploy running updates its deployment
+		if dr.InDeploy() {
+			gtx = gtx.Disabled()
+		}
+		return layout.Inset{Top: 4, Bottom: 0, Left: 0, Right: 4}.Layout(gtx, reloadBtn.Layout)
+	})
+
+	tab.buttons = append(tab.buttons, rigid1, rigid2, rigid3, rigid4)
 
 	allRes := k8sClient.FetchAllApiResources(false)
 
 	tab.widget = func(gtx layout.Context) layout.Dimensions {
+		if view := tab.revisions; view != nil {
+			dims, redeploy, closed := view.Layout(gtx)
+			if redeploy > 0 {
+				go tab.Redeploy(view.detail, redeploy)
+			}
+			if closed {
+				tab.revisions = nil
+			}
+			return dims
+		}
+		if view := tab.drift; view != nil {
+			dims, closed := view.Layout(gtx)
+			if closed {
+				tab.drift = nil
+			}
+			return dims
+		}
 
 		inset := layout.UniformInset(unit.Dp(2))
 
@@ -149,15 +273,15 @@ func NewDeploymentTab(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8s
 		gtx.Constraints = orig
 
 		//5 columns: Checkbox, Name, Kind, Namespace, Status
-		return component.Table(th, &tab.grid).Layout(gtx, tab.deployed.Size(), 6,
+		return component.Table(th, &tab.grid).Layout(gtx, tab.deployed.Size(), 8,
 			func(axis layout.Axis, index, constraint int) int {
 				switch axis {
 				case layout.Horizontal:
 					switch index {
 					case 0:
 						return int(26)
-					case 1, 2, 3, 4, 5:
-						return int(constraint / 5)
+					case 1, 2, 3, 4, 5, 6, 7:
+						return int(constraint / 7)
 					default:
 						return 0
 					}
@@ -205,8 +329,26 @@ func NewDeploymentTab(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8s
 						value = dd.GetAllDeployNamespaces()
 					case 4:
 						value = dd.Status.String()
+						if dd.Rollback != nil {
+							value += " (rolled back)"
+						}
 					case 5:
 						value = dd.Creation
+					case 6:
+						value = dd.HealthSummary()
+					case 7:
+						report := tab.deployed.GetDrift(dd.Id)
+						if report == nil {
+							break
+						}
+						if dd.GetClickable().Clicked(gtx) {
+							tab.drift = NewDriftView(dd, tab.deployed, tab.client, tab.resMgr)
+						}
+						lb := material.Label(th, unit.Sp(15), report.Summary())
+						if len(report.Drifted()) > 0 {
+							lb.Color = common.COLOR.Red
+						}
+						return material.Clickable(gtx, dd.GetClickable(), lb.Layout)
 					}
 					lb := material.Label(th, unit.Sp(15), value)
 					return lb.Layout(gtx)
@@ -215,5 +357,93 @@ func NewDeploymentTab(dr *k8sservice.DeployedResources, k8sClient k8sservice.K8s
 		)
 	}
 	tab.Load()
+	if interval := options.Options.DriftInterval; interval > 0 {
+		go tab.watchDrift(interval)
+	}
 	return tab
 }
+
+// CheckDrift compares the details with the live cluster and records their
+// drift
+// Note: this method is called in a go routine
+func (d *DeploymentTab) CheckDrift(details []*k8sservice.DeployDetail) {
+	if !d.checkingDrift.CompareAndSwap(false, true) {
+		return
+	}
+	defer d.checkingDrift.Store(false)
+	for _, detail := range details {
+		report := k8sservice.CheckDrift(d.client, detail)
+		d.deployed.SetDrift(detail.Id, report)
+		if len(report.Drifted()) > 0 {
+			logger.Info("Deployment drifted", zap.String("deployment", detail.Name), zap.String("drift", report.Summary()))
+		}
+	}
+	common.GetAppWindow().Invalidate()
+}
+
+// watchDrift checks all the deployments for drift every interval
+func (d *DeploymentTab) watchDrift(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		d.CheckDrift(d.deployed.All())
+	}
+}
+
+// Redeploy deploys the revision number of detail again as a new revision.
+// What it deployed is rolled back if it fails.
+// Note: this method is called in a go routine
+func (d *DeploymentTab) Redeploy(detail *k8sservice.DeployDetail, number int) {
+	appLog := logs.GetLogger(logs.IN_APP_LOGGER_NAME)
+	plan, revision, err := d.deployed.PlanRedeploy(detail.Id, number)
+	if err != nil {
+		appLog.Error("Failed to redeploy", zap.String("deployment", detail.Name), zap.Int("revision", number), zap.Error(err))
+		return
+	}
+	if err := plan.PlanPrune(d.client); err != nil {
+		// there is no plan to show, the user is told in the app log
+		appLog.Warn("What earlier deploys left in the cluster is not pruned", zap.String("deployment", detail.Name), zap.Error(err))
+	}
+	ctxData, _ := common.GetContextData(common.CONTEXT_LONG_TASK_LIST)
+	taskCtx, ok := ctxData.(*common.LongTasksContext)
+	if !ok {
+		return
+	}
+	task := taskCtx.AddTask(fmt.Sprintf("Redeploying revision %d", number))
+	task.Run = func() {
+		task.Progress = float32(0.1)
+		task.Update("Starting")
+		items := plan.Approved()
+		task.Step = 0.9 / float32(len(items)+1)
+
+		prior := d.deployed.Snapshot(detail.Id)
+		tx := k8sservice.NewDeployTransaction(d.client)
+		finalNs := make(map[string]types.NamespacedName)
+		for _, item := range items {
+			ns, _, err := tx.Deploy(item, item.Namespace)
+			if err != nil {
+				logger.Error("Failed to redeploy resource", zap.String("res", item.Id), zap.Error(err))
+				task.SetStatus("Rolling back")
+				report := tx.Rollback(err)
+				d.deployed.RolledBack(detail.Id, prior, report)
+				appLog.Warn("Redeploy rolled back", zap.String("deployment", detail.Name), zap.String(logs.REPLY_CONTENT_KEY, report.String()))
+				revision.Outcome = k8sservice.RevisionRolledBack
+				revision.Message = err.Error()
+				d.deployed.AddRevision(detail.Id, revision)
+				task.Failed(err)
+				return
+			}
+			if item.Action.GetAction() != common.Delete {
+				finalNs[item.Id] = ns
+			}
+			task.Update("deployed " + item.Name)
+		}
+		d.deployed.Deployed(detail.Id, finalNs)
+		revision.Outcome = k8sservice.RevisionDeployed
+		revision.SetNamespaces(finalNs)
+		d.deployed.AddRevision(detail.Id, revision)
+		appLog.Info("Redeployed", zap.String("deployment", detail.Name), zap.Int("revision", number))
+		task.Done()
+	}
+	task.Start()
+}
@@ -0,0 +1,359 @@
+package panels
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gaohoward.tools/k8s/resutil/pkg/logs"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"go.uber.org/zap"
+)
+
+// the widths of the kind, name, namespace, state and fields columns
+var driftColumnWeights = []float32{0.2, 0.3, 0.2, 0.15, 0.15}
+
+type driftRow struct {
+	drift *k8sservice.ResourceDrift
+	check widget.Bool
+	btn   widget.Clickable
+}
+
+// DriftView shows how the resources of a deployment drifted from what was
+// deployed, for the user to re-apply them or adopt their live state
+type DriftView struct {
+	lock     sync.Mutex
+	detail   *k8sservice.DeployDetail
+	deployed *k8sservice.DeployedResources
+	client   k8sservice.K8sService
+	resMgr   common.ResourceManager
+	report   *k8sservice.DriftReport
+	rows     []*driftRow
+	selected int
+	// a check or re-apply is running
+	busy bool
+	err  string
+
+	split      component.Resize
+	rowList    widget.List
+	fieldList  widget.List
+	reapplyBtn widget.Clickable
+	adoptBtn   widget.Clickable
+	recheckBtn widget.Clickable
+	closeBtn   widget.Clickable
+}
+
+func NewDriftView(detail *k8sservice.DeployDetail, deployed *k8sservice.DeployedResources, client k8sservice.K8sService, resMgr common.ResourceManager) *DriftView {
+	view := &DriftView{
+		detail:   detail,
+		deployed: deployed,
+		client:   client,
+		resMgr:   resMgr,
+		split:    component.Resize{Ratio: 0.5},
+	}
+	view.rowList.Axis = layout.Vertical
+	view.fieldList.Axis = layout.Vertical
+	view.setReport(deployed.GetDrift(detail.Id))
+	return view
+}
+
+func (v *DriftView) setReport(report *k8sservice.DriftReport) {
+	v.report = report
+	v.rows = nil
+	v.selected = 0
+	if report == nil {
+		return
+	}
+	for _, drift := range report.Resources {
+		row := &driftRow{drift: drift}
+		row.check.Value = drift.State != k8sservice.DriftInSync
+		v.rows = append(v.rows, row)
+	}
+}
+
+// checked returns the checked drifted resources
+func (v *DriftView) checked() []*k8sservice.ResourceDrift {
+	drifts := make([]*k8sservice.ResourceDrift, 0)
+	for _, row := range v.rows {
+		if row.check.Value && row.drift.State != k8sservice.DriftInSync {
+			drifts = append(drifts, row.drift)
+		}
+	}
+	return drifts
+}
+
+// run does work in a go routine then checks the drift again
+func (v *DriftView) run(work func() error) {
+	v.busy = true
+	v.err = ""
+	go func() {
+		err := work()
+		report := k8sservice.CheckDrift(v.client, v.detail)
+		v.deployed.SetDrift(v.detail.Id, report)
+		v.lock.Lock()
+		v.setReport(report)
+		if err != nil {
+			v.err = err.Error()
+		}
+		v.busy = false
+		v.lock.Unlock()
+		common.GetAppWindow().Invalidate()
+	}()
+}
+
+// adopt takes the live state of the checked resources as their crs, both
+// in the deployment and the repository
+func (v *DriftView) adopt() error {
+	appLog := logs.GetLogger(logs.IN_APP_LOGGER_NAME)
+	failures := []string{}
+	for _, drift := range v.checked() {
+		if !drift.CanAdopt() {
+			continue
+		}
+		cr, err := drift.Adopted()
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if err := v.deployed.Adopt(v.detail.Id, drift.Id, cr); err != nil {
+			failures = append(failures, drift.Name+": "+err.Error())
+			continue
+		}
+		if node, ok := v.resMgr.GetNodeMap()[drift.Id].(*common.ResourceNode); ok {
+			node.Instance.SetCR(cr)
+			v.resMgr.SaveResource(drift.Id)
+			v.resMgr.ResourceUpdated(node)
+		} else {
+			failures = append(failures, drift.Name+" is not in the repository")
+		}
+		appLog.Info("Adopted the live state", zap.String("resource", drift.Name))
+	}
+	if len(failures) > 0 {
+		return &adoptError{failures}
+	}
+	return nil
+}
+
+type adoptError struct {
+	failures []string
+}
+
+func (e *adoptError) Error() string {
+	return "failed to adopt: " + strings.Join(e.failures, "; ")
+}
+
+// Layout returns whether the user closed the view
+func (v *DriftView) Layout(gtx layout.Context) (layout.Dimensions, bool) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	th := common.GetTheme()
+	checked := v.checked()
+	if !v.busy {
+		switch {
+		case v.reapplyBtn.Clicked(gtx) && len(checked) > 0:
+			v.run(func() error {
+				failures := []string{}
+				for _, drift := range checked {
+					if err := drift.Reapply(v.client); err != nil {
+						logger.Error("Failed to re-apply", zap.String("res", drift.Id), zap.Error(err))
+						failures = append(failures, drift.Name+": "+err.Error())
+					}
+				}
+				if len(failures) > 0 {
+					return &reapplyError{failures}
+				}
+				return nil
+			})
+		case v.adoptBtn.Clicked(gtx) && len(checked) > 0:
+			// the repository is changed in the ui loop
+			err := v.adopt()
+			v.run(func() error { return err })
+		case v.recheckBtn.Clicked(gtx):
+			v.run(func() error { return nil })
+		}
+	}
+	closed := v.closeBtn.Clicked(gtx)
+	for i, row := range v.rows {
+		if row.btn.Clicked(gtx) {
+			v.selected = i
+		}
+	}
+
+	button := func(btn *widget.Clickable, text string, enabled bool) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !enabled || v.busy {
+				gtx = gtx.Disabled()
+			}
+			return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, material.Button(th, btn, text).Layout)
+		})
+	}
+	header := func(gtx layout.Context) layout.Dimensions {
+		title := "Drift of " + v.detail.Name
+		if v.report != nil {
+			title += " checked " + v.report.Checked
+		}
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1.0, material.H6(th, title).Layout),
+				button(&v.reapplyBtn, "Re-apply", len(checked) > 0),
+				button(&v.adoptBtn, "Adopt", len(checked) > 0),
+				button(&v.recheckBtn, "Check again", true),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, material.Button(th, &v.closeBtn, "Close").Layout)
+				}),
+			)
+		})
+	}
+
+	body := func(gtx layout.Context) layout.Dimensions {
+		switch {
+		case v.busy:
+			return material.Body1(th, "Checking...").Layout(gtx)
+		case v.report == nil:
+			return material.Body1(th, "Not checked yet").Layout(gtx)
+		case len(v.rows) == 0:
+			return material.Body1(th, "No resources deployed").Layout(gtx)
+		}
+		return v.split.Layout(gtx, v.layoutRows, v.layoutFields, common.VerticalSplitHandler)
+	}
+
+	dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(header),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if v.err == "" {
+					return layout.Dimensions{}
+				}
+				label := material.Body2(th, v.err)
+				label.Color = common.COLOR.Red
+				return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, label.Layout)
+			}),
+			layout.Flexed(1.0, body),
+		)
+	})
+	return dims, closed
+}
+
+type reapplyError struct {
+	failures []string
+}
+
+func (e *reapplyError) Error() string {
+	return "failed to re-apply: " + strings.Join(e.failures, "; ")
+}
+
+func (v *DriftView) layoutRows(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+	columns := func(gtx layout.Context, texts []string, style func(*material.LabelStyle)) layout.Dimensions {
+		children := make([]layout.FlexChild, 0, len(texts))
+		for i, text := range texts {
+			children = append(children, layout.Flexed(driftColumnWeights[i], func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(th, text)
+				label.MaxLines = 1
+				style(&label)
+				return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, label.Layout)
+			}))
+		}
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+	}
+	checkWidth := unit.Dp(32)
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(layout.Spacer{Width: checkWidth}.Layout),
+				layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+					return columns(gtx, []string{"Kind", "Name", "Namespace", "State", "Fields"}, func(l *material.LabelStyle) {
+						l.Font.Weight = font.Bold
+					})
+				}),
+			)
+		}),
+		layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+			return material.List(th, &v.rowList).Layout(gtx, len(v.rows), func(gtx layout.Context, index int) layout.Dimensions {
+				row := v.rows[index]
+				drift := row.drift
+				texts := []string{drift.Kind, drift.Name, drift.Namespace, string(drift.State), strconv.Itoa(len(drift.Fields))}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Min.X = gtx.Dp(checkWidth)
+						if drift.State == k8sservice.DriftInSync {
+							gtx = gtx.Disabled()
+						}
+						return material.CheckBox(th, &row.check, "").Layout(gtx)
+					}),
+					layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+						return material.Clickable(gtx, &row.btn, func(gtx layout.Context) layout.Dimensions {
+							return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return columns(gtx, texts, func(l *material.LabelStyle) {
+									if drift.State != k8sservice.DriftInSync {
+										l.Color = common.COLOR.Red
+									}
+									if index == v.selected {
+										l.Font.Weight = font.Bold
+									}
+								})
+							})
+						})
+					}),
+				)
+			})
+		}),
+	)
+}
+
+func (v *DriftView) layoutFields(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+	if v.selected >= len(v.rows) {
+		return layout.Dimensions{}
+	}
+	drift := v.rows[v.selected].drift
+	return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		var message string
+		switch drift.State {
+		case k8sservice.DriftInSync:
+			message = "in sync with what was deployed"
+		case k8sservice.DriftMissing:
+			message = "not found in the cluster, re-apply to create it"
+		case k8sservice.DriftUnknown:
+			message = drift.Err
+		}
+		if message != "" {
+			return material.Body2(th, message).Layout(gtx)
+		}
+		return material.List(th, &v.fieldList).Layout(gtx, len(drift.Fields), func(gtx layout.Context, index int) layout.Dimensions {
+			field := drift.Fields[index]
+			live := field.Live
+			if live == "" {
+				live = "<removed>"
+			}
+			return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.Body2(th, field.Path)
+						label.Font.Weight = font.Bold
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.Body2(th, "- deployed: "+field.Desired)
+						label.Color = common.COLOR.Red
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						label := material.Body2(th, "+ live: "+live)
+						label.Color = common.COLOR.Green
+						return label.Layout(gtx)
+					}),
+				)
+			})
+		})
+	})
+}
@@ -0,0 +1,347 @@
+package panels
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const POD_EXEC_CHANGE_FLAG = "pod.exec.detail.output.change.flag"
+
+// the output kept in the terminal, older output is dropped
+const execBufferLimit = 1024 * 1024
+
+// escape sequences (colors, cursor moves, titles) the terminal can't render
+var terminalControls = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[=>]|\r`)
+
+func stripTerminalControls(s string) string {
+	return terminalControls.ReplaceAllString(s, "")
+}
+
+// execSession is a running exec of a command in a container
+type execSession struct {
+	cancel context.CancelFunc
+	stdin  *io.PipeWriter
+	resize chan remotecommand.TerminalSize
+}
+
+// PodExecDetail is a terminal running a command in a container of the pod
+type PodExecDetail struct {
+	*ResourceDetail
+	containers     []string
+	containerBtns  []widget.Clickable
+	container      string
+	containerList  layout.List
+	commandField   widget.Editor
+	inputField     widget.Editor
+	tty            widget.Bool
+	connectBtn     widget.Clickable
+	ctrlCBtn       widget.Clickable
+	output         *common.ReadOnlyEditor
+	changeFlag     string
+	lastSize       remotecommand.TerminalSize
+	outputLock     sync.Mutex
+	outputBuffer   bytes.Buffer
+	outputText     string
+	session        *execSession
+	sessionRunning bool
+}
+
+// Write implements io.Writer, it receives the stdout and stderr of the command
+func (p *PodExecDetail) Write(data []byte) (int, error) {
+	p.appendOutput(stripTerminalControls(string(data)))
+	return len(data), nil
+}
+
+func (p *PodExecDetail) appendOutput(text string) {
+	p.outputLock.Lock()
+	defer p.outputLock.Unlock()
+	p.outputBuffer.WriteString(text)
+	if over := p.outputBuffer.Len() - execBufferLimit; over > 0 {
+		p.outputBuffer.Next(over)
+	}
+	common.SetContextBool(p.changeFlag, true, nil)
+	if win := common.GetAppWindow(); win != nil {
+		win.Invalidate()
+	}
+}
+
+// Changed implements common.IResourceDetail.
+func (p *PodExecDetail) Changed() bool {
+	changed, _, _ := common.GetContextBool(p.changeFlag)
+	if changed {
+		common.FlipContextBool(p.changeFlag)
+	}
+	return changed
+}
+
+// Save implements common.IResourceDetail.
+func (p *PodExecDetail) Save(baseDir string, kind string, name string, ns string) {
+	filePath := common.CreateFilePathForK8sObject(baseDir, kind, name, ns, p.container+"-exec", "log")
+	p.outputLock.Lock()
+	content := p.outputBuffer.String()
+	p.outputLock.Unlock()
+	if err := common.SaveFile(filePath, &content); err != nil {
+		logger.Error("Failed to save exec output", zap.String("file", filePath), zap.Error(err))
+	}
+}
+
+func (p *PodExecDetail) isRunning() bool {
+	p.outputLock.Lock()
+	defer p.outputLock.Unlock()
+	return p.sessionRunning
+}
+
+// connect starts the command in the current container
+func (p *PodExecDetail) connect() {
+	command := strings.Fields(p.commandField.Text())
+	if len(command) == 0 || p.container == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stdinReader, stdinWriter := io.Pipe()
+	session := &execSession{
+		cancel: cancel,
+		stdin:  stdinWriter,
+		resize: make(chan remotecommand.TerminalSize, 1),
+	}
+	if p.lastSize.Width > 0 {
+		session.resize <- p.lastSize
+	}
+
+	p.outputLock.Lock()
+	p.session = session
+	p.sessionRunning = true
+	p.outputLock.Unlock()
+
+	opts := k8sservice.ExecOptions{
+		Container: p.container,
+		Command:   command,
+		Tty:       p.tty.Value,
+		Stdin:     stdinReader,
+		Stdout:    p,
+		Stderr:    p,
+		Resize:    session.resize,
+	}
+	p.appendOutput(fmt.Sprintf("--- %s: %s ---\n", p.container, strings.Join(command, " ")))
+
+	go func() {
+		err := k8sservice.GetK8sService().ExecPod(ctx, p.item, opts)
+		cancel()
+		stdinReader.Close()
+		if err != nil {
+			p.appendOutput(fmt.Sprintf("\n--- session ended: %v ---\n", err))
+		} else {
+			p.appendOutput("\n--- session ended ---\n")
+		}
+		p.outputLock.Lock()
+		if p.session == session {
+			p.sessionRunning = false
+		}
+		p.outputLock.Unlock()
+	}()
+}
+
+func (p *PodExecDetail) disconnect() {
+	p.outputLock.Lock()
+	session := p.session
+	p.outputLock.Unlock()
+	if session != nil {
+		session.stdin.Close()
+		session.cancel()
+	}
+}
+
+// sendInput writes to the stdin of the running command
+func (p *PodExecDetail) sendInput(input string) {
+	p.outputLock.Lock()
+	session := p.session
+	running := p.sessionRunning
+	p.outputLock.Unlock()
+	if !running {
+		return
+	}
+	go func() {
+		if _, err := io.WriteString(session.stdin, input); err != nil {
+			logger.Info("failed to write to exec stdin", zap.Error(err))
+		}
+	}()
+}
+
+// resizeTerminal tells the command the size of the output area in characters
+func (p *PodExecDetail) resizeTerminal(gtx layout.Context) {
+	th := common.GetTheme()
+	measure := material.Body1(th, "M")
+	measure.Font.Typeface = "monospace"
+	measure.TextSize = unit.Sp(15)
+	macro := op.Record(gtx.Ops)
+	charSize := measure.Layout(gtx.Disabled())
+	macro.Stop()
+	if charSize.Size.X == 0 || charSize.Size.Y == 0 {
+		return
+	}
+	size := remotecommand.TerminalSize{
+		Width:  uint16(gtx.Constraints.Max.X / charSize.Size.X),
+		Height: uint16(gtx.Constraints.Max.Y / charSize.Size.Y),
+	}
+	if size == p.lastSize {
+		return
+	}
+	p.lastSize = size
+
+	p.outputLock.Lock()
+	defer p.outputLock.Unlock()
+	if p.session != nil && p.sessionRunning {
+		// only the latest size matters
+		select {
+		case <-p.session.resize:
+		default:
+		}
+		p.session.resize <- size
+	}
+}
+
+// GetContent implements common.IResourceDetail.
+func (p *PodExecDetail) GetContent() layout.Widget {
+	th := common.GetTheme()
+	return func(gtx layout.Context) layout.Dimensions {
+		running := p.isRunning()
+
+		if p.connectBtn.Clicked(gtx) {
+			if running {
+				p.disconnect()
+			} else {
+				p.connect()
+			}
+		}
+		if p.ctrlCBtn.Clicked(gtx) {
+			p.sendInput("\x03")
+		}
+		for {
+			event, ok := p.inputField.Update(gtx)
+			if !ok {
+				break
+			}
+			if _, ok := event.(widget.SubmitEvent); ok {
+				p.sendInput(p.inputField.Text() + "\n")
+				p.inputField.SetText("")
+			}
+		}
+
+		if p.Changed() {
+			p.outputLock.Lock()
+			p.outputText = p.outputBuffer.String()
+			p.outputLock.Unlock()
+			p.output.SetText(&p.outputText, nil)
+		}
+
+		containerBar := func(gtx layout.Context) layout.Dimensions {
+			return p.containerList.Layout(gtx, len(p.containers), func(gtx layout.Context, index int) layout.Dimensions {
+				name := p.containers[index]
+				if p.containerBtns[index].Clicked(gtx) && !running {
+					p.container = name
+				}
+				label := material.Body1(th, name)
+				if name == p.container {
+					label.Font.Weight = font.Bold
+					label.Color = common.COLOR.Blue
+				}
+				return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return material.Clickable(gtx, &p.containerBtns[index], label.Layout)
+				})
+			})
+		}
+
+		commandBar := func(gtx layout.Context) layout.Dimensions {
+			connectText := "Connect"
+			if running {
+				connectText = "Disconnect"
+			}
+			ttyCheck := material.CheckBox(th, &p.tty, "tty")
+			ttyCheck.Size = unit.Dp(16)
+			command := material.Editor(th, &p.commandField, "command")
+			command.TextSize = unit.Sp(14)
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, command.Layout)
+				}),
+				layout.Rigid(ttyCheck.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, material.Button(th, &p.connectBtn, connectText).Layout)
+				}),
+			)
+		}
+
+		inputBar := func(gtx layout.Context) layout.Dimensions {
+			input := material.Editor(th, &p.inputField, "input, enter to send")
+			input.Font.Typeface = "monospace"
+			input.TextSize = unit.Sp(14)
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Body2(th, "$ ").Layout(gtx)
+				}),
+				layout.Flexed(1.0, input.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, material.Button(th, &p.ctrlCBtn, "Ctrl-C").Layout)
+				}),
+			)
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(containerBar),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, commandBar)
+			}),
+			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				p.resizeTerminal(gtx)
+				return p.output.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, inputBar)
+			}),
+		)
+	}
+}
+
+func NewPodExecDetail(item *unstructured.Unstructured) (*PodExecDetail, error) {
+	containers, err := common.GetPodContainers(item)
+	if err != nil {
+		return nil, err
+	}
+	pd := &PodExecDetail{
+		ResourceDetail: NewDetail("exec", item),
+		containers:     containers,
+		containerBtns:  make([]widget.Clickable, len(containers)),
+		changeFlag:     POD_EXEC_CHANGE_FLAG + "." + string(item.GetUID()) + "." + item.GetName(),
+	}
+	if len(containers) > 0 {
+		pd.container = containers[0]
+	}
+	pd.tty.Value = true
+	pd.commandField.SingleLine = true
+	pd.commandField.SetText("/bin/sh")
+	pd.inputField.SingleLine = true
+	pd.inputField.Submit = true
+
+	pd.output = common.NewReadOnlyEditor("exec", 15, nil, nil, false)
+	pd.output.FollowTail(true)
+	common.RegisterContext(pd.changeFlag, false, true)
+
+	return pd, nil
+}
@@ -1,9 +1,11 @@
 package panels
 
 import (
+	"fmt"
 	"image"
 	"slices"
 	"strings"
+	"sync"
 
 	"gaohoward.tools/k8s/resutil/pkg/common"
 	"gaohoward.tools/k8s/resutil/pkg/config"
@@ -24,6 +26,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 const (
@@ -32,6 +35,10 @@ const (
 	CONTEXT_KEY_API_SEARCH_RESULT = "app.panel.in_kube.api.search.result"
 )
 
+// the in-kube results are listed in pages of this size,
+// more pages are loaded when scrolled to the end
+const IN_KUBE_PAGE_SIZE int64 = 200
+
 type InKubeTab struct {
 	title        string
 	tabClickable widget.Clickable
@@ -54,6 +61,8 @@ type InKubeTab struct {
 	resize2 component.Resize
 
 	searchField widget.Editor
+	labelField  widget.Editor
+	fieldField  widget.Editor
 
 	nsList  *common.ReadOnlyEditor
 	resList *common.ReadOnlyEditor
@@ -77,6 +86,13 @@ type InKubeTab struct {
 	widget       layout.Widget
 	InRefreshing bool
 	inQuery      bool
+
+	watchLock   sync.Mutex
+	watcher     *ResultWatcher
+	pages       []*watchTarget
+	loadingPage bool
+	// the least fresh of the results the agent served from its cache
+	freshness *k8sservice.CacheFreshness
 }
 
 type DetailPanel struct {
@@ -85,6 +101,8 @@ type DetailPanel struct {
 
 func (t *InKubeTab) Query() ([]*unstructured.UnstructuredList, error) {
 	results := make([]*unstructured.UnstructuredList, 0)
+	targets := make([]watchTarget, 0)
+	var freshness *k8sservice.CacheFreshness
 	targetNs := t.currentCriteria.GetTargetNamespaces()
 	if len(targetNs) == 0 {
 		targetNs = []string{""}
@@ -96,25 +114,242 @@ func (t *InKubeTab) Query() ([]*unstructured.UnstructuredList, error) {
 			if !pair.Value.res.Namespaced {
 				targetNs = ""
 			}
-			result, err := t.client.FetchGVRInstances(g, v, r, targetNs)
+			result, err := t.client.FetchGVRInstances(g, v, r, targetNs, v1.ListOptions{
+				LabelSelector: t.currentCriteria.LabelSelector,
+				FieldSelector: t.currentCriteria.FieldSelector,
+				Limit:         IN_KUBE_PAGE_SIZE,
+			})
 			if err != nil {
 				logger.Warn("failed to query the cluster", zap.Error(err))
 				t.inLogger.Info("failed to query resource", zap.String("name", r), zap.String("err", err.Error()))
 				continue
 			}
 			results = append(results, result)
+			freshness = leastFresh(freshness, k8sservice.ListFreshness(result))
+			targets = append(targets, watchTarget{
+				g: g, v: v, r: r, ns: targetNs,
+				kind:            pair.Value.res.Kind,
+				labelSelector:   t.currentCriteria.LabelSelector,
+				fieldSelector:   t.currentCriteria.FieldSelector,
+				resourceVersion: result.GetResourceVersion(),
+				next:            result.GetContinue(),
+			})
 		}
 	}
 	t.searchResults.Set(t.currentCriteria.Compile(), results)
+	t.watchResults(targets)
+	t.watchLock.Lock()
+	t.freshness = freshness
+	t.watchLock.Unlock()
 	return results, nil
 
 }
 
+func leastFresh(a *k8sservice.CacheFreshness, b *k8sservice.CacheFreshness) *k8sservice.CacheFreshness {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.Stale != b.Stale:
+		if a.Stale {
+			return a
+		}
+		return b
+	case a.Stale:
+		if a.StaleSince.Before(b.StaleSince) {
+			return a
+		}
+		return b
+	case a.LastUpdate.Before(b.LastUpdate):
+		return a
+	}
+	return b
+}
+
+// cacheFreshness tells how current the results are if they come from the agent's cache
+func (t *InKubeTab) cacheFreshness() *k8sservice.CacheFreshness {
+	t.watchLock.Lock()
+	defer t.watchLock.Unlock()
+	return t.freshness
+}
+
+// watchResults replaces the watches of the previous query
+func (t *InKubeTab) watchResults(targets []watchTarget) {
+	t.watchLock.Lock()
+	defer t.watchLock.Unlock()
+	if t.watcher != nil {
+		t.watcher.Stop()
+	}
+	t.watcher = NewResultWatcher(t.client, targets)
+	t.pages = make([]*watchTarget, 0, len(targets))
+	for i := range targets {
+		t.pages = append(t.pages, &targets[i])
+	}
+}
+
+func (t *InKubeTab) hasMorePages() bool {
+	t.watchLock.Lock()
+	defer t.watchLock.Unlock()
+	return slices.ContainsFunc(t.pages, func(p *watchTarget) bool {
+		return p.next != ""
+	})
+}
+
+// loadNextPage fetches the next page of the first resource that has more.
+// The items reach the result list through the watcher, so they are merged
+// with the changes seen meanwhile.
+func (t *InKubeTab) loadNextPage() {
+	t.watchLock.Lock()
+	defer t.watchLock.Unlock()
+	if t.loadingPage {
+		return
+	}
+	index := slices.IndexFunc(t.pages, func(p *watchTarget) bool {
+		return p.next != ""
+	})
+	if index < 0 {
+		return
+	}
+	target := t.pages[index]
+	watcher := t.watcher
+	opts := v1.ListOptions{
+		LabelSelector: target.labelSelector,
+		FieldSelector: target.fieldSelector,
+		Limit:         IN_KUBE_PAGE_SIZE,
+		Continue:      target.next,
+	}
+	t.loadingPage = true
+
+	go func() {
+		result, err := t.client.FetchGVRInstances(target.g, target.v, target.r, target.ns, opts)
+
+		t.watchLock.Lock()
+		defer t.watchLock.Unlock()
+		t.loadingPage = false
+		if t.watcher != watcher {
+			// replaced by a new query
+			return
+		}
+		if err != nil {
+			// e.g. the continue token expired, the watch still brings in changes
+			t.inLogger.Info("failed to load more results", zap.String("name", target.r), zap.String("err", err.Error()))
+			target.next = ""
+			return
+		}
+		target.next = result.GetContinue()
+		watcher.AddPage(result.Items)
+	}()
+}
+
+func (t *InKubeTab) startQuery() {
+	if t.inQuery {
+		return
+	}
+	t.inQuery = true
+	t.currentCriteria.LabelSelector = strings.TrimSpace(t.labelField.Text())
+	t.currentCriteria.FieldSelector = strings.TrimSpace(t.fieldField.Text())
+	go func() {
+		result, _ := t.Query()
+		resultList := getSearchResultList(result)
+		common.SetContextData(CONTEXT_KEY_API_SEARCH_RESULT, resultList, nil)
+		t.inQuery = false
+	}()
+}
+
+func sameObject(a *unstructured.Unstructured, b *unstructured.Unstructured) bool {
+	if a.GetUID() != "" || b.GetUID() != "" {
+		return a.GetUID() == b.GetUID()
+	}
+	return a.GetKind() == b.GetKind() && a.GetNamespace() == b.GetNamespace() && a.GetName() == b.GetName()
+}
+
+// applyWatchEvents updates the result list with the changes seen by the watcher.
+// It runs on the ui goroutine so the items can be updated in place.
+func (t *InKubeTab) applyWatchEvents(uList []*SearchResultItem) []*SearchResultItem {
+	t.watchLock.Lock()
+	watcher := t.watcher
+	t.watchLock.Unlock()
+	if watcher == nil {
+		return uList
+	}
+	events := watcher.Drain()
+	if len(events) == 0 {
+		return uList
+	}
+	for _, event := range events {
+		if relist, ok := event.Object.(*relistResult); ok {
+			uList = t.applyRelist(uList, relist)
+			continue
+		}
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		index := slices.IndexFunc(uList, func(item *SearchResultItem) bool {
+			return sameObject(item.item, obj)
+		})
+		switch event.Type {
+		case pageLoaded:
+			if index < 0 {
+				uList = append(uList, newSearchResultItem(obj))
+			}
+		case watch.Added, watch.Modified:
+			if index < 0 {
+				uList = append(uList, newSearchResultItem(obj))
+			} else {
+				uList[index].Update(obj, uList[index] == t.currentResultItem)
+			}
+		case watch.Deleted:
+			if index >= 0 {
+				if uList[index] == t.currentResultItem {
+					t.currentResultItem = nil
+				}
+				uList = slices.Delete(uList, index, index+1)
+			}
+		}
+	}
+	common.SetContextData(CONTEXT_KEY_API_SEARCH_RESULT, uList, nil)
+	return uList
+}
+
+// applyRelist reconciles the result list with the objects of a target
+// listed again: those no longer there are removed, the others are added or
+// updated.
+func (t *InKubeTab) applyRelist(uList []*SearchResultItem, relist *relistResult) []*SearchResultItem {
+	uList = slices.DeleteFunc(uList, func(item *SearchResultItem) bool {
+		if !relist.contains(item.item) {
+			return false
+		}
+		gone := !slices.ContainsFunc(relist.Items, func(obj unstructured.Unstructured) bool {
+			return sameObject(item.item, &obj)
+		})
+		if gone && item == t.currentResultItem {
+			t.currentResultItem = nil
+		}
+		return gone
+	})
+	for i := range relist.Items {
+		obj := &relist.Items[i]
+		index := slices.IndexFunc(uList, func(item *SearchResultItem) bool {
+			return sameObject(item.item, obj)
+		})
+		if index < 0 {
+			uList = append(uList, newSearchResultItem(obj))
+		} else {
+			uList[index].Update(obj, uList[index] == t.currentResultItem)
+		}
+	}
+	return uList
+}
+
 type SearchCriteria struct {
-	Ns         *om.OrderedMap[string, string]
-	Res        *om.OrderedMap[string, *ResourceItem]
-	Valid      bool
-	InvalidMsg string
+	Ns            *om.OrderedMap[string, string]
+	Res           *om.OrderedMap[string, *ResourceItem]
+	LabelSelector string
+	FieldSelector string
+	Valid         bool
+	InvalidMsg    string
 }
 
 func (s *SearchCriteria) Update(nsLines []*common.Liner, nsItems []*common.Liner, t *InKubeTab) bool {
@@ -277,6 +512,29 @@ func (r *ResourceItem) GetGvr() string {
 	return r.verInfo.Gv + "/" + r.verInfo.Name
 }
 
+// ClusterChanged implements ClusterListener.
+// The results of the previous cluster are dropped and their watches stopped.
+func (t *InKubeTab) ClusterChanged() {
+	t.InRefreshing = true
+	t.watchLock.Lock()
+	if t.watcher != nil {
+		t.watcher.Stop()
+		t.watcher = nil
+	}
+	t.pages = nil
+	t.watchLock.Unlock()
+
+	t.searchField.SetText("")
+	t.currentCriteria.Reset()
+	t.searchResults = om.New[string, []*unstructured.UnstructuredList]()
+	t.currentResultItem = nil
+	common.SetContextData(CONTEXT_KEY_API_SEARCH_RESULT, nil, nil)
+
+	t.RefreshNamespaces()
+	t.RefreshApiResources(true)
+	t.InRefreshing = false
+}
+
 func (t *InKubeTab) RefreshNamespaces() {
 	allNamespaces, err := t.client.FetchAllNamespaces()
 	if err != nil {
@@ -330,6 +588,24 @@ type SearchResultItem struct {
 	statusInfo    common.ResStatusInfo
 }
 
+func newSearchResultItem(item *unstructured.Unstructured) *SearchResultItem {
+	return &SearchResultItem{
+		item:   item,
+		label0: material.Label(common.GetTheme(), unit.Sp(15), ""),
+	}
+}
+
+// Update replaces the item with a newer version of it. The details of a
+// selected item are kept so that views like logs are not interrupted.
+func (s *SearchResultItem) Update(item *unstructured.Unstructured, selected bool) {
+	s.item = item
+	s.statusInfo = nil
+	if !selected {
+		s.details = nil
+		s.currentDetail = nil
+	}
+}
+
 func (s *SearchResultItem) SupportStatus() bool {
 	return s.item.GetKind() == "Pod"
 }
@@ -449,10 +725,7 @@ func getSearchResultList(result []*unstructured.UnstructuredList) []*SearchResul
 	resultList := make([]*SearchResultItem, 0)
 	itemList := common.GetAllUnstructuredItems(result)
 	for _, item := range itemList {
-		resultList = append(resultList, &SearchResultItem{
-			item:   item,
-			label0: material.Label(common.GetTheme(), unit.Sp(15), ""),
-		})
+		resultList = append(resultList, newSearchResultItem(item))
 	}
 	return resultList
 }
@@ -461,6 +734,87 @@ func getSearchResultList(result []*unstructured.UnstructuredList) []*SearchResul
 // namespace should be empty if resource is not namespaced
 var headings = []string{"Name", "Kind", "Namespace"}
 
+func (tab *InKubeTab) layoutResultTable(gtx layout.Context, uList []*SearchResultItem, inset layout.Inset, headingLabel material.LabelStyle, dims layout.Dimensions) layout.Dimensions {
+	th := common.GetTheme()
+	return component.Table(th, &tab.grid).Layout(gtx, len(uList), len(headings),
+		func(axis layout.Axis, index, constraint int) int {
+			unit := int(constraint / 10)
+			switch axis {
+			case layout.Horizontal:
+				switch index {
+				case 0:
+					//give name a bit extra space
+					return int(unit * 5)
+				case 1:
+					return int(unit * 2)
+				case 2:
+					return int(unit * 3)
+				default:
+					return 0
+				}
+			default:
+				return dims.Size.Y + 2
+			}
+		},
+		func(gtx layout.Context, index int) layout.Dimensions {
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				headingLabel.Text = headings[index]
+				return headingLabel.Layout(gtx)
+			})
+		},
+		func(gtx layout.Context, row, col int) layout.Dimensions {
+			if row == len(uList)-1 && col == 0 {
+				// scrolled to the end
+				tab.loadNextPage()
+			}
+			rowItem := uList[row]
+			value := ""
+			switch col {
+			case 0:
+				value = rowItem.item.GetName()
+			case 1:
+				value = rowItem.item.GetKind()
+			case 2:
+				value = rowItem.item.GetNamespace()
+			}
+
+			if col == 0 {
+				rowItem.label0.Text = value
+				if rowItem.clickable.Clicked(gtx) {
+					if tab.currentResultItem != rowItem {
+						tab.currentResultItem = rowItem
+					}
+				}
+				if tab.currentResultItem == rowItem {
+					rowItem.label0.Font.Weight = font.Bold
+				} else {
+					rowItem.label0.Font.Weight = font.Normal
+				}
+
+				if statusIcon := rowItem.GetStatusIcon(); statusIcon != nil {
+					newIcon := common.NewStatusIcon(statusIcon.GetStatus(), statusIcon.GetReason())
+
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.End}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return newIcon.Layout(gtx, unit.Dp(14), &layout.Inset{Top: 3, Bottom: 0, Left: 1, Right: 2})
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Clickable(gtx, &rowItem.clickable, func(gtx layout.Context) layout.Dimensions {
+								return rowItem.label0.Layout(gtx)
+							})
+						}),
+					)
+				}
+				return material.Clickable(gtx, &rowItem.clickable, func(gtx layout.Context) layout.Dimensions {
+					return rowItem.label0.Layout(gtx)
+				})
+			}
+			l := material.Label(th, unit.Sp(15), value)
+			return l.Layout(gtx)
+		},
+	)
+}
+
 func (tab *InKubeTab) layoutCurrentDetail(gtx layout.Context) layout.Dimensions {
 	th := common.GetTheme()
 	title := tab.currentResultItem.item.GetKind() + ": " + tab.currentResultItem.item.GetName()
@@ -633,6 +987,13 @@ func NewInKubeTab(client k8sservice.K8sService) *InKubeTab {
 	tab.searchField.LineHeightScale = 0.8
 	tab.searchField.ReadOnly = true
 
+	for _, selectorField := range []*widget.Editor{&tab.labelField, &tab.fieldField} {
+		selectorField.SingleLine = true
+		selectorField.Submit = true
+		selectorField.LineHeight = unit.Sp(16)
+		selectorField.LineHeightScale = 0.8
+	}
+
 	exBtn := material.IconButton(th, &tab.exQueryButton, graphics.ExecIcon, "go")
 	exBtn.Inset = layout.Inset{Top: 0, Bottom: 0, Left: 1, Right: 1}
 	exBtn.Size = unit.Dp(22)
@@ -668,6 +1029,38 @@ func NewInKubeTab(client k8sservice.K8sService) *InKubeTab {
 		)
 	}
 
+	// pressing enter in a selector runs the query
+	selectorBar := func(gtx layout.Context) layout.Dimensions {
+		for _, selectorField := range []*widget.Editor{&tab.labelField, &tab.fieldField} {
+			for {
+				event, ok := selectorField.Update(gtx)
+				if !ok {
+					break
+				}
+				if _, ok := event.(widget.SubmitEvent); ok {
+					tab.startQuery()
+				}
+			}
+		}
+		selector := func(editor *widget.Editor, hint string) layout.Widget {
+			return func(gtx layout.Context) layout.Dimensions {
+				e := material.Editor(th, editor, hint)
+				e.TextSize = unit.Sp(14)
+				return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(2), Left: unit.Dp(4), Right: unit.Dp(12)}.Layout(gtx, e.Layout)
+			}
+		}
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Body2(th, "labels:").Layout(gtx)
+			}),
+			layout.Flexed(0.5, selector(&tab.labelField, "e.g. app=web,tier!=db")),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Body2(th, "fields:").Layout(gtx)
+			}),
+			layout.Flexed(0.5, selector(&tab.fieldField, "e.g. status.phase=Running")),
+		)
+	}
+
 	nsPanel := func(gtx layout.Context) layout.Dimensions {
 		return tab.nsList.Layout(gtx)
 		// return material.List(th, &tab.nsList).Layout(gtx, len(tab.namespaceItems), func(gtx layout.Context, index int) layout.Dimensions {
@@ -752,6 +1145,7 @@ func NewInKubeTab(client k8sservice.K8sService) *InKubeTab {
 			return material.H5(th, "No result.").Layout(gtx)
 		}
 		if uList, ok := result.([]*SearchResultItem); ok {
+			uList = tab.applyWatchEvents(uList)
 
 			// Configure a label styled to be a heading.
 			headingLabel := material.Body1(th, "")
@@ -770,78 +1164,28 @@ func NewInKubeTab(client k8sservice.K8sService) *InKubeTab {
 			//populate result
 			return tab.resultResize.Layout(gtx,
 				func(gtx layout.Context) layout.Dimensions {
-					return component.Table(th, &tab.grid).Layout(gtx, len(uList), len(headings),
-						func(axis layout.Axis, index, constraint int) int {
-							unit := int(constraint / 10)
-							switch axis {
-							case layout.Horizontal:
-								switch index {
-								case 0:
-									//give name a bit extra space
-									return int(unit * 5)
-								case 1:
-									return int(unit * 2)
-								case 2:
-									return int(unit * 3)
-								default:
-									return 0
-								}
-							default:
-								return dims.Size.Y + 2
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+							return tab.layoutResultTable(gtx, uList, inset, headingLabel, dims)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							notes := make([]string, 0)
+							if tab.hasMorePages() {
+								notes = append(notes, fmt.Sprintf("%d items loaded, scroll down for more", len(uList)))
 							}
-						},
-						func(gtx layout.Context, index int) layout.Dimensions {
-							return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-								headingLabel.Text = headings[index]
-								return headingLabel.Layout(gtx)
-							})
-						},
-						func(gtx layout.Context, row, col int) layout.Dimensions {
-							rowItem := uList[row]
-							value := ""
-							switch col {
-							case 0:
-								value = rowItem.item.GetName()
-							case 1:
-								value = rowItem.item.GetKind()
-							case 2:
-								value = rowItem.item.GetNamespace()
+							freshness := tab.cacheFreshness()
+							if freshness != nil {
+								notes = append(notes, freshness.String())
 							}
-
-							if col == 0 {
-								rowItem.label0.Text = value
-								if rowItem.clickable.Clicked(gtx) {
-									if tab.currentResultItem != rowItem {
-										tab.currentResultItem = rowItem
-									}
-								}
-								if tab.currentResultItem == rowItem {
-									rowItem.label0.Font.Weight = font.Bold
-								} else {
-									rowItem.label0.Font.Weight = font.Normal
-								}
-
-								if statusIcon := rowItem.GetStatusIcon(); statusIcon != nil {
-									newIcon := common.NewStatusIcon(statusIcon.GetStatus(), statusIcon.GetReason())
-
-									return layout.Flex{Axis: layout.Horizontal, Alignment: layout.End}.Layout(gtx,
-										layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-											return newIcon.Layout(gtx, unit.Dp(14), &layout.Inset{Top: 3, Bottom: 0, Left: 1, Right: 2})
-										}),
-										layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-											return material.Clickable(gtx, &rowItem.clickable, func(gtx layout.Context) layout.Dimensions {
-												return rowItem.label0.Layout(gtx)
-											})
-										}),
-									)
+							if len(notes) > 0 {
+								caption := material.Caption(th, strings.Join(notes, ", "))
+								if freshness != nil && freshness.Stale {
+									caption.Color = common.COLOR.Red
 								}
-								return material.Clickable(gtx, &rowItem.clickable, func(gtx layout.Context) layout.Dimensions {
-									return rowItem.label0.Layout(gtx)
-								})
+								return caption.Layout(gtx)
 							}
-							l := material.Label(th, unit.Sp(15), value)
-							return l.Layout(gtx)
-						},
+							return layout.Dimensions{}
+						}),
 					)
 				},
 				func(gtx layout.Context) layout.Dimensions {
@@ -908,20 +1252,13 @@ func NewInKubeTab(client k8sservice.K8sService) *InKubeTab {
 										div.Thickness = unit.Dp(2)
 										return div.Layout(gtx)
 									}),
+									layout.Rigid(selectorBar),
 								)
 							})
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							if tab.exQueryButton.Clicked(gtx) {
-								if !tab.inQuery {
-									tab.inQuery = true
-									go func() {
-										result, _ := tab.Query()
-										resultList := getSearchResultList(result)
-										common.SetContextData(CONTEXT_KEY_API_SEARCH_RESULT, resultList, nil)
-										tab.inQuery = false
-									}()
-								}
+								tab.startQuery()
 							}
 
 							return layout.Inset{Top: 0, Bottom: 0, Left: 0, Right: unit.Dp(8)}.Layout(gtx,
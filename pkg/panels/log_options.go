@@ -0,0 +1,183 @@
+package panels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/graphics"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+)
+
+// LogOptionsBar lets the user choose which part of a log is read and
+// whether it is followed. The options take effect when applied.
+type LogOptionsBar struct {
+	follow     widget.Bool
+	autoScroll widget.Bool
+	timestamps widget.Bool
+	previous   widget.Bool
+	tailField  widget.Editor
+	sinceField widget.Editor
+
+	applyBtn        widget.Clickable
+	applyBtnTooltip component.Tooltip
+	applyBtnTipArea component.TipArea
+
+	options k8sservice.PodLogOptions
+	err     string
+	// the logs are always followed, follow and previous are not offered
+	streaming bool
+}
+
+// Options returns the options last applied
+func (b *LogOptionsBar) Options() k8sservice.PodLogOptions {
+	return b.options
+}
+
+// AutoScroll tells if the log view should keep the newest lines in view
+func (b *LogOptionsBar) AutoScroll() bool {
+	return b.autoScroll.Value
+}
+
+// parseLogOptions reads the options, since is a duration like 10m or an RFC3339 time
+func parseLogOptions(follow, timestamps, previous bool, tail, since string) (k8sservice.PodLogOptions, error) {
+	opts := k8sservice.PodLogOptions{
+		Follow:     follow,
+		Timestamps: timestamps,
+		Previous:   previous,
+	}
+	if tail = strings.TrimSpace(tail); tail != "" {
+		lines, err := strconv.ParseInt(tail, 10, 64)
+		if err != nil || lines < 0 {
+			return opts, fmt.Errorf("invalid tail lines %q", tail)
+		}
+		opts.TailLines = lines
+	}
+	if since = strings.TrimSpace(since); since != "" {
+		if duration, err := time.ParseDuration(since); err == nil {
+			if duration <= 0 {
+				return opts, fmt.Errorf("invalid since %q", since)
+			}
+			// the api server takes whole seconds
+			opts.SinceSeconds = int64((duration + time.Second - 1) / time.Second)
+		} else if sinceTime, err := time.Parse(time.RFC3339, since); err == nil {
+			opts.SinceTime = &sinceTime
+		} else {
+			return opts, fmt.Errorf("invalid since %q, use a duration like 10m or an RFC3339 time", since)
+		}
+	}
+	return opts, nil
+}
+
+func (b *LogOptionsBar) apply() bool {
+	opts, err := parseLogOptions(b.follow.Value, b.timestamps.Value, b.previous.Value, b.tailField.Text(), b.sinceField.Text())
+	if err != nil {
+		b.err = err.Error()
+		return false
+	}
+	b.err = ""
+	b.options = opts
+	return true
+}
+
+// Layout draws the bar, it returns true when new options are applied
+func (b *LogOptionsBar) Layout(gtx layout.Context) (layout.Dimensions, bool) {
+	th := common.GetTheme()
+
+	applied := false
+	if b.applyBtn.Clicked(gtx) {
+		applied = b.apply()
+	}
+	for _, field := range []*widget.Editor{&b.tailField, &b.sinceField} {
+		for {
+			event, ok := field.Update(gtx)
+			if !ok {
+				break
+			}
+			if _, ok := event.(widget.SubmitEvent); ok {
+				applied = b.apply()
+			}
+		}
+	}
+
+	checkBox := func(value *widget.Bool, label string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			check := material.CheckBox(th, value, label)
+			check.Size = unit.Dp(16)
+			check.TextSize = unit.Sp(13)
+			return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, check.Layout)
+		})
+	}
+	editor := func(field *widget.Editor, hint string, width unit.Dp) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(width)
+			gtx.Constraints.Max.X = gtx.Dp(width)
+			ed := material.Editor(th, field, hint)
+			ed.TextSize = unit.Sp(13)
+			return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, ed.Layout)
+		})
+	}
+
+	applyBtn := component.TipIconButtonStyle{
+		Tooltip:         b.applyBtnTooltip,
+		IconButtonStyle: material.IconButton(th, &b.applyBtn, graphics.ReloadIcon, "Apply"),
+		State:           &b.applyBtnTipArea,
+	}
+	applyBtn.Size = 16
+	applyBtn.IconButtonStyle.Inset = layout.Inset{Top: 1, Bottom: 1, Left: 1, Right: 1}
+
+	children := make([]layout.FlexChild, 0)
+	if !b.streaming {
+		children = append(children, checkBox(&b.follow, "follow"))
+	}
+	children = append(children,
+		checkBox(&b.autoScroll, "auto-scroll"),
+		checkBox(&b.timestamps, "timestamps"))
+	if !b.streaming {
+		children = append(children, checkBox(&b.previous, "previous"))
+	}
+	children = append(children,
+		editor(&b.tailField, "tail lines", 70),
+		editor(&b.sinceField, "since (10m)", 90),
+		layout.Rigid(applyBtn.Layout),
+		layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+			if b.err == "" {
+				return layout.Dimensions{}
+			}
+			label := material.Body2(th, b.err)
+			label.Color = common.COLOR.Red
+			return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, label.Layout)
+		}))
+
+	dims := layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+	return dims, applied
+}
+
+func NewLogOptionsBar() *LogOptionsBar {
+	th := common.GetTheme()
+	bar := &LogOptionsBar{}
+	bar.autoScroll.Value = true
+	bar.tailField.SingleLine = true
+	bar.tailField.Submit = true
+	bar.sinceField.SingleLine = true
+	bar.sinceField.Submit = true
+	bar.applyBtnTooltip = component.DesktopTooltip(th, "Apply and reload")
+	return bar
+}
+
+// NewStreamLogOptionsBar is for logs that are always followed,
+// it starts with the last 10 lines of each log.
+func NewStreamLogOptionsBar() *LogOptionsBar {
+	bar := NewLogOptionsBar()
+	bar.streaming = true
+	bar.tailField.SetText("10")
+	bar.apply()
+	return bar
+}
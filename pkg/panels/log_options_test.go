@@ -0,0 +1,35 @@
+package panels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogOptions(t *testing.T) {
+	opts, err := parseLogOptions(true, false, true, " 100 ", "90s")
+	if err != nil {
+		t.Fatalf("failed to parse options: %v", err)
+	}
+	if !opts.Follow || opts.Timestamps || !opts.Previous || opts.TailLines != 100 || opts.SinceSeconds != 90 || opts.SinceTime != nil {
+		t.Errorf("wrong options %+v", opts)
+	}
+
+	// partial seconds are rounded up
+	if opts, _ := parseLogOptions(false, false, false, "", "1500ms"); opts.SinceSeconds != 2 {
+		t.Errorf("wrong since seconds %d", opts.SinceSeconds)
+	}
+
+	opts, err = parseLogOptions(false, true, false, "", "2024-05-01T10:00:00Z")
+	if err != nil {
+		t.Fatalf("failed to parse options: %v", err)
+	}
+	if opts.SinceTime == nil || !opts.SinceTime.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) || opts.SinceSeconds != 0 {
+		t.Errorf("wrong since time %v", opts.SinceTime)
+	}
+
+	for _, invalid := range [][2]string{{"ten", ""}, {"-1", ""}, {"", "yesterday"}, {"", "-5m"}} {
+		if _, err := parseLogOptions(false, false, false, invalid[0], invalid[1]); err == nil {
+			t.Errorf("tail %q since %q should be invalid", invalid[0], invalid[1])
+		}
+	}
+}
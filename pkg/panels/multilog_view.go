@@ -0,0 +1,325 @@
+package panels
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"slices"
+	"strings"
+	"sync"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// the lines kept in an aggregated log, older lines are dropped
+const multiLogLineLimit = 10000
+
+// colors of the log sources, readable on the light background
+var logSourceColors = []color.NRGBA{
+	{R: 0, G: 110, B: 200, A: 255},
+	{R: 200, G: 80, B: 0, A: 255},
+	{R: 0, G: 140, B: 70, A: 255},
+	{R: 160, G: 0, B: 160, A: 255},
+	{R: 180, G: 20, B: 40, A: 255},
+	{R: 0, G: 130, B: 140, A: 255},
+	{R: 120, G: 90, B: 0, A: 255},
+	{R: 90, G: 60, B: 200, A: 255},
+}
+
+func logSourceColor(source string) color.NRGBA {
+	h := fnv.New32a()
+	h.Write([]byte(source))
+	return logSourceColors[h.Sum32()%uint32(len(logSourceColors))]
+}
+
+// MultiPodLogView shows the logs of all pods matching a label selector in
+// one list, each line prefixed with its pod and container.
+type MultiPodLogView struct {
+	// the target can't be changed, e.g. the pods of a workload
+	fixed          bool
+	namespaceField component.TextField
+	selectorField  component.TextField
+	optionsBar     *LogOptionsBar
+	startBtn       widget.Clickable
+	clearBtn       widget.Clickable
+	list           widget.List
+
+	lock    sync.Mutex
+	lines   []k8sservice.LogLine
+	cancel  context.CancelFunc
+	running bool
+	status  string
+}
+
+func (v *MultiPodLogView) addLine(line k8sservice.LogLine) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.lines = append(v.lines, line)
+	if len(v.lines) > multiLogLineLimit+multiLogLineLimit/10 {
+		// a copy, the lines being drawn are not touched
+		v.lines = slices.Clone(v.lines[len(v.lines)-multiLogLineLimit:])
+	}
+	if win := common.GetAppWindow(); win != nil {
+		win.Invalidate()
+	}
+}
+
+func (v *MultiPodLogView) isRunning() bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.running
+}
+
+// Start streams the logs of the pods, the stream of a previous start is stopped
+func (v *MultiPodLogView) Start() {
+	v.Stop()
+
+	namespace := strings.TrimSpace(v.namespaceField.Text())
+	selector := strings.TrimSpace(v.selectorField.Text())
+	if selector == "" {
+		v.lock.Lock()
+		v.status = "a label selector is required"
+		v.lock.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.lock.Lock()
+	v.cancel = cancel
+	v.running = true
+	v.status = ""
+	v.lock.Unlock()
+
+	opts := v.optionsBar.Options()
+	go func() {
+		err := k8sservice.StreamPodLogs(ctx, k8sservice.GetK8sService(), namespace, selector, opts, v.addLine)
+		if err != nil {
+			logger.Info("failed to stream pod logs", zap.String("selector", selector), zap.Error(err))
+		}
+		v.lock.Lock()
+		defer v.lock.Unlock()
+		if err != nil {
+			v.status = err.Error()
+		}
+		if ctx.Err() == nil {
+			v.running = false
+		}
+	}()
+}
+
+// Stop ends the streaming, the lines read are kept
+func (v *MultiPodLogView) Stop() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.cancel != nil {
+		v.cancel()
+		v.cancel = nil
+	}
+	v.running = false
+}
+
+// Text returns the lines read, prefixed with their source
+func (v *MultiPodLogView) Text() string {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	builder := strings.Builder{}
+	for _, line := range v.lines {
+		fmt.Fprintf(&builder, "[%s/%s] %s\n", line.Pod, line.Container, line.Text)
+	}
+	return builder.String()
+}
+
+func (v *MultiPodLogView) Layout(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+
+	running := v.isRunning()
+	if v.startBtn.Clicked(gtx) {
+		if running {
+			v.Stop()
+		} else {
+			v.Start()
+		}
+	}
+	if v.clearBtn.Clicked(gtx) {
+		v.lock.Lock()
+		v.lines = nil
+		v.lock.Unlock()
+	}
+	v.list.ScrollToEnd = v.optionsBar.AutoScroll()
+
+	targetBar := func(gtx layout.Context) layout.Dimensions {
+		if v.fixed {
+			label := material.Body1(th, fmt.Sprintf("pods in %s matching %s", v.namespaceField.Text(), v.selectorField.Text()))
+			return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, label.Layout)
+		}
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
+			layout.Flexed(0.3, func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return v.namespaceField.Layout(gtx, th, "Namespace")
+				})
+			}),
+			layout.Flexed(0.7, func(gtx layout.Context) layout.Dimensions {
+				return v.selectorField.Layout(gtx, th, "Label selector, e.g. app=web")
+			}),
+		)
+	}
+
+	actionBar := func(gtx layout.Context) layout.Dimensions {
+		startText := "Start"
+		if running {
+			startText = "Stop"
+		}
+		v.lock.Lock()
+		status := v.status
+		v.lock.Unlock()
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				dims, applied := v.optionsBar.Layout(gtx)
+				if applied && running {
+					v.Start()
+				}
+				return dims
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(5)}.Layout(gtx, material.Button(th, &v.startBtn, startText).Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(5)}.Layout(gtx, material.Button(th, &v.clearBtn, "Clear").Layout)
+			}),
+			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(th, status)
+				label.Color = common.COLOR.Red
+				return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, label.Layout)
+			}),
+		)
+	}
+
+	v.lock.Lock()
+	lines := v.lines
+	v.lock.Unlock()
+
+	logLine := func(gtx layout.Context, index int) layout.Dimensions {
+		line := lines[index]
+		source := line.Pod + "/" + line.Container
+		prefix := material.Body2(th, "["+source+"] ")
+		prefix.Font.Typeface = "monospace"
+		prefix.Font.Weight = font.Bold
+		prefix.Color = logSourceColor(source)
+		text := material.Body2(th, line.Text)
+		text.Font.Typeface = "monospace"
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+			layout.Rigid(prefix.Layout),
+			layout.Flexed(1.0, text.Layout),
+		)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(targetBar),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, actionBar)
+		}),
+		layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+			return material.List(th, &v.list).Layout(gtx, len(lines), logLine)
+		}),
+	)
+}
+
+func NewMultiPodLogView() *MultiPodLogView {
+	v := &MultiPodLogView{
+		optionsBar: NewStreamLogOptionsBar(),
+	}
+	v.list.Axis = layout.Vertical
+	v.namespaceField.SingleLine = true
+	v.namespaceField.SetText("default")
+	v.selectorField.SingleLine = true
+	return v
+}
+
+// MultiPodLogTool streams the logs of the pods matching a label selector
+type MultiPodLogTool struct {
+	clickable widget.Clickable
+	view      *MultiPodLogView
+}
+
+func (t *MultiPodLogTool) GetClickable() *widget.Clickable {
+	return &t.clickable
+}
+
+func (t *MultiPodLogTool) GetName() string {
+	return "pod-logs"
+}
+
+func (t *MultiPodLogTool) GetTabButtons() []layout.FlexChild {
+	return nil
+}
+
+func (t *MultiPodLogTool) GetWidget() layout.Widget {
+	return t.view.Layout
+}
+
+func NewMultiPodLogTool() *MultiPodLogTool {
+	return &MultiPodLogTool{
+		view: NewMultiPodLogView(),
+	}
+}
+
+// WorkloadLogDetail streams the logs of the pods of a workload
+type WorkloadLogDetail struct {
+	*ResourceDetail
+	view    *MultiPodLogView
+	started bool
+}
+
+// Changed implements common.IResourceDetail.
+func (w *WorkloadLogDetail) Changed() bool {
+	return false
+}
+
+// Save implements common.IResourceDetail.
+func (w *WorkloadLogDetail) Save(baseDir string, kind string, name string, ns string) {
+	filePath := common.CreateFilePathForK8sObject(baseDir, kind, name, ns, "pod-logs", "log")
+	content := w.view.Text()
+	if err := common.SaveFile(filePath, &content); err != nil {
+		logger.Error("Failed to save pod logs", zap.String("file", filePath), zap.Error(err))
+	}
+}
+
+// GetContent implements common.IResourceDetail.
+func (w *WorkloadLogDetail) GetContent() layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		if !w.started {
+			// streams once it is looked at
+			w.started = true
+			w.view.Start()
+		}
+		return w.view.Layout(gtx)
+	}
+}
+
+// the kinds whose pods are found by spec.selector
+var workloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job", "Service"}
+
+func NewWorkloadLogDetail(item *unstructured.Unstructured) (*WorkloadLogDetail, error) {
+	selector, err := k8sservice.SelectorOf(item)
+	if err != nil {
+		return nil, err
+	}
+	view := NewMultiPodLogView()
+	view.fixed = true
+	view.namespaceField.SetText(item.GetNamespace())
+	view.selectorField.SetText(selector)
+	return &WorkloadLogDetail{
+		ResourceDetail: NewDetail("pod-logs", item),
+		view:           view,
+	}, nil
+}
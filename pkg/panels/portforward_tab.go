@@ -0,0 +1,228 @@
+package panels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/graphics"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+)
+
+// PortForwardTab starts port forwards and shows the active ones
+type PortForwardTab struct {
+	client k8sservice.K8sService
+	tab    widget.Clickable
+
+	refreshBtn        widget.Clickable
+	refreshBtnTooltip component.Tooltip
+	refreshBtnTipArea component.TipArea
+
+	namespaceField component.TextField
+	nameField      component.TextField
+	portField      component.TextField
+	localPortField component.TextField
+	kind           widget.Enum
+	startBtn       widget.Clickable
+	startErr       string
+
+	forwardList widget.List
+	stopBtns    map[string]*widget.Clickable
+
+	rigidButtons []layout.FlexChild
+	widget       layout.Widget
+}
+
+// GetClickable implements PanelTab.
+func (p *PortForwardTab) GetClickable() *widget.Clickable {
+	return &p.tab
+}
+
+// GetTabButtons implements PanelTab.
+func (p *PortForwardTab) GetTabButtons() []layout.FlexChild {
+	return p.rigidButtons
+}
+
+// GetTitle implements PanelTab.
+func (p *PortForwardTab) GetTitle() string {
+	return "port-forward"
+}
+
+// GetWidget implements PanelTab.
+func (p *PortForwardTab) GetWidget() layout.Widget {
+	return p.widget
+}
+
+func (p *PortForwardTab) start() {
+	port, err := strconv.Atoi(strings.TrimSpace(p.portField.Text()))
+	if err != nil || port <= 0 {
+		p.startErr = "invalid port"
+		return
+	}
+	localPort := 0
+	if text := strings.TrimSpace(p.localPortField.Text()); text != "" {
+		if localPort, err = strconv.Atoi(text); err != nil {
+			p.startErr = "invalid local port"
+			return
+		}
+	}
+	namespace := strings.TrimSpace(p.namespaceField.Text())
+	if namespace == "" {
+		namespace = "default"
+	}
+	_, err = p.client.StartPortForward(k8sservice.PortForwardSpec{
+		Target: k8sservice.PortForwardTarget{
+			Namespace: namespace,
+			Kind:      p.kind.Value,
+			Name:      strings.TrimSpace(p.nameField.Text()),
+			Port:      int32(port),
+		},
+		LocalPort: localPort,
+	})
+	if err != nil {
+		p.startErr = err.Error()
+		return
+	}
+	p.startErr = ""
+}
+
+func (p *PortForwardTab) stopButton(id string) *widget.Clickable {
+	btn, ok := p.stopBtns[id]
+	if !ok {
+		btn = &widget.Clickable{}
+		p.stopBtns[id] = btn
+	}
+	return btn
+}
+
+func NewPortForwardTab(client k8sservice.K8sService) *PortForwardTab {
+	th := common.GetTheme()
+
+	tab := &PortForwardTab{
+		client:   client,
+		stopBtns: make(map[string]*widget.Clickable),
+	}
+	tab.kind.Value = "Pod"
+	tab.forwardList.Axis = layout.Vertical
+	for _, field := range []*component.TextField{&tab.namespaceField, &tab.nameField, &tab.portField, &tab.localPortField} {
+		field.SingleLine = true
+	}
+
+	tab.rigidButtons = make([]layout.FlexChild, 0)
+
+	tab.refreshBtnTooltip = component.DesktopTooltip(th, "Refresh")
+
+	refreshBtn := component.TipIconButtonStyle{
+		Tooltip:         tab.refreshBtnTooltip,
+		IconButtonStyle: material.IconButton(th, &tab.refreshBtn, graphics.RefreshIcon, "Refresh"),
+		State:           &tab.refreshBtnTipArea,
+	}
+
+	refreshBtn.Size = 16
+	refreshBtn.IconButtonStyle.Inset = layout.Inset{Top: 1, Bottom: 1, Left: 1, Right: 1}
+
+	rigid1 := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		// the list is read on each frame, a click just redraws it
+		tab.refreshBtn.Clicked(gtx)
+		return layout.Inset{Top: 4, Bottom: 0, Left: 0, Right: 4}.Layout(gtx, refreshBtn.Layout)
+	})
+	tab.rigidButtons = append(tab.rigidButtons, rigid1)
+
+	startBtn := material.Button(th, &tab.startBtn, "Start")
+
+	field := func(field *component.TextField, hint string, weight float32) layout.FlexChild {
+		return layout.Flexed(weight, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return field.Layout(gtx, th, hint)
+			})
+		})
+	}
+
+	actionBar := func(gtx layout.Context) layout.Dimensions {
+		if tab.startBtn.Clicked(gtx) {
+			tab.start()
+		}
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(material.RadioButton(th, &tab.kind, "Pod", "Pod").Layout),
+			layout.Rigid(material.RadioButton(th, &tab.kind, "Service", "Service").Layout),
+			field(&tab.namespaceField, "Namespace", 0.25),
+			field(&tab.nameField, "Name", 0.35),
+			field(&tab.portField, "Port", 0.2),
+			field(&tab.localPortField, "Local port (optional)", 0.2),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(5), Right: unit.Dp(5)}.Layout(gtx, startBtn.Layout)
+			}),
+		)
+	}
+
+	errorBar := func(gtx layout.Context) layout.Dimensions {
+		if tab.startErr == "" {
+			return layout.Dimensions{}
+		}
+		label := material.Body2(th, tab.startErr)
+		label.Color = common.COLOR.Red
+		return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, label.Layout)
+	}
+
+	forwardRow := func(gtx layout.Context, fwd k8sservice.PortForward) layout.Dimensions {
+		stopBtn := tab.stopButton(fwd.Id)
+		if stopBtn.Clicked(gtx) {
+			if err := tab.client.StopPortForward(fwd.Id); err != nil {
+				tab.startErr = err.Error()
+			}
+			delete(tab.stopBtns, fwd.Id)
+		}
+		address := material.Body1(th, fwd.LocalAddress)
+		address.Font.Weight = font.Bold
+		status := fmt.Sprintf("%d connections", fwd.Connections)
+		if fwd.LastError != "" {
+			status += ", last error: " + fwd.LastError
+		}
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.IconButtonStyle{
+					Background: th.Palette.Bg,
+					Color:      th.Palette.Fg,
+					Icon:       graphics.CloseIcon,
+					Size:       unit.Dp(16),
+					Inset:      layout.UniformInset(unit.Dp(2)),
+					Button:     stopBtn,
+				}.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(6), Right: unit.Dp(10)}.Layout(gtx, address.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, material.Body1(th, "-> "+fwd.Target.String()).Layout)
+			}),
+			layout.Flexed(1.0, material.Body2(th, status).Layout),
+		)
+	}
+
+	tab.widget = func(gtx layout.Context) layout.Dimensions {
+		forwards := tab.client.ListPortForwards()
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(actionBar),
+			layout.Rigid(errorBar),
+			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: 6}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if len(forwards) == 0 {
+						return material.Body2(th, "No active port forwards").Layout(gtx)
+					}
+					return material.List(th, &tab.forwardList).Layout(gtx, len(forwards), func(gtx layout.Context, index int) layout.Dimensions {
+						return forwardRow(gtx, forwards[index])
+					})
+				})
+			}),
+		)
+	}
+
+	return tab
+}
@@ -0,0 +1,471 @@
+package panels
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/config"
+	"gaohoward.tools/k8s/resutil/pkg/graphics"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"go.uber.org/zap"
+)
+
+var RAW_API_METHODS = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+var RAW_API_PATCH_TYPES = []string{"merge", "json", "strategic", "apply"}
+
+// the requests kept in the history, the oldest are dropped
+const RAW_API_HISTORY_SIZE = 50
+
+// RawApiRequest is a request of the raw api tool as it is kept in the history
+type RawApiRequest struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	PatchType string `json:"patch_type,omitempty"`
+	// as typed, a "Name: value" per line
+	Headers string    `json:"headers,omitempty"`
+	Body    string    `json:"body,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+func (r *RawApiRequest) sameAs(other *RawApiRequest) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.PatchType == other.PatchType &&
+		r.Headers == other.Headers && r.Body == other.Body
+}
+
+func methodHasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// ParseHeaders parses a "Name: value" per line, empty lines are skipped
+func ParseHeaders(text string) (http.Header, error) {
+	headers := make(http.Header)
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid header %q, it should be Name: value", line)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+func (r *RawApiRequest) toRawRequest() (*k8sservice.RawRequest, error) {
+	headers, err := ParseHeaders(r.Headers)
+	if err != nil {
+		return nil, err
+	}
+	req := &k8sservice.RawRequest{
+		Method:  r.Method,
+		Path:    r.Path,
+		Headers: headers,
+	}
+	if methodHasBody(r.Method) {
+		req.Body = r.Body
+	}
+	if r.Method == http.MethodPatch {
+		req.ContentType = r.PatchType
+	}
+	return req, nil
+}
+
+// FormatRawResponse shows the status line and headers of the response before its body
+func FormatRawResponse(resp *k8sservice.RawResponse) string {
+	builder := strings.Builder{}
+	names := make([]string, 0, len(resp.Headers))
+	for name := range resp.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range resp.Headers[name] {
+			fmt.Fprintf(&builder, "%s: %s\n", name, value)
+		}
+	}
+	builder.WriteString("\n")
+	builder.WriteString(resp.Body)
+	return builder.String()
+}
+
+// RawApiHistory is the requests sent by the raw api tool, the latest first.
+// It is saved in the tool directory.
+type RawApiHistory struct {
+	lock     sync.Mutex
+	file     string
+	requests []*RawApiRequest
+}
+
+func LoadRawApiHistory(dir string) (*RawApiHistory, error) {
+	history := &RawApiHistory{
+		file:     filepath.Join(dir, "history.json"),
+		requests: make([]*RawApiRequest, 0),
+	}
+	data, err := os.ReadFile(history.file)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return history, err
+	}
+	if err := json.Unmarshal(data, &history.requests); err != nil {
+		return history, fmt.Errorf("invalid history file %s: %w", history.file, err)
+	}
+	return history, nil
+}
+
+// Requests returns a copy of the requests
+func (h *RawApiHistory) Requests() []*RawApiRequest {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return slices.Clone(h.requests)
+}
+
+// Add puts req first, a request sent before is moved instead of repeated
+func (h *RawApiHistory) Add(req *RawApiRequest) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.requests = slices.DeleteFunc(h.requests, req.sameAs)
+	h.requests = slices.Insert(h.requests, 0, req)
+	if len(h.requests) > RAW_API_HISTORY_SIZE {
+		h.requests = h.requests[:RAW_API_HISTORY_SIZE]
+	}
+	return h.save()
+}
+
+func (h *RawApiHistory) Remove(req *RawApiRequest) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.requests = slices.DeleteFunc(h.requests, func(r *RawApiRequest) bool {
+		return r == req
+	})
+	return h.save()
+}
+
+func (h *RawApiHistory) save() error {
+	data, err := json.MarshalIndent(h.requests, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.file, data, 0600)
+}
+
+type RawApiTool struct {
+	kubeClient k8sservice.K8sService
+	widget     layout.Widget
+	clickable  widget.Clickable
+	method     widget.Enum
+	patchType  widget.Enum
+	uriField   component.TextField
+	headers    widget.Editor
+	body       widget.Editor
+	runBtn     widget.Clickable
+	result     *common.ReadOnlyEditor
+
+	lock sync.Mutex
+	// the status line of the last response, or the error
+	status   string
+	statusOk bool
+
+	// nil if the tool dir is not available
+	history     *RawApiHistory
+	historyList widget.List
+	historyBtns map[*RawApiRequest]*historyButtons
+	resize      component.Resize
+}
+
+type historyButtons struct {
+	load   widget.Clickable
+	remove widget.Clickable
+}
+
+func (rat *RawApiTool) currentRequest() *RawApiRequest {
+	return &RawApiRequest{
+		Method:    rat.method.Value,
+		Path:      strings.TrimSpace(rat.uriField.Text()),
+		PatchType: rat.patchType.Value,
+		Headers:   rat.headers.Text(),
+		Body:      rat.body.Text(),
+		Time:      time.Now(),
+	}
+}
+
+func (rat *RawApiTool) load(req *RawApiRequest) {
+	rat.method.Value = req.Method
+	rat.uriField.SetText(req.Path)
+	if req.PatchType != "" {
+		rat.patchType.Value = req.PatchType
+	}
+	rat.headers.SetText(req.Headers)
+	rat.body.SetText(req.Body)
+}
+
+func (rat *RawApiTool) setStatus(status string, ok bool) {
+	rat.lock.Lock()
+	defer rat.lock.Unlock()
+	rat.status = status
+	rat.statusOk = ok
+}
+
+func (rat *RawApiTool) getStatus() (string, bool) {
+	rat.lock.Lock()
+	defer rat.lock.Unlock()
+	return rat.status, rat.statusOk
+}
+
+func (rat *RawApiTool) Run(req *RawApiRequest) {
+	defer common.GetAppWindow().Invalidate()
+	rawReq, err := req.toRawRequest()
+	if err != nil {
+		rat.setStatus("Error: "+err.Error(), false)
+		return
+	}
+	if rat.history != nil {
+		if err := rat.history.Add(req); err != nil {
+			logger.Warn("failed to save the raw api history", zap.Error(err))
+		}
+	}
+	resp, err := rat.kubeClient.DoRawRequest(rawReq)
+	if err != nil {
+		rat.setStatus("Error: "+err.Error(), false)
+		empty := ""
+		rat.result.SetText(&empty, nil)
+		return
+	}
+	rat.setStatus(resp.Status, resp.IsSuccess())
+	text := FormatRawResponse(resp)
+	rat.result.SetText(&text, nil)
+}
+
+func (rat *RawApiTool) GetClickable() *widget.Clickable {
+	return &rat.clickable
+}
+
+func (rat *RawApiTool) GetName() string {
+	return "raw-api"
+}
+
+func (rat *RawApiTool) GetTabButtons() []layout.FlexChild {
+	return nil
+}
+
+func (rat *RawApiTool) GetWidget() layout.Widget {
+	return rat.widget
+}
+
+func (rat *RawApiTool) historyButtons(req *RawApiRequest) *historyButtons {
+	btns, ok := rat.historyBtns[req]
+	if !ok {
+		btns = &historyButtons{}
+		rat.historyBtns[req] = btns
+	}
+	return btns
+}
+
+// The raw api tools allows users to access api resources
+// in raw http format, i.e. the kubectl raw option, for example
+// kubectl get --raw "/apis/subresources.kubevirt.io"
+// or "/apis/subresources.kubevirt.io/v1/guestfs"
+// Other methods send the body with the patch type or the headers given.
+func NewRawApiTool(client k8sservice.K8sService) *RawApiTool {
+	th := common.GetTheme()
+
+	rt := &RawApiTool{
+		kubeClient:  client,
+		historyBtns: make(map[*RawApiRequest]*historyButtons),
+	}
+	rt.method.Value = http.MethodGet
+	rt.patchType.Value = RAW_API_PATCH_TYPES[0]
+	rt.historyList.Axis = layout.Vertical
+	rt.resize.Ratio = 0.75
+
+	if cfg, err := config.GetConfig(); err == nil {
+		if dir, err := cfg.GetToolDir("raw-api"); err == nil {
+			if rt.history, err = LoadRawApiHistory(dir); err != nil {
+				logger.Warn("failed to load the raw api history", zap.Error(err))
+			}
+		}
+	}
+
+	rt.result = common.NewReadOnlyEditor("result", 16, nil, nil, true)
+
+	rt.uriField.SingleLine = true
+	rt.uriField.Submit = true
+
+	goBtn := material.Button(th, &rt.runBtn, "Go!")
+
+	radios := func(group *widget.Enum, values []string) []layout.FlexChild {
+		children := make([]layout.FlexChild, 0, len(values))
+		for _, value := range values {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				radio := material.RadioButton(th, group, value, value)
+				radio.TextSize = unit.Sp(13)
+				return radio.Layout(gtx)
+			}))
+		}
+		return children
+	}
+
+	actionBar := func(gtx layout.Context) layout.Dimensions {
+		submitted := false
+		for {
+			event, ok := rt.uriField.Editor.Update(gtx)
+			if !ok {
+				break
+			}
+			if _, ok := event.(widget.SubmitEvent); ok {
+				submitted = true
+			}
+		}
+		if rt.runBtn.Clicked(gtx) || submitted {
+			go rt.Run(rt.currentRequest())
+		}
+		children := radios(&rt.method, RAW_API_METHODS)
+		children = append(children,
+			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				return rt.uriField.Layout(gtx, th, "URI:")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(5), Right: unit.Dp(5)}.Layout(gtx, goBtn.Layout)
+			}),
+		)
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+	}
+
+	bordered := func(gtx layout.Context, height unit.Dp, editor *widget.Editor, hint string) layout.Dimensions {
+		gtx.Constraints.Min.Y = gtx.Dp(height)
+		gtx.Constraints.Max.Y = gtx.Dp(height)
+		return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return widget.Border{Color: common.COLOR.LightGray, Width: unit.Dp(1)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min = gtx.Constraints.Max
+				ed := material.Editor(th, editor, hint)
+				ed.Font.Typeface = "Monospace"
+				ed.TextSize = unit.Sp(14)
+				return layout.UniformInset(unit.Dp(4)).Layout(gtx, ed.Layout)
+			})
+		})
+	}
+
+	requestArea := func(gtx layout.Context) layout.Dimensions {
+		children := []layout.FlexChild{
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return bordered(gtx, 60, &rt.headers, "headers, a 'Name: value' per line")
+			}),
+		}
+		if rt.method.Value == http.MethodPatch {
+			patchTypes := []layout.FlexChild{
+				layout.Rigid(material.Body2(th, "patch type:").Layout),
+			}
+			patchTypes = append(patchTypes, radios(&rt.patchType, RAW_API_PATCH_TYPES)...)
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, patchTypes...)
+			}))
+		}
+		if methodHasBody(rt.method.Value) {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return bordered(gtx, 160, &rt.body, "request body")
+			}))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	}
+
+	statusBar := func(gtx layout.Context) layout.Dimensions {
+		status, ok := rt.getStatus()
+		if status == "" {
+			return layout.Dimensions{}
+		}
+		label := material.Body1(th, status)
+		label.Font.Weight = font.Bold
+		if !ok {
+			label.Color = common.COLOR.Red
+		}
+		return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, label.Layout)
+	}
+
+	resultArea := func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: 6, Bottom: 0, Left: 0, Right: 0}.Layout(gtx, rt.result.Layout)
+	}
+
+	historyRow := func(gtx layout.Context, req *RawApiRequest) layout.Dimensions {
+		btns := rt.historyButtons(req)
+		if btns.load.Clicked(gtx) {
+			rt.load(req)
+		}
+		if btns.remove.Clicked(gtx) {
+			if err := rt.history.Remove(req); err != nil {
+				logger.Warn("failed to save the raw api history", zap.Error(err))
+			}
+			delete(rt.historyBtns, req)
+		}
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.IconButtonStyle{
+					Background: th.Palette.Bg,
+					Color:      th.Palette.Fg,
+					Icon:       graphics.DeleteIcon,
+					Size:       unit.Dp(14),
+					Inset:      layout.UniformInset(unit.Dp(2)),
+					Button:     &btns.remove,
+				}.Layout(gtx)
+			}),
+			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				return material.Clickable(gtx, &btns.load, func(gtx layout.Context) layout.Dimensions {
+					label := material.Body2(th, req.Method+" "+req.Path)
+					label.MaxLines = 1
+					return layout.Inset{Left: unit.Dp(4), Top: unit.Dp(2), Bottom: unit.Dp(2)}.Layout(gtx, label.Layout)
+				})
+			}),
+		)
+	}
+
+	historyArea := func(gtx layout.Context) layout.Dimensions {
+		if rt.history == nil {
+			return material.Body2(th, "history not available").Layout(gtx)
+		}
+		requests := rt.history.Requests()
+		return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Body1(th, "History")
+					label.Font.Weight = font.Bold
+					return label.Layout(gtx)
+				}),
+				layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+					return material.List(th, &rt.historyList).Layout(gtx, len(requests), func(gtx layout.Context, index int) layout.Dimensions {
+						return historyRow(gtx, requests[index])
+					})
+				}),
+			)
+		})
+	}
+
+	rt.widget = func(gtx layout.Context) layout.Dimensions {
+		return rt.resize.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(actionBar),
+				layout.Rigid(requestArea),
+				layout.Rigid(statusBar),
+				layout.Flexed(1.0, resultArea),
+			)
+		}, historyArea, common.VerticalSplitHandler)
+	}
+	return rt
+}
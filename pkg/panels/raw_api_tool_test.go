@@ -0,0 +1,84 @@
+package panels
+
+import (
+	"net/http"
+	"testing"
+
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+)
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("Accept: application/yaml\n\n X-Trace : a:b \nX-Trace: c\n")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if headers.Get("Accept") != "application/yaml" || len(headers.Values("X-Trace")) != 2 || headers.Get("X-Trace") != "a:b" {
+		t.Errorf("wrong headers %v", headers)
+	}
+	if _, err := ParseHeaders("no value"); err == nil {
+		t.Errorf("invalid header accepted")
+	}
+}
+
+func TestRawApiHistory(t *testing.T) {
+	dir := t.TempDir()
+	history, err := LoadRawApiHistory(dir)
+	if err != nil || len(history.Requests()) != 0 {
+		t.Fatalf("failed to load an empty history: %v", err)
+	}
+
+	get := &RawApiRequest{Method: http.MethodGet, Path: "/api"}
+	patch := &RawApiRequest{Method: http.MethodPatch, Path: "/api/v1/namespaces/a", PatchType: "merge", Body: "{}"}
+	for _, req := range []*RawApiRequest{get, patch, {Method: http.MethodGet, Path: "/api"}} {
+		if err := history.Add(req); err != nil {
+			t.Fatalf("failed to add: %v", err)
+		}
+	}
+	requests := history.Requests()
+	if len(requests) != 2 || requests[0].Path != "/api" || requests[1] != patch {
+		t.Errorf("a repeated request should move first: %v", requests)
+	}
+
+	loaded, err := LoadRawApiHistory(dir)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	requests = loaded.Requests()
+	if len(requests) != 2 || !requests[1].sameAs(patch) {
+		t.Fatalf("history not saved: %v", requests)
+	}
+	if err := loaded.Remove(requests[0]); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if loaded, _ = LoadRawApiHistory(dir); len(loaded.Requests()) != 1 {
+		t.Errorf("removal not saved")
+	}
+
+	for i := range RAW_API_HISTORY_SIZE + 5 {
+		history.Add(&RawApiRequest{Method: http.MethodGet, Path: "/api/" + string(rune('a'+i))})
+	}
+	if len(history.Requests()) != RAW_API_HISTORY_SIZE {
+		t.Errorf("history not trimmed: %d", len(history.Requests()))
+	}
+}
+
+func TestRawApiRequest(t *testing.T) {
+	req, err := (&RawApiRequest{Method: http.MethodGet, Path: "/api", Body: "ignored", Headers: "X-Trace: 1"}).toRawRequest()
+	if err != nil || req.Body != "" || req.Headers.Get("X-Trace") != "1" {
+		t.Errorf("wrong get %+v %v", req, err)
+	}
+	req, err = (&RawApiRequest{Method: http.MethodPatch, Path: "/api", Body: "[]", PatchType: "json"}).toRawRequest()
+	if err != nil || req.Body != "[]" || req.ContentType != "json" {
+		t.Errorf("wrong patch %+v %v", req, err)
+	}
+
+	text := FormatRawResponse(&k8sservice.RawResponse{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Headers:    http.Header{"Content-Type": {"application/json"}, "Audit-Id": {"1"}},
+		Body:       "{}",
+	})
+	if text != "Audit-Id: 1\nContent-Type: application/json\n\n{}" {
+		t.Errorf("wrong response text %q", text)
+	}
+}
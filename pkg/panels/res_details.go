@@ -2,8 +2,10 @@ package panels
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"sync"
 
@@ -174,6 +176,7 @@ type PodLogDetail struct {
 	conLogList layout.List
 	logEditor  *common.ReadOnlyEditor
 	divider    component.DividerStyle
+	optionsBar *LogOptionsBar
 
 	bufferLimit int //current the ui can't handle large block of text, so we have a limit here
 }
@@ -280,8 +283,19 @@ func (p *PodLogDetail) GetContent() layout.Widget {
 					})
 				})
 			}),
+			// log options
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					dims, applied := p.optionsBar.Layout(gtx)
+					if applied {
+						p.applyLogOptions()
+					}
+					return dims
+				})
+			}),
 			// log content panel showing current container's log
 			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+				p.logEditor.FollowTail(p.optionsBar.AutoScroll())
 				if p.currentLog != nil && p.currentLog.Changed() {
 					p.logEditor.SetText(p.currentLog.GetContent(false), nil)
 				}
@@ -291,6 +305,35 @@ func (p *PodLogDetail) GetContent() layout.Widget {
 	}
 }
 
+// applyLogOptions reloads the current log with the new options,
+// the others are reloaded when shown
+func (p *PodLogDetail) applyLogOptions() {
+	for _, conLog := range p.containerLogs {
+		conLog.mutex.Lock()
+		conLog.stale = conLog != p.currentLog
+		conLog.mutex.Unlock()
+	}
+	if p.currentLog != nil {
+		p.currentLog.LoadLog(true)
+	}
+}
+
+// containerCrashed tells if the container is waiting to be restarted after it
+// terminated, its current log is then empty and the previous one tells why
+func containerCrashed(podRaw *unstructured.Unstructured, container string) bool {
+	statuses, _, _ := unstructured.NestedSlice(podRaw.Object, "status", "containerStatuses")
+	for _, s := range statuses {
+		status, ok := s.(map[string]any)
+		if !ok || status["name"] != container {
+			continue
+		}
+		restarts, _, _ := unstructured.NestedInt64(status, "restartCount")
+		_, waiting, _ := unstructured.NestedMap(status, "state", "waiting")
+		return restarts > 0 && waiting
+	}
+	return false
+}
+
 type ContainerLog struct {
 	detail *PodLogDetail
 	// mutex to protect buffer
@@ -302,6 +345,10 @@ type ContainerLog struct {
 	mutex       sync.RWMutex
 	buffer      *bytes.Buffer
 	logReadTask *common.LongTask
+	// stops the reading, a followed log is read until then
+	cancelRead context.CancelFunc
+	// the log options changed since it was read
+	stale bool
 
 	fullLog              *string
 	warned               bool
@@ -310,11 +357,19 @@ type ContainerLog struct {
 	status *common.StatusIcon
 }
 
-func (cl *ContainerLog) addLog(newLog string) {
+func (cl *ContainerLog) addLog(ctx context.Context, newLog string) {
 	cl.mutex.Lock()
 	defer cl.mutex.Unlock()
 
+	if ctx.Err() != nil {
+		// from a reading replaced by a reload
+		return
+	}
 	cl.buffer.WriteString(newLog)
+	if over := cl.buffer.Len() - 2*cl.detail.bufferLimit; over > 0 {
+		// a followed log keeps growing, only the tail can be shown anyway
+		cl.buffer.Next(over + cl.detail.bufferLimit/2)
+	}
 
 	common.SetContextBool(cl.logChangeContextFlag, true, nil)
 }
@@ -323,6 +378,10 @@ func (cl *ContainerLog) resetBuffer() {
 	cl.mutex.Lock()
 	defer cl.mutex.Unlock()
 
+	if cl.cancelRead != nil {
+		cl.cancelRead()
+		cl.cancelRead = nil
+	}
 	cl.buffer.Reset()
 	cl.logReadTask = nil
 }
@@ -341,26 +400,48 @@ func (cl *ContainerLog) LoadLog(reload bool) {
 	cl.mutex.Lock()
 	defer cl.mutex.Unlock()
 
+	if cl.stale {
+		reload = true
+	}
+
 	if cl.logReadTask == nil {
 		ctxData, _ := common.GetContextData(common.CONTEXT_LONG_TASK_LIST)
 		if taskCtx, ok := ctxData.(*common.LongTasksContext); ok {
-			cl.logReadTask = taskCtx.AddTask("Reading pod log")
-			cl.logReadTask.Progress = 0.1
-			cl.logReadTask.Step = 0.01
-			cl.logReadTask.Run = func() {
+			cl.stale = false
+			opts := cl.detail.optionsBar.Options()
+			autoPrevious := !opts.Previous && containerCrashed(cl.detail.item, cl.name)
+			if autoPrevious {
+				opts.Previous = true
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cl.cancelRead = cancel
+
+			task := taskCtx.AddTask("Reading pod log")
+			cl.logReadTask = task
+			task.Progress = 0.1
+			task.Step = 0.01
+			task.Run = func() {
+				defer cancel()
 				client := k8sservice.GetK8sService()
-				ioReader, err := client.GetPodLog(cl.detail.item, cl.name)
+				ioReader, err := client.GetPodLog(ctx, cl.detail.item, cl.name, opts)
 				if err != nil {
 					msg := err.Error()
-					cl.addLog(msg) //trigger update
-					cl.logReadTask.Done()
+					cl.addLog(ctx, msg) //trigger update
+					task.Done()
 					return
 				} else {
 					defer ioReader.Close()
 				}
-				if cl.logReadTask.Progress > 0.9 {
+				if autoPrevious {
+					cl.addLog(ctx, "- the container crashed, showing the log before it restarted -\n")
+				}
+				if opts.Follow {
+					// new lines are shown as they come, the reading isn't a task to wait for
+					task.Done()
+				}
+				if task.Progress > 0.9 {
 					//dont let it reach 100% until done
-					cl.logReadTask.Step = 0.0001
+					task.Step = 0.0001
 				}
 
 				tot := 0
@@ -369,29 +450,40 @@ func (cl *ContainerLog) LoadLog(reload bool) {
 				for {
 					n, err := ioReader.Read(bts)
 					if err != nil {
-						if err == io.EOF {
-							cl.addLog("") //trigger update
-							cl.logReadTask.Done()
+						if err == io.EOF || ctx.Err() != nil {
+							if opts.Follow && ctx.Err() == nil {
+								cl.addLog(ctx, "\n- log stream ended -\n")
+							}
+							cl.addLog(ctx, "") //trigger update
+							if !task.IsDone() {
+								task.Done()
+							}
 						} else {
-							cl.addLog("\n- error occured while reading log -\n")
-							cl.addLog(err.Error())
-							cl.logReadTask.Failed(err)
+							cl.addLog(ctx, "\n- error occured while reading log -\n")
+							cl.addLog(ctx, err.Error())
+							if !task.IsDone() {
+								task.Failed(err)
+							}
 						}
 						break
 					} else if n > 0 {
-						cl.addLog(string(bts[:n]))
-						cl.logReadTask.Update(fmt.Sprintf("read %d\n", n))
+						cl.addLog(ctx, string(bts[:n]))
+						if !task.IsDone() {
+							task.Update(fmt.Sprintf("read %d\n", n))
+						} else {
+							common.GetAppWindow().Invalidate()
+						}
 						tot += n
-					} else {
-						cl.logReadTask.Update(fmt.Sprintf("total %d\n", tot))
-						cl.logReadTask.Done()
-						cl.addLog("") //trigger update
+					} else if !opts.Follow {
+						task.Update(fmt.Sprintf("total %d\n", tot))
+						task.Done()
+						cl.addLog(ctx, "") //trigger update
 						break
 					}
 				}
 				logger.Info("total log", zap.String("con", cl.name), zap.Int("total", tot))
 			}
-			cl.logReadTask.Start()
+			task.Start()
 		}
 
 	} else {
@@ -513,6 +605,7 @@ func (pd *PodLogDetail) Init(status common.ResStatusInfo) error {
 	actions = append(actions, reloadLogAct)
 
 	pd.logEditor = common.NewReadOnlyEditor("log", 15, actions, nil, true)
+	pd.optionsBar = NewLogOptionsBar()
 
 	pd.divider = component.Divider(th)
 	pd.divider.Fill = common.COLOR.Gray
@@ -547,6 +640,21 @@ func GetExtApiDetails(item *unstructured.Unstructured, status common.ResStatusIn
 		} else {
 			result = append(result, podDetail)
 		}
+		execDetail, err := NewPodExecDetail(item)
+		if err != nil {
+			logger.Warn("Failed to create pod exec detail", zap.Error(err))
+		} else {
+			result = append(result, execDetail)
+		}
+	}
+
+	if slices.Contains(workloadKinds, item.GetKind()) {
+		logDetail, err := NewWorkloadLogDetail(item)
+		if err != nil {
+			logger.Info("No pod logs for workload", zap.String("name", item.GetName()), zap.Error(err))
+		} else {
+			result = append(result, logDetail)
+		}
 	}
 
 	if item.GetKind() == "Secret" {
@@ -0,0 +1,219 @@
+package panels
+
+import (
+	"sync"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const watchRetryInterval = 2 * time.Second
+
+// pageLoaded is the type of the events queued for items of a lazily loaded
+// page. Unlike ADDED they never replace an item already in the list, which
+// may be newer as it came from the watch.
+const pageLoaded watch.EventType = "PAGE"
+
+// relisted is the type of the events queued when a target is listed again
+// after its watch expired, the Object is a *relistResult. The objects of
+// the target that are not in it were deleted meanwhile.
+const relisted watch.EventType = "RELIST"
+
+// relistResult is the current objects of a watch target
+type relistResult struct {
+	*unstructured.UnstructuredList
+	// apiVersion and kind of the objects, ns empty for all namespaces
+	apiVersion, kind, ns string
+}
+
+// contains tells whether obj is of the relisted target
+func (r *relistResult) contains(obj *unstructured.Unstructured) bool {
+	return obj.GetAPIVersion() == r.apiVersion && obj.GetKind() == r.kind && (r.ns == "" || obj.GetNamespace() == r.ns)
+}
+
+// watchTarget is one (resource, namespace) pair of a query, to be watched
+// from the resource version of its list.
+type watchTarget struct {
+	g, v, r, ns     string
+	kind            string
+	labelSelector   string
+	fieldSelector   string
+	resourceVersion string
+	// continue token of the next page, empty if all pages are loaded
+	next string
+}
+
+func (t *watchTarget) listOptions() v1.ListOptions {
+	return v1.ListOptions{
+		LabelSelector:   t.labelSelector,
+		FieldSelector:   t.fieldSelector,
+		ResourceVersion: t.resourceVersion,
+	}
+}
+
+// ResultWatcher keeps the search results up to date by watching the queried
+// resources. The watch goroutines only queue the events, they are applied
+// to the result list on the ui goroutine (see InKubeTab.applyWatchEvents).
+type ResultWatcher struct {
+	client k8sservice.K8sService
+
+	lock    sync.Mutex
+	pending []watch.Event
+	stopped bool
+	stopCh  chan struct{}
+}
+
+func NewResultWatcher(client k8sservice.K8sService, targets []watchTarget) *ResultWatcher {
+	w := &ResultWatcher{
+		client: client,
+		stopCh: make(chan struct{}),
+	}
+	for _, t := range targets {
+		go w.run(t)
+	}
+	return w
+}
+
+func (w *ResultWatcher) Stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.stopped {
+		w.stopped = true
+		w.pending = nil
+		close(w.stopCh)
+	}
+}
+
+// Drain returns and clears the queued events
+func (w *ResultWatcher) Drain() []watch.Event {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	events := w.pending
+	w.pending = nil
+	return events
+}
+
+// AddPage queues the items of a page loaded after the query
+func (w *ResultWatcher) AddPage(items []unstructured.Unstructured) {
+	events := make([]watch.Event, 0, len(items))
+	for i := range items {
+		events = append(events, watch.Event{
+			Type:   pageLoaded,
+			Object: &items[i],
+		})
+	}
+	w.queue(events...)
+}
+
+func (w *ResultWatcher) queue(events ...watch.Event) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.stopped {
+		return
+	}
+	w.pending = append(w.pending, events...)
+	if win := common.GetAppWindow(); win != nil {
+		win.Invalidate()
+	}
+}
+
+// run keeps watching the target until stopped. A closed watch is resumed from
+// the last seen resource version; if that is too old the target is listed
+// again to catch up with the objects changed or deleted meanwhile, and then
+// watched from the resource version of that list.
+func (w *ResultWatcher) run(t watchTarget) {
+	opts := t.listOptions()
+	expired := false
+	for {
+		if expired {
+			rv, err := w.relist(t)
+			if err != nil {
+				logger.Info("failed to relist", zap.String("res", t.r), zap.Error(err))
+			} else {
+				opts.ResourceVersion = rv
+				expired = false
+			}
+		}
+		if !expired {
+			wi, err := w.client.WatchGVRInstances(t.g, t.v, t.r, t.ns, opts)
+			if err != nil {
+				expired = apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+				logger.Info("failed to watch", zap.String("res", t.r), zap.Error(err))
+			} else {
+				opts.ResourceVersion, expired = w.consume(wi, opts.ResourceVersion)
+			}
+		}
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// relist lists all the objects of the target, bypassing any cache, and
+// queues them as a relisted event. It returns the resource version of the
+// list.
+func (w *ResultWatcher) relist(t watchTarget) (string, error) {
+	list, err := w.client.FetchGVRInstancesUncached(t.g, t.v, t.r, t.ns, v1.ListOptions{
+		LabelSelector: t.labelSelector,
+		FieldSelector: t.fieldSelector,
+	})
+	if err != nil {
+		return "", err
+	}
+	apiVersion := t.v
+	if t.g != "" {
+		apiVersion = t.g + "/" + t.v
+	}
+	w.queue(watch.Event{
+		Type: relisted,
+		Object: &relistResult{
+			UnstructuredList: list,
+			apiVersion:       apiVersion,
+			kind:             t.kind,
+			ns:               t.ns,
+		},
+	})
+	return list.GetResourceVersion(), nil
+}
+
+// consume queues the events of the watch until it closes. It returns the
+// last seen resource version and whether it expired.
+func (w *ResultWatcher) consume(wi watch.Interface, rv string) (string, bool) {
+	defer wi.Stop()
+	for {
+		select {
+		case <-w.stopCh:
+			return rv, false
+		case event, ok := <-wi.ResultChan():
+			if !ok {
+				return rv, false
+			}
+			switch event.Type {
+			case watch.Error:
+				expired := false
+				if status, ok := event.Object.(*v1.Status); ok {
+					expired = status.Code == 410 || status.Reason == v1.StatusReasonExpired || status.Reason == v1.StatusReasonGone
+					logger.Info("watch error", zap.Error(&apierrors.StatusError{ErrStatus: *status}))
+				}
+				return rv, expired
+			case watch.Bookmark:
+				if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+					rv = obj.GetResourceVersion()
+				}
+			default:
+				if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+					rv = obj.GetResourceVersion()
+					w.queue(event)
+				}
+			}
+		}
+	}
+}
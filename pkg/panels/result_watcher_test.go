@@ -0,0 +1,96 @@
+package panels
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// expiringService expires the first watch, the relist has only pod b
+type expiringService struct {
+	k8sservice.K8sService
+
+	lock    sync.Mutex
+	watches []string
+}
+
+func newWatchedPod(name string, rv string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	pod.SetNamespace("default")
+	pod.SetUID(types.UID(name))
+	pod.SetResourceVersion(rv)
+	return pod
+}
+
+func (s *expiringService) FetchGVRInstancesUncached(g string, v string, r string, ns string, opts v1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*newWatchedPod("b", "20")}}
+	list.SetResourceVersion("21")
+	return list, nil
+}
+
+func (s *expiringService) WatchGVRInstances(g string, v string, r string, ns string, opts v1.ListOptions) (watch.Interface, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.watches = append(s.watches, opts.ResourceVersion)
+	wi := watch.NewFakeWithChanSize(1, false)
+	if len(s.watches) == 1 {
+		wi.Error(&v1.Status{Status: v1.StatusFailure, Code: http.StatusGone, Reason: v1.StatusReasonExpired})
+	}
+	return wi, nil
+}
+
+func TestResultWatcherRelist(t *testing.T) {
+	service := &expiringService{}
+	watcher := NewResultWatcher(service, []watchTarget{{v: "v1", r: "pods", ns: "default", kind: "Pod", resourceVersion: "10"}})
+	defer watcher.Stop()
+
+	tab := &InKubeTab{watcher: watcher}
+	other := newWatchedPod("svc", "5")
+	other.SetKind("Service")
+	uList := []*SearchResultItem{
+		newSearchResultItem(newWatchedPod("a", "10")),
+		newSearchResultItem(newWatchedPod("b", "10")),
+		newSearchResultItem(other),
+	}
+	tab.currentResultItem = uList[0]
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		service.lock.Lock()
+		watches := append([]string{}, service.watches...)
+		service.lock.Unlock()
+		if len(watches) == 2 {
+			if watches[0] != "10" || watches[1] != "21" {
+				t.Errorf("expected to rewatch from the relist, got %v", watches)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("not watched again after expiry %v", watches)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	uList = tab.applyWatchEvents(uList)
+	names := []string{}
+	for _, item := range uList {
+		names = append(names, item.item.GetName()+"@"+item.item.GetResourceVersion())
+	}
+	// a was deleted while the watch was expired, other kinds are kept
+	if len(names) != 2 || names[0] != "b@20" || names[1] != "svc@5" {
+		t.Errorf("wrong results after relist %v", names)
+	}
+	if tab.currentResultItem != nil {
+		t.Errorf("the deleted item is still selected")
+	}
+}
@@ -0,0 +1,200 @@
+package panels
+
+import (
+	"fmt"
+	"strconv"
+
+	"gaohoward.tools/k8s/resutil/pkg/common"
+	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+)
+
+// the unchanged lines shown around a change between revisions
+const REVISION_DIFF_CONTEXT = 3
+
+// the widths of the revision, time, outcome, from, resources and message columns
+var revisionColumnWeights = []float32{0.08, 0.2, 0.12, 0.08, 0.1, 0.42}
+
+// RevisionsView lists the revisions of a deployment, diffs two of them
+// and redeploys one
+type RevisionsView struct {
+	detail *k8sservice.DeployDetail
+	// the selection by revision number
+	selected map[int]*widget.Bool
+	diff     *common.DiffView
+	// the revisions diffed
+	diffFrom int
+	diffTo   int
+
+	split       component.Resize
+	rowList     widget.List
+	diffBtn     widget.Clickable
+	redeployBtn widget.Clickable
+	closeBtn    widget.Clickable
+}
+
+func NewRevisionsView(detail *k8sservice.DeployDetail) *RevisionsView {
+	view := &RevisionsView{
+		detail:   detail,
+		selected: make(map[int]*widget.Bool),
+		split:    component.Resize{Ratio: 0.4},
+	}
+	view.rowList.Axis = layout.Vertical
+	return view
+}
+
+// selectedRevisions returns the selected revisions, the oldest first
+func (v *RevisionsView) selectedRevisions() []*k8sservice.DeployRevision {
+	revisions := make([]*k8sservice.DeployRevision, 0)
+	for _, revision := range v.detail.Revisions {
+		if check, ok := v.selected[revision.Number]; ok && check.Value {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+func (v *RevisionsView) check(number int) *widget.Bool {
+	check, ok := v.selected[number]
+	if !ok {
+		check = &widget.Bool{}
+		v.selected[number] = check
+	}
+	return check
+}
+
+// Layout returns the number of the revision the user chose to redeploy,
+// 0 if none, or whether the user closed the view
+func (v *RevisionsView) Layout(gtx layout.Context) (layout.Dimensions, int, bool) {
+	th := common.GetTheme()
+	selected := v.selectedRevisions()
+
+	redeploy := 0
+	if v.redeployBtn.Clicked(gtx) && len(selected) == 1 {
+		redeploy = selected[0].Number
+	}
+	if v.diffBtn.Clicked(gtx) && len(selected) == 2 {
+		v.diff = common.NewDiffView()
+		v.diff.SetDiff(k8sservice.DiffRevisions(selected[0], selected[1]), REVISION_DIFF_CONTEXT)
+		v.diffFrom, v.diffTo = selected[0].Number, selected[1].Number
+	}
+	closed := v.closeBtn.Clicked(gtx)
+
+	header := func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1.0, material.H6(th, "Revisions of "+v.detail.Name).Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if len(selected) != 2 {
+						gtx = gtx.Disabled()
+					}
+					return material.Button(th, &v.diffBtn, "Diff").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if len(selected) != 1 {
+						gtx = gtx.Disabled()
+					}
+					return material.Button(th, &v.redeployBtn, "Redeploy").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+				layout.Rigid(material.Button(th, &v.closeBtn, "Close").Layout),
+			)
+		})
+	}
+
+	body := func(gtx layout.Context) layout.Dimensions {
+		if len(v.detail.Revisions) == 0 {
+			return material.Body1(th, "No revisions recorded").Layout(gtx)
+		}
+		return v.split.Layout(gtx, v.layoutRows, v.layoutDiff, common.VerticalSplitHandler)
+	}
+
+	dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(header),
+			layout.Flexed(1.0, body),
+		)
+	})
+	return dims, redeploy, closed
+}
+
+func (v *RevisionsView) layoutRows(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+	columns := func(gtx layout.Context, texts []string, style func(*material.LabelStyle)) layout.Dimensions {
+		children := make([]layout.FlexChild, 0, len(texts))
+		for i, text := range texts {
+			children = append(children, layout.Flexed(revisionColumnWeights[i], func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(th, text)
+				label.MaxLines = 1
+				style(&label)
+				return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, label.Layout)
+			}))
+		}
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+	}
+	checkWidth := unit.Dp(32)
+	revisions := v.detail.Revisions
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(layout.Spacer{Width: checkWidth}.Layout),
+				layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+					return columns(gtx, []string{"Revision", "Time", "Outcome", "From", "Resources", "Message"}, func(l *material.LabelStyle) {
+						l.Font.Weight = font.Bold
+					})
+				}),
+			)
+		}),
+		layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+			// the latest first
+			return material.List(th, &v.rowList).Layout(gtx, len(revisions), func(gtx layout.Context, index int) layout.Dimensions {
+				revision := revisions[len(revisions)-1-index]
+				from := ""
+				if revision.From > 0 {
+					from = strconv.Itoa(revision.From)
+				}
+				texts := []string{strconv.Itoa(revision.Number), revision.Time, string(revision.Outcome), from,
+					strconv.Itoa(len(revision.Instances)), revision.Message}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Min.X = gtx.Dp(checkWidth)
+						return material.CheckBox(th, v.check(revision.Number), "").Layout(gtx)
+					}),
+					layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
+						return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return columns(gtx, texts, func(l *material.LabelStyle) {
+								if !revision.Outcome.Applied() {
+									l.Color = common.COLOR.Red
+								}
+							})
+						})
+					}),
+				)
+			})
+		}),
+	)
+}
+
+func (v *RevisionsView) layoutDiff(gtx layout.Context) layout.Dimensions {
+	th := common.GetTheme()
+	return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if v.diff == nil {
+			return material.Body2(th, "Select two revisions to diff").Layout(gtx)
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Body2(th, fmt.Sprintf("Revision %d to %d", v.diffFrom, v.diffTo))
+				label.Font.Weight = font.Bold
+				return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, label.Layout)
+			}),
+			layout.Flexed(1.0, v.diff.Layout),
+		)
+	})
+}
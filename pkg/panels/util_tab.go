@@ -4,8 +4,6 @@ import (
 
 	// "crypto/rsa"
 
-	"strings"
-
 	"gaohoward.tools/k8s/resutil/pkg/common"
 	"gaohoward.tools/k8s/resutil/pkg/graphics"
 	"gaohoward.tools/k8s/resutil/pkg/k8sservice"
@@ -63,40 +61,6 @@ func (p *ToolsTab) GetWidget() layout.Widget {
 	return p.widget
 }
 
-type RawApiTool struct {
-	kubeClient k8sservice.K8sService
-	widget     layout.Widget
-	clickable  widget.Clickable
-	uriField   component.TextField
-	runBtn     widget.Clickable
-	result     *common.ReadOnlyEditor
-}
-
-func (rat *RawApiTool) Run() {
-	if rep, err := rat.kubeClient.DoRawRequest(strings.TrimSpace(rat.uriField.Text())); err == nil {
-		rat.result.SetText(&rep, nil)
-	} else {
-		errInfo := "Error: " + err.Error()
-		rat.result.SetText(&errInfo, nil)
-	}
-}
-
-func (rat *RawApiTool) GetClickable() *widget.Clickable {
-	return &rat.clickable
-}
-
-func (rat *RawApiTool) GetName() string {
-	return "raw-api"
-}
-
-func (rat *RawApiTool) GetTabButtons() []layout.FlexChild {
-	return nil
-}
-
-func (rat *RawApiTool) GetWidget() layout.Widget {
-	return rat.widget
-}
-
 func NewToolsTab(client k8sservice.K8sService) *ToolsTab {
 	tab := &ToolsTab{}
 
@@ -124,7 +88,7 @@ func NewToolsTab(client k8sservice.K8sService) *ToolsTab {
 
 	tab.tools = make([]Tool, 0)
 
-	tab.tools = append(tab.tools, NewConvertTool(), NewRawApiTool(client))
+	tab.tools = append(tab.tools, NewConvertTool(), NewRawApiTool(client), NewMultiPodLogTool())
 	if rt, err := NewReaderTool(); err == nil {
 		tab.tools = append(tab.tools, rt)
 	}
@@ -194,49 +158,3 @@ func NewToolsTab(client k8sservice.K8sService) *ToolsTab {
 
 	return tab
 }
-
-// The raw api tools allows users to access api resources
-// in raw http format, i.e. the kubectl raw option, for example
-// kubectl get --raw "/apis/subresources.kubevirt.io"
-// or "/apis/subresources.kubevirt.io/v1/guestfs"
-func NewRawApiTool(client k8sservice.K8sService) *RawApiTool {
-	th := common.GetTheme()
-
-	rt := &RawApiTool{
-		kubeClient: client,
-	}
-
-	rt.result = common.NewReadOnlyEditor("result", 16, nil, nil, true)
-
-	rt.uriField.SingleLine = true
-	rt.uriField.Submit = true
-
-	goBtn := material.Button(th, &rt.runBtn, "Go!")
-
-	actionBar := func(gtx layout.Context) layout.Dimensions {
-
-		if rt.runBtn.Clicked(gtx) {
-			go rt.Run()
-		}
-		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
-			layout.Flexed(1.0, func(gtx layout.Context) layout.Dimensions {
-				return rt.uriField.Layout(gtx, th, "URI:")
-			}),
-			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Inset{Left: unit.Dp(5), Right: unit.Dp(5)}.Layout(gtx, goBtn.Layout)
-			}),
-		)
-	}
-
-	resultArea := func(gtx layout.Context) layout.Dimensions {
-		return layout.Inset{Top: 6, Bottom: 0, Left: 0, Right: 0}.Layout(gtx, rt.result.Layout)
-	}
-
-	rt.widget = func(gtx layout.Context) layout.Dimensions {
-		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-			layout.Rigid(actionBar),
-			layout.Flexed(1.0, resultArea),
-		)
-	}
-	return rt
-}